@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client/fake"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fakeJobManagerPort             int
+	fakeJobManagerConfig           fake.Config
+	fakeJobManagerLatency          time.Duration
+	fakeJobManagerSavepointFailure string
+)
+
+// fakeJobManagerCmd serves a fake JobManager REST API, which a locally running operator can be pointed at by setting
+// useKubectlProxy and ProxyPort
+var fakeJobManagerCmd = &cobra.Command{
+	Use:   "fake-jobmanager",
+	Short: "Serves a fake Flink JobManager REST API for local development",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr := fmt.Sprintf(":%d", fakeJobManagerPort)
+		logger.Infof(context.Background(), "Serving fake JobManager on %s", addr)
+		jm := fake.NewJobManager(fakeJobManagerConfig)
+		jm.SetLatency(fakeJobManagerLatency)
+		jm.FailSavepoints(fakeJobManagerSavepointFailure)
+		return http.ListenAndServe(addr, jm)
+	},
+}
+
+func init() {
+	flags := fakeJobManagerCmd.Flags()
+	flags.IntVar(&fakeJobManagerPort, "port", 8001, "Port to serve the fake JobManager on")
+	flags.IntVar(&fakeJobManagerConfig.TaskManagers, "taskmanagers", fake.DefaultTaskManagers,
+		"Number of taskmanagers in each cluster")
+	flags.IntVar(&fakeJobManagerConfig.SlotsPerTaskManager, "slots", fake.DefaultSlotsPerTaskManager,
+		"Number of task slots per taskmanager")
+	flags.DurationVar(&fakeJobManagerConfig.JobStartDelay, "job-start-delay", 5*time.Second,
+		"Time submitted jobs take to start running")
+	flags.DurationVar(&fakeJobManagerConfig.SavepointDuration, "savepoint-duration", 10*time.Second,
+		"Time savepoints take to complete")
+	flags.DurationVar(&fakeJobManagerConfig.CheckpointInterval, "checkpoint-interval", time.Minute,
+		"Interval between checkpoints of running jobs")
+	flags.DurationVar(&fakeJobManagerLatency, "latency", 0, "Delay added to every response")
+	flags.StringVar(&fakeJobManagerSavepointFailure, "savepoint-failure", "",
+		"If set, savepoints fail with this cause")
+
+	rootCmd.AddCommand(fakeJobManagerCmd)
+}
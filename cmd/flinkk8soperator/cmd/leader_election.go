@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	controller_config "github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/health"
+	"github.com/lyft/flinkk8soperator/pkg/controller/sharding"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const leaderElectionRecorderName = "flinkk8soperator-leader-election"
+
+// Wraps the manager so that the controllers added to it only start once this replica has been elected leader. The
+// manager itself, and so the cache shared by the controllers, is started right away. Events received before the
+// election are queued by the controllers, so that a new leader picks up where the previous one left off without
+// having to wait for the caches to sync or for the next resync.
+type leaderElectedManager struct {
+	manager.Manager
+	elected <-chan struct{}
+}
+
+func (m *leaderElectedManager) Add(runnable manager.Runnable) error {
+	// caches added by the controllers are started right away, like the cache of the manager
+	if _, ok := runnable.(cache.Cache); ok {
+		return m.Manager.Add(runnable)
+	}
+	// dependencies are injected into the runnable itself rather than into the wrapper
+	if err := m.Manager.SetFields(runnable); err != nil {
+		return err
+	}
+	return m.Manager.Add(&leaderElectedRunnable{runnable: runnable, elected: m.elected})
+}
+
+type leaderElectedRunnable struct {
+	runnable manager.Runnable
+	elected  <-chan struct{}
+}
+
+func (r *leaderElectedRunnable) Start(stop <-chan struct{}) error {
+	select {
+	case <-r.elected:
+		return r.runnable.Start(stop)
+	case <-stop:
+		return nil
+	}
+}
+
+// Campaigns for leadership in the background, closing the returned channel once this replica has been elected. A
+// leader that loses its lock exits, as its controllers may be in the middle of reconciling applications that the new
+// leader is now handling as well.
+func startLeaderElection(ctx context.Context, cfg *rest.Config, mgr manager.Manager, healthMonitor *health.Monitor,
+	controllerCfg *controller_config.Config) (<-chan struct{}, error) {
+	namespace, err := controllerCfg.GetLeaderElectionNamespace()
+	if err != nil {
+		return nil, err
+	}
+
+	// with sharding, the replicas of each shard elect their own leader
+	shard, err := sharding.NewShard(controllerCfg.Sharding)
+	if err != nil {
+		return nil, err
+	}
+	lockName := shard.GetLockName(controllerCfg.LeaderElection.LockName)
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	identity := hostname + "_" + string(uuid.NewUUID())
+
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := resourcelock.New(resourcelock.ConfigMapsResourceLock, namespace, lockName,
+		client.CoreV1(), resourcelock.ResourceLockConfig{
+			Identity:      identity,
+			EventRecorder: mgr.GetRecorder(leaderElectionRecorderName),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	elected := make(chan struct{})
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: controllerCfg.LeaderElection.LeaseDuration.Duration,
+		RenewDeadline: controllerCfg.LeaderElection.RenewDeadline.Duration,
+		RetryPeriod:   controllerCfg.LeaderElection.RetryPeriod.Duration,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(stop <-chan struct{}) {
+				logger.Infof(ctx, "Elected leader as %s", identity)
+				healthMonitor.SetLeader(true)
+				close(elected)
+			},
+			OnStoppedLeading: func() {
+				healthMonitor.SetLeader(false)
+				logAndExit(errors.Errorf("Lost leadership of %s/%s", namespace, lockName))
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof(ctx, "Campaigning for leadership of %s/%s as %s", namespace, lockName, identity)
+	go elector.Run()
+	return elected, nil
+}
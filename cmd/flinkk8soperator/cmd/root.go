@@ -18,6 +18,8 @@ import (
 
 	"github.com/lyft/flinkk8soperator/pkg/controller"
 	controller_config "github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/health"
+	"github.com/lyft/flinkk8soperator/pkg/controller/tracing"
 	ctrlRuntimeConfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	"github.com/kubernetes-sigs/controller-runtime/pkg/runtime/signals"
@@ -116,14 +118,23 @@ func executeRootCmd(controllerCfg *controller_config.Config) error {
 	}
 	operatorScope := promutils.NewScope(controllerCfg.MetricsPrefix)
 
+	shutdownTracing, err := tracing.Initialize(ctx, controllerCfg.Tracing)
+	if err != nil {
+		logAndExit(errors.Wrap(err, "Failed to initialize tracing"))
+	}
+
+	// The work queues of the controllers are only tracked if they are created after this
+	healthMonitor := health.NewMonitor(controllerCfg.Health.MaxQueueDepth, controllerCfg.Health.MaxReconcileAge.Duration)
+	healthMonitor.WatchQueues()
+
 	go func() {
-		err := profutils.StartProfilingServerWithDefaultHandlers(ctx, controllerCfg.ProfilerPort.Port, nil)
+		err := profutils.StartProfilingServerWithDefaultHandlers(ctx, controllerCfg.ProfilerPort.Port, healthMonitor.GetHandlers())
 		if err != nil {
 			logger.Panicf(ctx, "Failed to Start profiling and metrics server. Error: %v", err)
 		}
 	}()
 
-	stopCh, err := operatorEntryPoint(ctx, operatorScope, controllerCfg)
+	stopCh, err := operatorEntryPoint(ctx, operatorScope, healthMonitor, controllerCfg)
 	if err != nil {
 		cancelNow()
 		return err
@@ -132,6 +143,9 @@ func executeRootCmd(controllerCfg *controller_config.Config) error {
 	for {
 		select {
 		case <-stopCh:
+			if err := shutdownTracing(context.Background()); err != nil {
+				logger.Warnf(ctx, "Failed to flush traces: %v", err)
+			}
 			cancelNow()
 			os.Exit(0)
 		case <-ctx.Done():
@@ -140,7 +154,7 @@ func executeRootCmd(controllerCfg *controller_config.Config) error {
 	}
 }
 
-func operatorEntryPoint(ctx context.Context, metricsScope promutils.Scope,
+func operatorEntryPoint(ctx context.Context, metricsScope promutils.Scope, healthMonitor *health.Monitor,
 	controllerCfg *controller_config.Config) (stopCh <-chan struct{}, err error) {
 
 	// Get a config to talk to the apiserver
@@ -151,7 +165,7 @@ func operatorEntryPoint(ctx context.Context, metricsScope promutils.Scope,
 
 	// Create a new Cmd to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{
-		Namespace:  controllerCfg.LimitNamespace,
+		Namespace:  controllerCfg.GetCacheNamespace(),
 		SyncPeriod: &controllerCfg.ResyncPeriod.Duration,
 	})
 	if err != nil {
@@ -165,10 +179,26 @@ func operatorEntryPoint(ctx context.Context, metricsScope promutils.Scope,
 		return nil, err
 	}
 
+	// With leader election, the controllers only start reconciling once this replica is elected
+	controllerMgr := mgr
+	if controllerCfg.LeaderElection.Enabled {
+		elected, err := startLeaderElection(ctx, cfg, mgr, healthMonitor, controllerCfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to start leader election")
+		}
+		controllerMgr = &leaderElectedManager{Manager: mgr, elected: elected}
+	} else {
+		healthMonitor.SetLeader(true)
+	}
+
+	// Controllers may add caches of their own, which are waited for along with the cache of the manager
+	healthMonitor.AddCache(mgr.GetCache())
+
 	// Setup all Controllers
 	logger.Infof(ctx, "Adding controllers.")
-	if err := controller.AddToManager(ctx, mgr, controller_config.RuntimeConfig{
+	if err := controller.AddToManager(ctx, controllerMgr, controller_config.RuntimeConfig{
 		MetricsScope: metricsScope,
+		Health:       healthMonitor,
 	}); err != nil {
 		return nil, err
 	}
@@ -176,5 +206,8 @@ func operatorEntryPoint(ctx context.Context, metricsScope promutils.Scope,
 	// Start the Cmd
 	logger.Infof(ctx, "Starting the Cmd.")
 	stopCh = signals.SetupSignalHandler()
+	go func() {
+		healthMonitor.WaitForCacheSync(stopCh)
+	}()
 	return stopCh, mgr.Start(stopCh)
 }
@@ -21,12 +21,38 @@ if [ -n "$TASKMANAGER_HOSTNAME" ]; then
     echo "taskmanager.host: $TASKMANAGER_HOSTNAME" >> "$FLINK_HOME/conf/flink-conf.yaml"
 fi
 
+# With high availability, each jobmanager advertises the ip address of its pod,
+# which is assigned to JOBMANAGER_HOSTNAME env var by the operator.
+if [ -n "$JOBMANAGER_HOSTNAME" ]; then
+    echo "jobmanager.rpc.address: $JOBMANAGER_HOSTNAME" >> "$FLINK_HOME/conf/flink-conf.yaml"
+fi
+
 # Add in extra configs set by the operator
 if [ -n "$OPERATOR_FLINK_CONFIG" ]; then
     echo "$OPERATOR_FLINK_CONFIG" >> "$FLINK_HOME/conf/flink-conf.yaml"
 fi
 
-COMMAND=$@
+# With high availability, each job manager advertises the ip address of its pod,
+# which is assigned to the JOBMANAGER_HOSTNAME env var by the operator.
+if [ -n "$JOBMANAGER_HOSTNAME" ]; then
+    echo "jobmanager.rpc.address: $JOBMANAGER_HOSTNAME" >> "$FLINK_HOME/conf/flink-conf.yaml"
+fi
+
+# Passwords for the REST TLS keystore and truststore are provided by the operator
+# from the application's TLS secret
+if [ -n "$FLINK_REST_SSL_KEYSTORE_PASSWORD" ]; then
+    echo "security.ssl.rest.keystore-password: $FLINK_REST_SSL_KEYSTORE_PASSWORD" >> "$FLINK_HOME/conf/flink-conf.yaml"
+fi
+if [ -n "$FLINK_REST_SSL_KEY_PASSWORD" ]; then
+    echo "security.ssl.rest.key-password: $FLINK_REST_SSL_KEY_PASSWORD" >> "$FLINK_HOME/conf/flink-conf.yaml"
+fi
+if [ -n "$FLINK_REST_SSL_TRUSTSTORE_PASSWORD" ]; then
+    echo "security.ssl.rest.truststore-password: $FLINK_REST_SSL_TRUSTSTORE_PASSWORD" >> "$FLINK_HOME/conf/flink-conf.yaml"
+fi
+
+# Any arguments after the command are dynamic properties (-Dkey=value) set by
+# the operator, which are passed on to Flink
+COMMAND=$1
 
 if [ $# -lt 1 ]; then
     COMMAND="local"
@@ -38,11 +64,13 @@ if [ "$COMMAND" = "help" ]; then
 elif [ "$COMMAND" = "jobmanager" ]; then
     echo "Starting Job Manager"
     echo "config file: " && grep '^[^\n#]' "$FLINK_HOME/conf/flink-conf.yaml"
-    exec $(drop_privs_cmd) flink "$FLINK_HOME/bin/jobmanager.sh" start-foreground
+    shift
+    exec $(drop_privs_cmd) flink "$FLINK_HOME/bin/jobmanager.sh" start-foreground "$@"
 elif [ "$COMMAND" = "taskmanager" ]; then
     echo "Starting Task Manager"
     echo "config file: " && grep '^[^\n#]' "$FLINK_HOME/conf/flink-conf.yaml"
-    exec $(drop_privs_cmd) flink "$FLINK_HOME/bin/taskmanager.sh" start-foreground
+    shift
+    exec $(drop_privs_cmd) flink "$FLINK_HOME/bin/taskmanager.sh" start-foreground "$@"
 elif [ "$COMMAND" = "local" ]; then
     echo "Starting local cluster"
     exec $(drop_privs_cmd) flink "$FLINK_HOME/bin/jobmanager.sh" start-foreground local
@@ -4,8 +4,10 @@ import (
 	"fmt"
 
 	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -28,28 +30,37 @@ type FlinkApplication struct {
 }
 
 type FlinkApplicationSpec struct {
-	Image             string                       `json:"image,omitempty" protobuf:"bytes,2,opt,name=image"`
-	ImagePullPolicy   apiv1.PullPolicy             `json:"imagePullPolicy,omitempty" protobuf:"bytes,14,opt,name=imagePullPolicy,casttype=PullPolicy"`
-	ImagePullSecrets  []apiv1.LocalObjectReference `json:"imagePullSecrets,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,15,rep,name=imagePullSecrets"`
-	FlinkConfig       FlinkConfig                  `json:"flinkConfig"`
-	FlinkVersion      string                       `json:"flinkVersion"`
-	TaskManagerConfig TaskManagerConfig            `json:"taskManagerConfig,omitempty"`
-	JobManagerConfig  JobManagerConfig             `json:"jobManagerConfig,omitempty"`
-	JarName           string                       `json:"jarName"`
-	Parallelism       int32                        `json:"parallelism"`
-	EntryClass        string                       `json:"entryClass,omitempty"`
-	ProgramArgs       string                       `json:"programArgs,omitempty"`
-	SavepointInfo     SavepointInfo                `json:"savepointInfo,omitempty"`
-	DeploymentMode    DeploymentMode               `json:"deploymentMode"`
-	RPCPort           *int32                       `json:"rpcPort,omitempty"`
-	BlobPort          *int32                       `json:"blobPort,omitempty"`
-	QueryPort         *int32                       `json:"queryPort,omitempty"`
-	UIPort            *int32                       `json:"uiPort,omitempty"`
-	MetricsQueryPort  *int32                       `json:"metricsQueryPort,omitempty"`
-	Volumes           []apiv1.Volume               `json:"volumes,omitempty"`
-	VolumeMounts      []apiv1.VolumeMount          `json:"volumeMounts,omitempty"`
-	RestartNonce      string                       `json:"restartNonce"`
-	DeleteMode        DeleteMode                   `json:"deleteMode"`
+	Image              string                       `json:"image,omitempty" protobuf:"bytes,2,opt,name=image"`
+	ImagePullPolicy    apiv1.PullPolicy             `json:"imagePullPolicy,omitempty" protobuf:"bytes,14,opt,name=imagePullPolicy,casttype=PullPolicy"`
+	ImagePullSecrets   []apiv1.LocalObjectReference `json:"imagePullSecrets,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,15,rep,name=imagePullSecrets"`
+	FlinkConfig        FlinkConfig                  `json:"flinkConfig"`
+	FlinkVersion       string                       `json:"flinkVersion"`
+	TaskManagerConfig  TaskManagerConfig            `json:"taskManagerConfig,omitempty"`
+	JobManagerConfig   JobManagerConfig             `json:"jobManagerConfig,omitempty"`
+	JarName            string                       `json:"jarName"`
+	Parallelism        int32                        `json:"parallelism"`
+	EntryClass         string                       `json:"entryClass,omitempty"`
+	ProgramArgs        string                       `json:"programArgs,omitempty"`
+	SavepointInfo      SavepointInfo                `json:"savepointInfo,omitempty"`
+	DeploymentMode     DeploymentMode               `json:"deploymentMode"`
+	RPCPort            *int32                       `json:"rpcPort,omitempty"`
+	BlobPort           *int32                       `json:"blobPort,omitempty"`
+	QueryPort          *int32                       `json:"queryPort,omitempty"`
+	UIPort             *int32                       `json:"uiPort,omitempty"`
+	MetricsQueryPort   *int32                       `json:"metricsQueryPort,omitempty"`
+	Volumes            []apiv1.Volume               `json:"volumes,omitempty"`
+	VolumeMounts       []apiv1.VolumeMount          `json:"volumeMounts,omitempty"`
+	RestartNonce       string                       `json:"restartNonce"`
+	DeleteMode         DeleteMode                   `json:"deleteMode"`
+	StopMode           StopMode                     `json:"stopMode,omitempty"`
+	RestSecurity       *RestSecurityConfig          `json:"restSecurity,omitempty"`
+	SavepointRetention *SavepointRetentionPolicy    `json:"savepointRetention,omitempty"`
+	MemoryModel        MemoryModel                  `json:"memoryModel,omitempty"`
+	Metrics            []FlinkMetricSelector        `json:"metrics,omitempty"`
+	NetworkPolicy      *NetworkPolicyConfig         `json:"networkPolicy,omitempty"`
+	ConfigMode         ConfigMode                   `json:"configMode,omitempty"`
+	LoggingConfig      map[string]string            `json:"loggingConfig,omitempty"`
+	Ingress            *IngressConfig               `json:"ingress,omitempty"`
 }
 
 type FlinkConfig map[string]interface{}
@@ -101,6 +112,22 @@ type JobManagerConfig struct {
 	Environment           EnvironmentConfig           `json:"envConfig"`
 	Replicas              *int32                      `json:"replicas,omitempty"`
 	OffHeapMemoryFraction *float64                    `json:"offHeapMemoryFraction,omitempty"`
+	PodDisruptionBudget   *PodDisruptionBudgetConfig  `json:"podDisruptionBudget,omitempty"`
+	HighAvailability      *HighAvailabilityConfig     `json:"highAvailability,omitempty"`
+	Service               *JobManagerServiceConfig    `json:"service,omitempty"`
+}
+
+// Configures how the generic JobManager service, which is shared by all versions of the application, is exposed. The
+// versioned services used by the operator are always internal.
+type JobManagerServiceConfig struct {
+	// One of ClusterIP (default), NodePort or LoadBalancer
+	Type apiv1.ServiceType `json:"type,omitempty"`
+	// Annotations added to the service, e.g. to configure the load balancer
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Source ranges allowed to reach a LoadBalancer service
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+	// Names of the ports exposed by the service (rpc, blob, query, ui and metrics). Defaults to all ports.
+	Ports []string `json:"ports,omitempty"`
 }
 
 type TaskManagerConfig struct {
@@ -108,6 +135,63 @@ type TaskManagerConfig struct {
 	Environment           EnvironmentConfig           `json:"envConfig"`
 	TaskSlots             *int32                      `json:"taskSlots,omitempty"`
 	OffHeapMemoryFraction *float64                    `json:"offHeapMemoryFraction,omitempty"`
+	PodDisruptionBudget   *PodDisruptionBudgetConfig  `json:"podDisruptionBudget,omitempty"`
+	StatefulSet           *StatefulSetConfig          `json:"statefulSet,omitempty"`
+}
+
+// Runs the TaskManagers as a StatefulSet, which gives each of them a stable hostname and persistent volumes that are
+// kept across restarts of its pod, e.g. for RocksDB and local recovery
+type StatefulSetConfig struct {
+	// Claims for the volumes created for each TaskManager. The volumes are deleted along with the cluster.
+	VolumeClaimTemplates []apiv1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+	// Mounts of the claimed volumes into the TaskManager container
+	VolumeMounts []apiv1.VolumeMount `json:"volumeMounts,omitempty"`
+}
+
+type PodDisruptionBudgetConfig struct {
+	// Disables the PodDisruptionBudget, allowing any number of pods to be evicted at once
+	Disabled bool `json:"disabled,omitempty"`
+	// The number or percentage of pods that may be unavailable during voluntary disruptions (e.g. node drains).
+	// Defaults to 1.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+type HighAvailabilityConfig struct {
+	// The service the JobManagers use to elect a leader and to store pointers to the cluster's metadata
+	Mode HighAvailabilityMode `json:"mode"`
+	// A durable filesystem path (e.g. on S3 or HDFS) where the JobManagers persist the metadata needed to recover the
+	// cluster (high-availability.storageDir)
+	StorageDir string `json:"storageDir"`
+	// The ZooKeeper quorum, e.g. zk-0.zk:2181,zk-1.zk:2181 (high-availability.zookeeper.quorum)
+	ZookeeperQuorum string `json:"zookeeperQuorum,omitempty"`
+	// The ZooKeeper node under which the clusters store their data (high-availability.zookeeper.path.root)
+	ZookeeperPathRoot string `json:"zookeeperPathRoot,omitempty"`
+	// With Kubernetes HA, the service account the cluster's pods run as, which must be allowed to manage ConfigMaps in
+	// the application's namespace. If not set, the operator creates one for the application.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+type NetworkPolicyConfig struct {
+	// Creates a NetworkPolicy for each cluster, which only allows traffic to its pods from the other pods of the same
+	// cluster, from the operator and ingress controller to the UI port, and from the sources in Ingress
+	Enabled bool `json:"enabled,omitempty"`
+	// Additional rules for traffic allowed to reach the cluster's pods, e.g. from Prometheus to the metrics port
+	Ingress []networkingv1.NetworkPolicyIngressRule `json:"ingress,omitempty"`
+}
+
+type IngressConfig struct {
+	// Do not create an Ingress for the UI of the application
+	Disabled bool `json:"disabled,omitempty"`
+	// Host of the UI, overriding the ingressUrlFormat of the operator config
+	Host string `json:"host,omitempty"`
+	// Path under which the UI is served on the host. Defaults to all paths.
+	Path string `json:"path,omitempty"`
+	// Name of the secret with the TLS certificate for the host. If set, the Ingress terminates TLS for the UI.
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// The class of the ingress controller that should serve the Ingress
+	IngressClassName string `json:"ingressClassName,omitempty"`
+	// Annotations added to the Ingress, e.g. to configure the ingress controller
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type EnvironmentConfig struct {
@@ -115,6 +199,69 @@ type EnvironmentConfig struct {
 	Env     []apiv1.EnvVar        `json:"env,omitempty"`
 }
 
+type RestSecurityConfig struct {
+	// Serves the JobManager REST endpoint over TLS (security.ssl.rest.enabled)
+	TLSEnabled bool `json:"tlsEnabled,omitempty"`
+	// Additionally requires clients of the REST endpoint to present a certificate
+	// (security.ssl.rest.authentication-enabled)
+	MutualTLS bool `json:"mutualTLS,omitempty"`
+	// Name of a secret containing the keystore and truststore used by Flink, along with the PEM encoded CA bundle
+	// (and for mutual TLS, client certificate and key) used by the operator to connect
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// Name of a secret containing either a bearer token or a username and password, which the operator sends with
+	// every request to the REST endpoint
+	AuthSecretName string `json:"authSecretName,omitempty"`
+}
+
+// Controls when savepoints taken by the operator during updates are disposed. A savepoint is disposed once either
+// limit is exceeded; the savepoint the running job was submitted with is only disposed after DeleteAfterRestore.
+type SavepointRetentionPolicy struct {
+	// Number of the most recent deploy savepoints to keep
+	KeepLast *int32 `json:"keepLast,omitempty"`
+	// Time after which a savepoint is disposed once a job has been successfully restored from it
+	DeleteAfterRestore *metav1.Duration `json:"deleteAfterRestore,omitempty"`
+}
+
+type SavepointDisposalState string
+
+const (
+	SavepointRetained       SavepointDisposalState = ""
+	SavepointDisposing      SavepointDisposalState = "Disposing"
+	SavepointDisposed       SavepointDisposalState = "Disposed"
+	SavepointDisposalFailed SavepointDisposalState = "DisposalFailed"
+)
+
+// A savepoint taken by the operator during an update, tracked so that it can be disposed according to the
+// application's SavepointRetentionPolicy
+type SavepointRecord struct {
+	Location          string                 `json:"location"`
+	CreatedAt         metav1.Time            `json:"createdAt"`
+	RestoredAt        *metav1.Time           `json:"restoredAt,omitempty"`
+	DisposalState     SavepointDisposalState `json:"disposalState,omitempty"`
+	DisposalTriggerID string                 `json:"disposalTriggerId,omitempty"`
+	DisposalError     string                 `json:"disposalError,omitempty"`
+	DisposedAt        *metav1.Time           `json:"disposedAt,omitempty"`
+}
+
+// Selects a Flink metric that is published into the application's status while the job is running
+type FlinkMetricSelector struct {
+	// ID of the metric as reported by the Flink REST API, e.g. numRestarts or numRecordsInPerSecond
+	Name  string      `json:"name"`
+	Scope MetricScope `json:"scope,omitempty"`
+	// Name of the job vertex, required for the Vertex scope
+	Vertex string `json:"vertex,omitempty"`
+	// How the metric is aggregated across subtasks (Vertex scope) or taskmanagers (TaskManager scope). Defaults to sum.
+	Aggregation MetricAggregation `json:"aggregation,omitempty"`
+}
+
+type FlinkMetricStatus struct {
+	Name        string            `json:"name"`
+	Scope       MetricScope       `json:"scope,omitempty"`
+	Vertex      string            `json:"vertex,omitempty"`
+	Aggregation MetricAggregation `json:"aggregation,omitempty"`
+	Value       string            `json:"value"`
+}
+
 type SavepointInfo struct {
 	SavepointLocation string `json:"savepointLocation,omitempty"`
 	TriggerID         string `json:"triggerId,omitempty"`
@@ -146,6 +293,17 @@ type FlinkJobStatus struct {
 	RestorePath              string       `json:"restorePath,omitEmpty"`
 	RestoreTime              *metav1.Time `json:"restoreTime,omitEmpty"`
 	LastFailingTime          *metav1.Time `json:"lastFailingTime,omitEmpty"`
+
+	Vertices []FlinkJobVertexStatus `json:"vertices,omitempty"`
+}
+
+type FlinkJobVertexStatus struct {
+	Name              string            `json:"name"`
+	Parallelism       int32             `json:"parallelism"`
+	Status            JobState          `json:"status"`
+	RunningTasks      int32             `json:"runningTasks"`
+	FailedTasks       int32             `json:"failedTasks"`
+	BackpressureLevel BackpressureLevel `json:"backpressureLevel,omitempty"`
 }
 
 type FlinkApplicationStatus struct {
@@ -157,6 +315,23 @@ type FlinkApplicationStatus struct {
 	JobStatus        FlinkJobStatus        `json:"jobStatus"`
 	FailedDeployHash string                `json:"failedUpdateHash,omitEmpty"`
 	DeployHash       string                `json:"deployHash"`
+	Savepoints       []SavepointRecord     `json:"savepoints,omitempty"`
+
+	// The Flink version of the cluster with DeployHash, which REST calls to that cluster have to speak even after the
+	// spec moves to another version
+	DeployFlinkVersion string `json:"deployFlinkVersion,omitempty"`
+
+	// Set when the job in the cluster with DeployHash has been rescaled in place, to the hash of the application
+	// with the parallelism it was rescaled to
+	RescaledHash     string `json:"rescaledHash,omitempty"`
+	RescaleTriggerID string `json:"rescaleTriggerID,omitempty"`
+
+	// Values of the metrics selected in the spec, refreshed at most once per resync period while the job is running
+	Metrics          []FlinkMetricStatus `json:"metrics,omitempty"`
+	MetricsUpdatedAt *metav1.Time        `json:"metricsUpdatedAt,omitempty"`
+
+	// The URL of the UI of the application, served by its Ingress
+	UIURL string `json:"uiURL,omitempty"`
 }
 
 func (in *FlinkApplicationStatus) GetPhase() FlinkApplicationPhase {
@@ -200,6 +375,7 @@ const (
 	FlinkApplicationDeleting        FlinkApplicationPhase = "Deleting"
 	FlinkApplicationRollingBackJob  FlinkApplicationPhase = "RollingBackJob"
 	FlinkApplicationDeployFailed    FlinkApplicationPhase = "DeployFailed"
+	FlinkApplicationRescaling       FlinkApplicationPhase = "Rescaling"
 )
 
 var FlinkApplicationPhases = []FlinkApplicationPhase{
@@ -212,6 +388,7 @@ var FlinkApplicationPhases = []FlinkApplicationPhase{
 	FlinkApplicationDeleting,
 	FlinkApplicationDeployFailed,
 	FlinkApplicationRollingBackJob,
+	FlinkApplicationRescaling,
 }
 
 func IsRunningPhase(phase FlinkApplicationPhase) bool {
@@ -233,6 +410,41 @@ const (
 	DeleteModeNone        DeleteMode = "None"
 )
 
+type MemoryModel string
+
+const (
+	MemoryModelHeap    MemoryModel = "Heap"
+	MemoryModelProcess MemoryModel = "Process"
+)
+
+type StopMode string
+
+const (
+	StopModeCancel StopMode = "Cancel"
+	StopModeStop   StopMode = "Stop"
+	StopModeDrain  StopMode = "Drain"
+)
+
+// Determines how the Flink configuration is provided to the JobManager and TaskManager containers
+type ConfigMode string
+
+const (
+	// The configuration is passed in the OPERATOR_FLINK_CONFIG environment variable, which the image's entrypoint has
+	// to append to flink-conf.yaml
+	ConfigModeEnv ConfigMode = "Env"
+	// flink-conf.yaml and the logging configuration are rendered into a ConfigMap that is mounted into the containers
+	ConfigModeConfigMap ConfigMode = "ConfigMap"
+)
+
+// Determines how the JobManagers of a cluster elect a leader
+type HighAvailabilityMode string
+
+const (
+	HighAvailabilityModeZooKeeper HighAvailabilityMode = "ZooKeeper"
+	// Uses ConfigMaps for leader election, which requires Flink 1.12 or later
+	HighAvailabilityModeKubernetes HighAvailabilityMode = "Kubernetes"
+)
+
 type HealthStatus string
 
 const (
@@ -241,6 +453,32 @@ const (
 	Red    HealthStatus = "Red"
 )
 
+type BackpressureLevel string
+
+const (
+	BackpressureOk   BackpressureLevel = "ok"
+	BackpressureLow  BackpressureLevel = "low"
+	BackpressureHigh BackpressureLevel = "high"
+)
+
+type MetricScope string
+
+const (
+	MetricScopeJob         MetricScope = "Job"
+	MetricScopeVertex      MetricScope = "Vertex"
+	MetricScopeTaskManager MetricScope = "TaskManager"
+	MetricScopeJobManager  MetricScope = "JobManager"
+)
+
+type MetricAggregation string
+
+const (
+	MetricAggregationMin MetricAggregation = "min"
+	MetricAggregationMax MetricAggregation = "max"
+	MetricAggregationAvg MetricAggregation = "avg"
+	MetricAggregationSum MetricAggregation = "sum"
+)
+
 type JobState string
 
 const (
@@ -6,7 +6,10 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -151,6 +154,38 @@ func (in *FlinkApplicationSpec) DeepCopyInto(out *FlinkApplicationSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RestSecurity != nil {
+		in, out := &in.RestSecurity, &out.RestSecurity
+		*out = new(RestSecurityConfig)
+		**out = **in
+	}
+	if in.SavepointRetention != nil {
+		in, out := &in.SavepointRetention, &out.SavepointRetention
+		*out = new(SavepointRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]FlinkMetricSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(NetworkPolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoggingConfig != nil {
+		in, out := &in.LoggingConfig, &out.LoggingConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -177,6 +212,22 @@ func (in *FlinkApplicationStatus) DeepCopyInto(out *FlinkApplicationStatus) {
 	}
 	out.ClusterStatus = in.ClusterStatus
 	in.JobStatus.DeepCopyInto(&out.JobStatus)
+	if in.Savepoints != nil {
+		in, out := &in.Savepoints, &out.Savepoints
+		*out = make([]SavepointRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]FlinkMetricStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetricsUpdatedAt != nil {
+		in, out := &in.MetricsUpdatedAt, &out.MetricsUpdatedAt
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -225,6 +276,11 @@ func (in *FlinkJobStatus) DeepCopyInto(out *FlinkJobStatus) {
 		in, out := &in.LastFailingTime, &out.LastFailingTime
 		*out = (*in).DeepCopy()
 	}
+	if in.Vertices != nil {
+		in, out := &in.Vertices, &out.Vertices
+		*out = make([]FlinkJobVertexStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -238,6 +294,93 @@ func (in *FlinkJobStatus) DeepCopy() *FlinkJobStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkJobVertexStatus) DeepCopyInto(out *FlinkJobVertexStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkJobVertexStatus.
+func (in *FlinkJobVertexStatus) DeepCopy() *FlinkJobVertexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkJobVertexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkMetricSelector) DeepCopyInto(out *FlinkMetricSelector) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkMetricSelector.
+func (in *FlinkMetricSelector) DeepCopy() *FlinkMetricSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkMetricSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkMetricStatus) DeepCopyInto(out *FlinkMetricStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkMetricStatus.
+func (in *FlinkMetricStatus) DeepCopy() *FlinkMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HighAvailabilityConfig) DeepCopyInto(out *HighAvailabilityConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HighAvailabilityConfig.
+func (in *HighAvailabilityConfig) DeepCopy() *HighAvailabilityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HighAvailabilityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
+func (in *IngressConfig) DeepCopy() *IngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobManagerConfig) DeepCopyInto(out *JobManagerConfig) {
 	*out = *in
@@ -257,6 +400,21 @@ func (in *JobManagerConfig) DeepCopyInto(out *JobManagerConfig) {
 		*out = new(float64)
 		**out = **in
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailabilityConfig)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(JobManagerServiceConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -270,6 +428,99 @@ func (in *JobManagerConfig) DeepCopy() *JobManagerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerServiceConfig) DeepCopyInto(out *JobManagerServiceConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerServiceConfig.
+func (in *JobManagerServiceConfig) DeepCopy() *JobManagerServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicyConfig) DeepCopyInto(out *NetworkPolicyConfig) {
+	*out = *in
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = make([]networkingv1.NetworkPolicyIngressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicyConfig.
+func (in *NetworkPolicyConfig) DeepCopy() *NetworkPolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetConfig) DeepCopyInto(out *PodDisruptionBudgetConfig) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetConfig.
+func (in *PodDisruptionBudgetConfig) DeepCopy() *PodDisruptionBudgetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestSecurityConfig) DeepCopyInto(out *RestSecurityConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestSecurityConfig.
+func (in *RestSecurityConfig) DeepCopy() *RestSecurityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RestSecurityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SavepointInfo) DeepCopyInto(out *SavepointInfo) {
 	*out = *in
@@ -286,6 +537,87 @@ func (in *SavepointInfo) DeepCopy() *SavepointInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavepointRecord) DeepCopyInto(out *SavepointRecord) {
+	*out = *in
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	if in.RestoredAt != nil {
+		in, out := &in.RestoredAt, &out.RestoredAt
+		*out = (*in).DeepCopy()
+	}
+	if in.DisposedAt != nil {
+		in, out := &in.DisposedAt, &out.DisposedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavepointRecord.
+func (in *SavepointRecord) DeepCopy() *SavepointRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(SavepointRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavepointRetentionPolicy) DeepCopyInto(out *SavepointRetentionPolicy) {
+	*out = *in
+	if in.KeepLast != nil {
+		in, out := &in.KeepLast, &out.KeepLast
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DeleteAfterRestore != nil {
+		in, out := &in.DeleteAfterRestore, &out.DeleteAfterRestore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavepointRetentionPolicy.
+func (in *SavepointRetentionPolicy) DeepCopy() *SavepointRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SavepointRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetConfig) DeepCopyInto(out *StatefulSetConfig) {
+	*out = *in
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]v1.PersistentVolumeClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetConfig.
+func (in *StatefulSetConfig) DeepCopy() *StatefulSetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskManagerConfig) DeepCopyInto(out *TaskManagerConfig) {
 	*out = *in
@@ -305,6 +637,16 @@ func (in *TaskManagerConfig) DeepCopyInto(out *TaskManagerConfig) {
 		*out = new(float64)
 		**out = **in
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(StatefulSetConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
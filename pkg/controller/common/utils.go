@@ -54,5 +54,16 @@ func GetEnvVar(envs []v1.EnvVar, name string) *v1.EnvVar {
 type FlinkDeployment struct {
 	Jobmanager  *appsv1.Deployment
 	Taskmanager *appsv1.Deployment
-	Hash        string
+	// Set instead of Taskmanager if the TaskManagers of the cluster run as a StatefulSet
+	TaskmanagerStatefulSet *appsv1.StatefulSet
+	Hash                   string
+}
+
+// A failure of a pod in a Flink cluster, as determined from the pod and container statuses
+type PodFailure struct {
+	PodName string
+	Reason  string
+	Message string
+	// True if the failure is not expected to resolve without a change to the application
+	Unrecoverable bool
 }
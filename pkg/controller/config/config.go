@@ -1,26 +1,124 @@
 package config
 
 import (
+	"io/ioutil"
+	"strings"
+
 	"github.com/lyft/flytestdlib/config"
+	"github.com/pkg/errors"
 )
 
 //go:generate pflags Config
 
-const configSectionKey = "operator"
+const (
+	configSectionKey            = "operator"
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
 
 var ConfigSection = config.MustRegisterSection(configSectionKey, &Config{})
 
 type Config struct {
-	ResyncPeriod                  config.Duration `json:"resyncPeriod" pflag:"\"30s\",Determines the resync period for all watchers."`
-	LimitNamespace                string          `json:"limitNamespace" pflag:"\"\",Namespaces to watch for by flink operator"`
-	MetricsPrefix                 string          `json:"metricsPrefix" pflag:"\"flinkk8soperator\",Prefix for metrics propagated to prometheus"`
-	ProfilerPort                  config.Port     `json:"prof-port" pflag:"\"10254\",Profiler port"`
-	FlinkIngressURLFormat         string          `json:"ingressUrlFormat"`
-	UseProxy                      bool            `json:"useKubectlProxy"`
-	ProxyPort                     config.Port     `json:"ProxyPort" pflag:"\"8001\",The port at which flink cluster runs locally"`
-	ContainerNameFormat           string          `json:"containerNameFormat"`
-	Workers                       int             `json:"workers" pflag:"4,Number of routines to process custom resource"`
-	StatemachineStalenessDuration config.Duration `json:"statemachineStalenessDuration" pflag:"\"5m\",Duration for statemachine staleness."`
+	ResyncPeriod                  config.Duration      `json:"resyncPeriod" pflag:"\"30s\",Determines the resync period for all watchers."`
+	LimitNamespace                string               `json:"limitNamespace" pflag:"\"\",Namespaces to watch for by flink operator"`
+	LimitNamespaces               []string             `json:"limitNamespaces" pflag:",Namespaces to watch in addition to limitNamespace."`
+	NamespaceSelector             string               `json:"namespaceSelector" pflag:",Label selector (e.g. flink-enabled=true) for additional namespaces to watch."`
+	MetricsPrefix                 string               `json:"metricsPrefix" pflag:"\"flinkk8soperator\",Prefix for metrics propagated to prometheus"`
+	ProfilerPort                  config.Port          `json:"prof-port" pflag:"\"10254\",Profiler port"`
+	FlinkIngressURLFormat         string               `json:"ingressUrlFormat"`
+	UseProxy                      bool                 `json:"useKubectlProxy"`
+	ProxyPort                     config.Port          `json:"ProxyPort" pflag:"\"8001\",The port at which flink cluster runs locally"`
+	ContainerNameFormat           string               `json:"containerNameFormat"`
+	Workers                       int                  `json:"workers" pflag:"4,Number of routines to process custom resource"`
+	StatemachineStalenessDuration config.Duration      `json:"statemachineStalenessDuration" pflag:"\"5m\",Duration for statemachine staleness."`
+	SampleBackpressure            bool                 `json:"sampleBackpressure" pflag:",Sample per-vertex backpressure when updating job status."`
+	OrphanedClusterGracePeriod    config.Duration      `json:"orphanedClusterGracePeriod" pflag:"\"10m\",Time after their creation that clusters which are neither deployed nor being deployed are deleted."`
+	Tracing                       TracingConfig        `json:"tracing"`
+	FlinkClient                   FlinkClientConfig    `json:"flinkClient"`
+	NetworkPolicy                 NetworkPolicyConfig  `json:"networkPolicy"`
+	LeaderElection                LeaderElectionConfig `json:"leaderElection"`
+	Health                        HealthConfig         `json:"health"`
+	Sharding                      ShardingConfig       `json:"sharding"`
+}
+
+type TracingConfig struct {
+	Exporter string `json:"exporter" pflag:"\"noop\",Span exporter to use. Only noop is available, which drops spans."`
+}
+
+type FlinkClientConfig struct {
+	ReadTimeout                config.Duration `json:"readTimeout" pflag:"\"5s\",Timeout for each attempt of a request that reads from the JobManager."`
+	ReadRetries                int             `json:"readRetries" pflag:"3,Number of times a failed read from the JobManager is retried."`
+	WriteTimeout               config.Duration `json:"writeTimeout" pflag:"\"30s\",Timeout for each attempt of a request that submits, cancels or stops a job."`
+	WriteRetries               int             `json:"writeRetries" pflag:"3,Number of times a request that submits, cancels or stops a job is retried if the JobManager could not be connected to."`
+	CircuitBreakerThreshold    int             `json:"circuitBreakerThreshold" pflag:"5,Consecutive failed requests after which requests to a JobManager fail fast (0 to disable)."`
+	CircuitBreakerResetTimeout config.Duration `json:"circuitBreakerResetTimeout" pflag:"\"30s\",Time after which a request is let through to a JobManager that has been failing."`
+	RateLimitQPS               float64         `json:"rateLimitQPS" pflag:"100,Maximum requests per second across all JobManagers (0 to disable)."`
+	RateLimitBurst             int             `json:"rateLimitBurst" pflag:"200,Maximum burst of requests across all JobManagers."`
+}
+
+// Label selectors (e.g. "app=flinkoperator") for the pods allowed to reach the UI port of clusters with network
+// policies enabled. If neither selector is set for a source, the UI port is open to all sources.
+type NetworkPolicyConfig struct {
+	OperatorNamespaceSelector string `json:"operatorNamespaceSelector" pflag:",Label selector for the namespaces of the operator pods allowed to reach the JobManager REST port."`
+	OperatorPodSelector       string `json:"operatorPodSelector" pflag:",Label selector for the operator pods allowed to reach the JobManager REST port."`
+	IngressNamespaceSelector  string `json:"ingressNamespaceSelector" pflag:",Label selector for the namespaces of the ingress controller pods allowed to reach the JobManager UI."`
+	IngressPodSelector        string `json:"ingressPodSelector" pflag:",Label selector for the ingress controller pods allowed to reach the JobManager UI."`
+}
+
+// With leader election enabled, only the replica holding the lock reconciles applications. The other replicas keep
+// their caches in sync, so that they can take over as soon as they acquire the lock.
+type LeaderElectionConfig struct {
+	Enabled       bool            `json:"enabled" pflag:",Run multiple replicas of the operator, of which only the elected leader reconciles applications."`
+	LockNamespace string          `json:"lockNamespace" pflag:",Namespace of the ConfigMap used as the leader election lock (defaults to the limitNamespace)."`
+	LockName      string          `json:"lockName" pflag:"\"flinkk8soperator-lock\",Name of the ConfigMap used as the leader election lock."`
+	LeaseDuration config.Duration `json:"leaseDuration" pflag:"\"15s\",Time that non-leaders wait after the last renewal before trying to acquire leadership."`
+	RenewDeadline config.Duration `json:"renewDeadline" pflag:"\"10s\",Time for which the leader retries renewing its leadership before giving it up."`
+	RetryPeriod   config.Duration `json:"retryPeriod" pflag:"\"2s\",Time between attempts to acquire or renew leadership."`
+}
+
+// Thresholds of the checks served on the profiler port at /healthz and /readyz
+type HealthConfig struct {
+	MaxQueueDepth   int             `json:"maxQueueDepth" pflag:"1000,Number of queued reconciliations above which the operator is reported as not ready (0 to disable)."`
+	MaxReconcileAge config.Duration `json:"maxReconcileAge" pflag:"\"15m\",Time without a successful reconciliation while reconciliations are queued after which the operator is reported as unhealthy (0 to disable)."`
+}
+
+// With sharding enabled, applications are split between shardCount operator instances, each of which only reconciles
+// the applications assigned to its shardIndex
+type ShardingConfig struct {
+	ShardCount int    `json:"shardCount" pflag:",Number of operator instances that applications are split between (0 or 1 to disable sharding)."`
+	ShardIndex int    `json:"shardIndex" pflag:",Shard handled by this instance, from 0 to shardCount - 1, or -1 to use the ordinal at the end of the hostname (as assigned by a StatefulSet)."`
+	ShardLabel string `json:"shardLabel" pflag:",Label whose value assigns applications to shards: a number selects the shard, other values are hashed. Applications without it are assigned by hashing their namespace and name."`
+}
+
+// Whether applications are watched in a list of namespaces or in the namespaces matching a selector
+func (c *Config) WatchesMultipleNamespaces() bool {
+	return len(c.LimitNamespaces) > 0 || c.NamespaceSelector != ""
+}
+
+// Returns the namespace that the cache of the manager is limited to, or an empty string if applications are watched
+// in more than one namespace. The controller then caches the objects of each watched namespace separately, so that
+// the cache of the manager is not used for them.
+func (c *Config) GetCacheNamespace() string {
+	if c.WatchesMultipleNamespaces() {
+		return ""
+	}
+	return c.LimitNamespace
+}
+
+// Returns the namespace of the leader election locks: the configured lockNamespace, the namespace the caches are
+// limited to, or the namespace the operator is running in
+func (c *Config) GetLeaderElectionNamespace() (string, error) {
+	if c.LeaderElection.LockNamespace != "" {
+		return c.LeaderElection.LockNamespace, nil
+	}
+	if namespace := c.GetCacheNamespace(); namespace != "" {
+		return namespace, nil
+	}
+
+	namespace, err := ioutil.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return "", errors.Wrap(err, "leaderElection.lockNamespace must be set when running outside of a cluster")
+	}
+	return strings.TrimSpace(string(namespace)), nil
 }
 
 func GetConfig() *Config {
@@ -15,6 +15,8 @@ func (Config) GetPFlagSet(prefix string) *pflag.FlagSet {
 	cmdFlags := pflag.NewFlagSet("Config", pflag.ExitOnError)
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "resyncPeriod"), "30s", "Determines the resync period for all watchers.")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "limitNamespace"), "", "Namespaces to watch for by flink operator")
+	cmdFlags.StringSlice(fmt.Sprintf("%v%v", prefix, "limitNamespaces"), []string{}, "Namespaces to watch in addition to limitNamespace.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "namespaceSelector"), *new(string), "Label selector (e.g. flink-enabled=true) for additional namespaces to watch.")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "metricsPrefix"), "flinkk8soperator", "Prefix for metrics propagated to prometheus")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "prof-port"), "10254", "Profiler port")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "ingressUrlFormat"), *new(string), "")
@@ -23,5 +25,31 @@ func (Config) GetPFlagSet(prefix string) *pflag.FlagSet {
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "containerNameFormat"), *new(string), "")
 	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "workers"), 4, "Number of routines to process custom resource")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "statemachineStalenessDuration"), "5m", "Duration for statemachine staleness.")
+	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "sampleBackpressure"), *new(bool), "Sample per-vertex backpressure when updating job status.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "orphanedClusterGracePeriod"), "10m", "Time after their creation that clusters which are neither deployed nor being deployed are deleted.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "tracing.exporter"), "noop", "Span exporter to use. Only noop is available, which drops spans.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "flinkClient.readTimeout"), "5s", "Timeout for each attempt of a request that reads from the JobManager.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "flinkClient.readRetries"), 3, "Number of times a failed read from the JobManager is retried.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "flinkClient.writeTimeout"), "30s", "Timeout for each attempt of a request that submits, cancels or stops a job.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "flinkClient.writeRetries"), 3, "Number of times a request that submits, cancels or stops a job is retried if the JobManager could not be connected to.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "flinkClient.circuitBreakerThreshold"), 5, "Consecutive failed requests after which requests to a JobManager fail fast (0 to disable).")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "flinkClient.circuitBreakerResetTimeout"), "30s", "Time after which a request is let through to a JobManager that has been failing.")
+	cmdFlags.Float64(fmt.Sprintf("%v%v", prefix, "flinkClient.rateLimitQPS"), 100, "Maximum requests per second across all JobManagers (0 to disable).")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "flinkClient.rateLimitBurst"), 200, "Maximum burst of requests across all JobManagers.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "networkPolicy.operatorNamespaceSelector"), *new(string), "Label selector for the namespaces of the operator pods allowed to reach the JobManager REST port.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "networkPolicy.operatorPodSelector"), *new(string), "Label selector for the operator pods allowed to reach the JobManager REST port.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "networkPolicy.ingressNamespaceSelector"), *new(string), "Label selector for the namespaces of the ingress controller pods allowed to reach the JobManager UI.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "networkPolicy.ingressPodSelector"), *new(string), "Label selector for the ingress controller pods allowed to reach the JobManager UI.")
+	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "leaderElection.enabled"), *new(bool), "Run multiple replicas of the operator, of which only the elected leader reconciles applications.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "leaderElection.lockNamespace"), *new(string), "Namespace of the ConfigMap used as the leader election lock (defaults to the limitNamespace).")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "leaderElection.lockName"), "flinkk8soperator-lock", "Name of the ConfigMap used as the leader election lock.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "leaderElection.leaseDuration"), "15s", "Time that non-leaders wait after the last renewal before trying to acquire leadership.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "leaderElection.renewDeadline"), "10s", "Time for which the leader retries renewing its leadership before giving it up.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "leaderElection.retryPeriod"), "2s", "Time between attempts to acquire or renew leadership.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "health.maxQueueDepth"), 1000, "Number of queued reconciliations above which the operator is reported as not ready (0 to disable).")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "health.maxReconcileAge"), "15m", "Time without a successful reconciliation while reconciliations are queued after which the operator is reported as unhealthy (0 to disable).")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "sharding.shardCount"), *new(int), "Number of operator instances that applications are split between (0 or 1 to disable sharding).")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "sharding.shardIndex"), *new(int), "Shard handled by this instance, from 0 to shardCount - 1, or -1 to use the ordinal at the end of the hostname (as assigned by a StatefulSet).")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "sharding.shardLabel"), *new(string), "Label whose value assigns applications to shards: a number selects the shard, other values are hashed. Applications without it are assigned by hashing their namespace and name.")
 	return cmdFlags
 }
@@ -143,6 +143,50 @@ func TestConfig_SetFlags(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Test_limitNamespaces", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vStringSlice, err := cmdFlags.GetStringSlice("limitNamespaces"); err == nil {
+				assert.Equal(t, []string([]string{}), vStringSlice)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := join_Config("1,1", ",")
+
+			cmdFlags.Set("limitNamespaces", testValue)
+			if vStringSlice, err := cmdFlags.GetStringSlice("limitNamespaces"); err == nil {
+				testDecodeSlice_Config(t, join_Config(vStringSlice, ","), &actual.LimitNamespaces)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_namespaceSelector", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("namespaceSelector"); err == nil {
+				assert.Equal(t, string(*new(string)), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("namespaceSelector", testValue)
+			if vString, err := cmdFlags.GetString("namespaceSelector"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.NamespaceSelector)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
 	t.Run("Test_metricsPrefix", func(t *testing.T) {
 		t.Run("DefaultValue", func(t *testing.T) {
 			// Test that default value is set properly
@@ -319,4 +363,576 @@ func TestConfig_SetFlags(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Test_sampleBackpressure", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vBool, err := cmdFlags.GetBool("sampleBackpressure"); err == nil {
+				assert.Equal(t, bool(*new(bool)), vBool)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("sampleBackpressure", testValue)
+			if vBool, err := cmdFlags.GetBool("sampleBackpressure"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vBool), &actual.SampleBackpressure)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_orphanedClusterGracePeriod", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("orphanedClusterGracePeriod"); err == nil {
+				assert.Equal(t, string("10m"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "10m"
+
+			cmdFlags.Set("orphanedClusterGracePeriod", testValue)
+			if vString, err := cmdFlags.GetString("orphanedClusterGracePeriod"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.OrphanedClusterGracePeriod)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_tracing.exporter", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("tracing.exporter"); err == nil {
+				assert.Equal(t, string("noop"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("tracing.exporter", testValue)
+			if vString, err := cmdFlags.GetString("tracing.exporter"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Tracing.Exporter)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.readTimeout", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("flinkClient.readTimeout"); err == nil {
+				assert.Equal(t, string("5s"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "5s"
+
+			cmdFlags.Set("flinkClient.readTimeout", testValue)
+			if vString, err := cmdFlags.GetString("flinkClient.readTimeout"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.FlinkClient.ReadTimeout)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.readRetries", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("flinkClient.readRetries"); err == nil {
+				assert.Equal(t, int(3), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("flinkClient.readRetries", testValue)
+			if vInt, err := cmdFlags.GetInt("flinkClient.readRetries"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.FlinkClient.ReadRetries)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.writeTimeout", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("flinkClient.writeTimeout"); err == nil {
+				assert.Equal(t, string("30s"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "30s"
+
+			cmdFlags.Set("flinkClient.writeTimeout", testValue)
+			if vString, err := cmdFlags.GetString("flinkClient.writeTimeout"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.FlinkClient.WriteTimeout)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.writeRetries", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("flinkClient.writeRetries"); err == nil {
+				assert.Equal(t, int(3), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("flinkClient.writeRetries", testValue)
+			if vInt, err := cmdFlags.GetInt("flinkClient.writeRetries"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.FlinkClient.WriteRetries)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.circuitBreakerThreshold", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("flinkClient.circuitBreakerThreshold"); err == nil {
+				assert.Equal(t, int(5), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("flinkClient.circuitBreakerThreshold", testValue)
+			if vInt, err := cmdFlags.GetInt("flinkClient.circuitBreakerThreshold"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.FlinkClient.CircuitBreakerThreshold)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.circuitBreakerResetTimeout", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("flinkClient.circuitBreakerResetTimeout"); err == nil {
+				assert.Equal(t, string("30s"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "30s"
+
+			cmdFlags.Set("flinkClient.circuitBreakerResetTimeout", testValue)
+			if vString, err := cmdFlags.GetString("flinkClient.circuitBreakerResetTimeout"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.FlinkClient.CircuitBreakerResetTimeout)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.rateLimitQPS", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vFloat64, err := cmdFlags.GetFloat64("flinkClient.rateLimitQPS"); err == nil {
+				assert.Equal(t, float64(100), vFloat64)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("flinkClient.rateLimitQPS", testValue)
+			if vFloat64, err := cmdFlags.GetFloat64("flinkClient.rateLimitQPS"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vFloat64), &actual.FlinkClient.RateLimitQPS)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_flinkClient.rateLimitBurst", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("flinkClient.rateLimitBurst"); err == nil {
+				assert.Equal(t, int(200), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("flinkClient.rateLimitBurst", testValue)
+			if vInt, err := cmdFlags.GetInt("flinkClient.rateLimitBurst"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.FlinkClient.RateLimitBurst)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_networkPolicy.operatorNamespaceSelector", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("networkPolicy.operatorNamespaceSelector"); err == nil {
+				assert.Equal(t, string(*new(string)), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("networkPolicy.operatorNamespaceSelector", testValue)
+			if vString, err := cmdFlags.GetString("networkPolicy.operatorNamespaceSelector"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.NetworkPolicy.OperatorNamespaceSelector)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_networkPolicy.operatorPodSelector", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("networkPolicy.operatorPodSelector"); err == nil {
+				assert.Equal(t, string(*new(string)), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("networkPolicy.operatorPodSelector", testValue)
+			if vString, err := cmdFlags.GetString("networkPolicy.operatorPodSelector"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.NetworkPolicy.OperatorPodSelector)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_networkPolicy.ingressNamespaceSelector", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("networkPolicy.ingressNamespaceSelector"); err == nil {
+				assert.Equal(t, string(*new(string)), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("networkPolicy.ingressNamespaceSelector", testValue)
+			if vString, err := cmdFlags.GetString("networkPolicy.ingressNamespaceSelector"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.NetworkPolicy.IngressNamespaceSelector)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_networkPolicy.ingressPodSelector", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("networkPolicy.ingressPodSelector"); err == nil {
+				assert.Equal(t, string(*new(string)), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("networkPolicy.ingressPodSelector", testValue)
+			if vString, err := cmdFlags.GetString("networkPolicy.ingressPodSelector"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.NetworkPolicy.IngressPodSelector)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_leaderElection.enabled", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vBool, err := cmdFlags.GetBool("leaderElection.enabled"); err == nil {
+				assert.Equal(t, bool(*new(bool)), vBool)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("leaderElection.enabled", testValue)
+			if vBool, err := cmdFlags.GetBool("leaderElection.enabled"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vBool), &actual.LeaderElection.Enabled)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_leaderElection.lockNamespace", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("leaderElection.lockNamespace"); err == nil {
+				assert.Equal(t, string(*new(string)), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("leaderElection.lockNamespace", testValue)
+			if vString, err := cmdFlags.GetString("leaderElection.lockNamespace"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.LeaderElection.LockNamespace)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_leaderElection.lockName", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("leaderElection.lockName"); err == nil {
+				assert.Equal(t, string("flinkk8soperator-lock"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "flinkk8soperator-lock"
+
+			cmdFlags.Set("leaderElection.lockName", testValue)
+			if vString, err := cmdFlags.GetString("leaderElection.lockName"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.LeaderElection.LockName)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_leaderElection.leaseDuration", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("leaderElection.leaseDuration"); err == nil {
+				assert.Equal(t, string("15s"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "15s"
+
+			cmdFlags.Set("leaderElection.leaseDuration", testValue)
+			if vString, err := cmdFlags.GetString("leaderElection.leaseDuration"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.LeaderElection.LeaseDuration)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_leaderElection.renewDeadline", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("leaderElection.renewDeadline"); err == nil {
+				assert.Equal(t, string("10s"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "10s"
+
+			cmdFlags.Set("leaderElection.renewDeadline", testValue)
+			if vString, err := cmdFlags.GetString("leaderElection.renewDeadline"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.LeaderElection.RenewDeadline)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_leaderElection.retryPeriod", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("leaderElection.retryPeriod"); err == nil {
+				assert.Equal(t, string("2s"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "2s"
+
+			cmdFlags.Set("leaderElection.retryPeriod", testValue)
+			if vString, err := cmdFlags.GetString("leaderElection.retryPeriod"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.LeaderElection.RetryPeriod)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_health.maxQueueDepth", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("health.maxQueueDepth"); err == nil {
+				assert.Equal(t, int(1000), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("health.maxQueueDepth", testValue)
+			if vInt, err := cmdFlags.GetInt("health.maxQueueDepth"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.Health.MaxQueueDepth)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_health.maxReconcileAge", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("health.maxReconcileAge"); err == nil {
+				assert.Equal(t, string("15m"), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "15m"
+
+			cmdFlags.Set("health.maxReconcileAge", testValue)
+			if vString, err := cmdFlags.GetString("health.maxReconcileAge"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Health.MaxReconcileAge)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_sharding.shardCount", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("sharding.shardCount"); err == nil {
+				assert.Equal(t, int(*new(int)), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("sharding.shardCount", testValue)
+			if vInt, err := cmdFlags.GetInt("sharding.shardCount"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.Sharding.ShardCount)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_sharding.shardIndex", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("sharding.shardIndex"); err == nil {
+				assert.Equal(t, int(*new(int)), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("sharding.shardIndex", testValue)
+			if vInt, err := cmdFlags.GetInt("sharding.shardIndex"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.Sharding.ShardIndex)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_sharding.shardLabel", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("sharding.shardLabel"); err == nil {
+				assert.Equal(t, string(*new(string)), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("sharding.shardLabel", testValue)
+			if vString, err := cmdFlags.GetString("sharding.shardLabel"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Sharding.ShardLabel)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
 }
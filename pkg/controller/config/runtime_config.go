@@ -1,9 +1,11 @@
 package config
 
 import (
+	"github.com/lyft/flinkk8soperator/pkg/controller/health"
 	"github.com/lyft/flytestdlib/promutils"
 )
 
 type RuntimeConfig struct {
 	MetricsScope promutils.Scope
+	Health       *health.Monitor
 }
@@ -7,4 +7,49 @@ const (
 	CausedByError            ErrorCode = "CausedByError"
 	BadJobSpecificationError ErrorCode = "BadJobSpecificationError"
 	ReconciliationNeeded     ErrorCode = "ReconciliationNeeded"
+
+	// The JobManager could not be reached, timed out or reported that it is not available
+	JobManagerUnavailableError ErrorCode = "JobManagerUnavailableError"
+	// Flink rejected a request made by the operator as invalid
+	FlinkBadRequestError ErrorCode = "FlinkBadRequestError"
+	// Flink did not find the job or asynchronous operation that a request referred to
+	FlinkNotFoundError ErrorCode = "FlinkNotFoundError"
+	// Flink failed to handle a request
+	FlinkInternalError ErrorCode = "FlinkInternalError"
+	// Flink returned a response that could not be parsed
+	InvalidResponseError ErrorCode = "InvalidResponseError"
+	// The job could not be submitted because of a problem with the application, e.g. a missing jar or entry class, or
+	// a main method that throws
+	JobSubmissionError ErrorCode = "JobSubmissionError"
+	// The operation is not supported by the application's version of Flink
+	UnsupportedOperationError ErrorCode = "UnsupportedOperationError"
 )
+
+// Whether an error is the user's fault, i.e. caused by the application or its spec, or the system's (Flink, Kubernetes
+// or the operator)
+type ErrorSource string
+
+const (
+	UserFault   ErrorSource = "User"
+	SystemFault ErrorSource = "System"
+)
+
+type errorClass struct {
+	source ErrorSource
+	// Whether the error may resolve by retrying without a change to the application
+	retryable bool
+}
+
+var errorClasses = map[ErrorCode]errorClass{
+	IllegalStateError:          {source: SystemFault, retryable: false},
+	CausedByError:              {source: SystemFault, retryable: true},
+	BadJobSpecificationError:   {source: UserFault, retryable: false},
+	ReconciliationNeeded:       {source: SystemFault, retryable: true},
+	JobManagerUnavailableError: {source: SystemFault, retryable: true},
+	FlinkBadRequestError:       {source: SystemFault, retryable: false},
+	FlinkNotFoundError:         {source: SystemFault, retryable: false},
+	FlinkInternalError:         {source: SystemFault, retryable: true},
+	InvalidResponseError:       {source: SystemFault, retryable: true},
+	JobSubmissionError:         {source: UserFault, retryable: false},
+	UnsupportedOperationError:  {source: UserFault, retryable: false},
+}
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"net/http"
 )
 
 type ErrorMessage = string
@@ -9,6 +10,11 @@ type ErrorMessage = string
 type FlinkOperatorError struct {
 	Code    ErrorCode
 	Message ErrorMessage
+
+	// Set for errors returned by the Flink REST API: the HTTP status of the response, and the error messages Flink
+	// reported in its body
+	HTTPStatus  int
+	FlinkErrors []string
 }
 
 func (w *FlinkOperatorError) Error() string {
@@ -46,11 +52,97 @@ func WrapErrorf(c ErrorCode, cause error, msgFmt string, args ...interface{}) er
 	}
 }
 
-func IsReconciliationNeeded(err error) bool {
-	if fErr, ok := err.(*FlinkOperatorError); ok {
-		if fErr.Code == ReconciliationNeeded {
-			return true
+// Returns an error for an unsuccessful response from the Flink REST API, classified by its HTTP status
+func FlinkResponseErrorf(status int, flinkErrors []string, msgFmt string, args ...interface{}) error {
+	err := errorf(GetCodeForHTTPStatus(status), msgFmt, args...)
+	err.HTTPStatus = status
+	err.FlinkErrors = flinkErrors
+	return err
+}
+
+func GetCodeForHTTPStatus(status int) ErrorCode {
+	switch {
+	case status == http.StatusNotFound:
+		return FlinkNotFoundError
+	case status == http.StatusTooManyRequests, status == http.StatusBadGateway,
+		status == http.StatusServiceUnavailable, status == http.StatusGatewayTimeout:
+		return JobManagerUnavailableError
+	case status >= http.StatusInternalServerError:
+		return FlinkInternalError
+	default:
+		return FlinkBadRequestError
+	}
+}
+
+// Returns the outermost FlinkOperatorError in err's chain of causes
+func getFlinkOperatorError(err error) *FlinkOperatorError {
+	for err != nil {
+		switch e := err.(type) {
+		case *FlinkOperatorError:
+			return e
+		case *FlinkOperatorErrorWithCause:
+			return e.FlinkOperatorError
+		}
+
+		causer, ok := err.(interface {
+			Cause() error
+		})
+		if !ok {
+			return nil
+		}
+		err = causer.Cause()
+	}
+	return nil
+}
+
+func GetErrorCode(err error) (ErrorCode, bool) {
+	if fErr := getFlinkOperatorError(err); fErr != nil {
+		return fErr.Code, true
+	}
+	return "", false
+}
+
+// Errors that have not been classified are assumed to be the system's fault
+func GetErrorSource(err error) ErrorSource {
+	if code, ok := GetErrorCode(err); ok {
+		if class, ok := errorClasses[code]; ok {
+			return class.source
 		}
 	}
-	return false
+	return SystemFault
+}
+
+func IsUserError(err error) bool {
+	return GetErrorSource(err) == UserFault
+}
+
+// Returns true if the error may resolve by retrying without a change to the application. Errors that have not been
+// classified are assumed to be retryable.
+func IsRetryable(err error) bool {
+	if code, ok := GetErrorCode(err); ok {
+		if class, ok := errorClasses[code]; ok {
+			return class.retryable
+		}
+	}
+	return true
+}
+
+// Returns the HTTP status of the Flink REST response that caused the error, or zero if there was none
+func GetHTTPStatus(err error) int {
+	if fErr := getFlinkOperatorError(err); fErr != nil {
+		return fErr.HTTPStatus
+	}
+	return 0
+}
+
+func GetFlinkErrors(err error) []string {
+	if fErr := getFlinkOperatorError(err); fErr != nil {
+		return fErr.FlinkErrors
+	}
+	return nil
+}
+
+func IsReconciliationNeeded(err error) bool {
+	code, ok := GetErrorCode(err)
+	return ok && code == ReconciliationNeeded
 }
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMessages(t *testing.T) {
+	err := Errorf(BadJobSpecificationError, "invalid parallelism %d", -1)
+	assert.EqualError(t, err, "ErrorCode: [BadJobSpecificationError] Reason: [invalid parallelism -1]")
+
+	err = WrapErrorf(JobManagerUnavailableError, pkgErrors.New("connection refused"), "Get jobs API request failed")
+	assert.EqualError(t, err, "ErrorCode: [JobManagerUnavailableError] Reason: [Get jobs API request failed]. Caused By [connection refused]")
+}
+
+func TestErrorClassification(t *testing.T) {
+	userErr := Errorf(BadJobSpecificationError, "invalid spec")
+	assert.True(t, IsUserError(userErr))
+	assert.False(t, IsRetryable(userErr))
+
+	systemErr := WrapErrorf(JobManagerUnavailableError, pkgErrors.New("timeout"), "request failed")
+	assert.False(t, IsUserError(systemErr))
+	assert.True(t, IsRetryable(systemErr))
+
+	// errors that have not been classified are retryable system errors
+	plainErr := pkgErrors.New("plain")
+	assert.False(t, IsUserError(plainErr))
+	assert.True(t, IsRetryable(plainErr))
+	_, ok := GetErrorCode(plainErr)
+	assert.False(t, ok)
+
+	// classification is preserved when errors are wrapped
+	wrapped := pkgErrors.Wrap(userErr, "failed to deploy")
+	code, ok := GetErrorCode(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, BadJobSpecificationError, code)
+	assert.True(t, IsUserError(wrapped))
+
+	assert.True(t, IsReconciliationNeeded(pkgErrors.Wrap(Errorf(ReconciliationNeeded, "retry"), "wrapped")))
+	assert.False(t, IsReconciliationNeeded(userErr))
+}
+
+func TestFlinkResponseErrors(t *testing.T) {
+	err := FlinkResponseErrorf(http.StatusNotFound, []string{"Job j1 not found"}, "Get job failed with status %d", 404)
+	assert.EqualError(t, err, "ErrorCode: [FlinkNotFoundError] Reason: [Get job failed with status 404]")
+	assert.Equal(t, http.StatusNotFound, GetHTTPStatus(err))
+	assert.Equal(t, []string{"Job j1 not found"}, GetFlinkErrors(pkgErrors.Wrap(err, "wrapped")))
+	code, _ := GetErrorCode(err)
+	assert.Equal(t, FlinkNotFoundError, code)
+
+	assert.Equal(t, FlinkBadRequestError, GetCodeForHTTPStatus(http.StatusBadRequest))
+	assert.Equal(t, JobManagerUnavailableError, GetCodeForHTTPStatus(http.StatusServiceUnavailable))
+	assert.Equal(t, JobManagerUnavailableError, GetCodeForHTTPStatus(http.StatusTooManyRequests))
+	assert.Equal(t, FlinkInternalError, GetCodeForHTTPStatus(http.StatusInternalServerError))
+
+	assert.Equal(t, 0, GetHTTPStatus(pkgErrors.New("plain")))
+}
@@ -2,41 +2,68 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/go-resty/resty"
 	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
+	"github.com/lyft/flinkk8soperator/pkg/controller/tracing"
 	"github.com/lyft/flytestdlib/logger"
 	"github.com/lyft/flytestdlib/promutils"
 	"github.com/lyft/flytestdlib/promutils/labeled"
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
 const submitJobURL = "/jars/%s/run"
 const savepointURL = "/jobs/%s/savepoints"
+const stopURL = "/jobs/%s/stop"
+const rescalingURL = "/jobs/%s/rescaling?parallelism=%d"
+const checkRescalingStatusURL = "/jobs/%s/rescaling/%s"
 const jobURL = "/jobs/%s"
 const checkSavepointStatusURL = "/jobs/%s/savepoints/%s"
+const savepointDisposalURL = "/savepoint-disposal"
+const savepointDisposalStatusURL = "/savepoint-disposal/%s"
 const getJobsURL = "/jobs"
 const getJobsOverviewURL = "/jobs/%s"
 const getJobConfigURL = "/jobs/%s/config"
 const getOverviewURL = "/overview"
 const checkpointsURL = "/jobs/%s/checkpoints"
 const taskmanagersURL = "/taskmanagers"
+const vertexBackpressureURL = "/jobs/%s/vertices/%s/backpressure"
+const jobMetricsURL = "/jobs/%s/metrics"
+const aggregatedJobMetricsURL = "/jobs/metrics"
+const vertexMetricsURL = "/jobs/%s/vertices/%s/metrics"
+const subtaskMetricsURL = "/jobs/%s/vertices/%s/subtasks/metrics"
+const taskManagerMetricsURL = "/taskmanagers/%s/metrics"
+const aggregatedTaskManagerMetricsURL = "/taskmanagers/metrics"
+const jobManagerMetricsURL = "/jobmanager/metrics"
+const programInvocationException = "ProgramInvocationException"
 const httpGet = "GET"
 const httpPost = "POST"
 const httpPatch = "PATCH"
-const retryCount = 3
-const timeOut = 5 * time.Second
+const defaultTimeout = 5 * time.Second
+const minRetryWait = 100 * time.Millisecond
+const maxRetryWait = 2 * time.Second
 
 type FlinkAPIInterface interface {
 	CancelJobWithSavepoint(ctx context.Context, url string, jobID string) (string, error)
+	StopJobWithSavepoint(ctx context.Context, url string, jobID string, drain bool) (string, error)
 	ForceCancelJob(ctx context.Context, url string, jobID string) error
 	SubmitJob(ctx context.Context, url string, jarID string, submitJobRequest SubmitJobRequest) (*SubmitJobResponse, error)
 	CheckSavepointStatus(ctx context.Context, url string, jobID, triggerID string) (*SavepointResponse, error)
+	RescaleJob(ctx context.Context, url string, jobID string, parallelism int32) (string, error)
+	CheckRescaleStatus(ctx context.Context, url string, jobID string, triggerID string) (*SavepointResponse, error)
+	DisposeSavepoint(ctx context.Context, url string, savepointPath string) (string, error)
+	CheckSavepointDisposalStatus(ctx context.Context, url string, triggerID string) (*SavepointResponse, error)
 	GetJobs(ctx context.Context, url string) (*GetJobsResponse, error)
 	GetClusterOverview(ctx context.Context, url string) (*ClusterOverviewResponse, error)
 	GetLatestCheckpoint(ctx context.Context, url string, jobID string) (*CheckpointStatistics, error)
@@ -44,163 +71,441 @@ type FlinkAPIInterface interface {
 	GetTaskManagers(ctx context.Context, url string) (*TaskManagersResponse, error)
 	GetCheckpointCounts(ctx context.Context, url string, jobID string) (*CheckpointResponse, error)
 	GetJobOverview(ctx context.Context, url string, jobID string) (*FlinkJobOverview, error)
+	GetVertexBackpressure(ctx context.Context, url string, jobID string, vertexID string) (*VertexBackpressureResponse, error)
+	GetJobMetrics(ctx context.Context, url string, jobID string, query MetricsQuery) ([]Metric, error)
+	GetVertexMetrics(ctx context.Context, url string, jobID string, vertexID string, query MetricsQuery) ([]Metric, error)
+	GetTaskManagerMetrics(ctx context.Context, url string, taskManagerID string, query MetricsQuery) ([]Metric, error)
+	GetJobManagerMetrics(ctx context.Context, url string, metrics []string) ([]Metric, error)
+
+	// Returns a client sharing this client's metrics that connects using the given TLS and authentication settings
+	WithSecurity(security RestSecurity) FlinkAPIInterface
+
+	// Returns a client sharing this client's connection settings that uses the REST API of the given Flink version.
+	// Endpoints that are not available in that version fail without calling the JobManager.
+	WithVersion(version FlinkVersion) FlinkAPIInterface
+
+	// Discards the connection state kept for the JobManager at url, once its cluster has been deleted
+	ForgetJobManager(url string)
+}
+
+// Settings for connecting to a JobManager REST endpoint that is served over TLS or requires authentication
+type RestSecurity struct {
+	TLSConfig   *tls.Config
+	BearerToken string
+	Username    string
+	Password    string
 }
 
 type FlinkJobManagerClient struct {
 	client  *resty.Client
 	metrics *flinkJobManagerClientMetrics
+	version FlinkVersion
+
+	// Shared by all clients derived from this one with WithSecurity and WithVersion
+	read    requestPolicy
+	write   requestPolicy
+	breaker *circuitBreaker
+	limiter *rate.Limiter
+}
+
+// Timeout and retries for a class of requests. Reads are safe to retry, while writes (submitting, cancelling and
+// stopping jobs) are not idempotent and are only retried if they could not be sent to the JobManager at all.
+type requestPolicy struct {
+	timeout    time.Duration
+	retries    int
+	idempotent bool
+}
+
+func newRequestPolicy(timeout time.Duration, retries int, idempotent bool) requestPolicy {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return requestPolicy{
+		timeout:    timeout,
+		retries:    retries,
+		idempotent: idempotent,
+	}
+}
+
+// Returns true if the connection to the JobManager could not be established, in which case the request was never
+// sent and can safely be retried
+func isDialError(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial"
 }
 
 type flinkJobManagerClientMetrics struct {
-	scope                        promutils.Scope
-	submitJobSuccessCounter      labeled.Counter
-	submitJobFailureCounter      labeled.Counter
-	cancelJobSuccessCounter      labeled.Counter
-	cancelJobFailureCounter      labeled.Counter
-	forceCancelJobSuccessCounter labeled.Counter
-	forceCancelJobFailureCounter labeled.Counter
-	checkSavepointSuccessCounter labeled.Counter
-	checkSavepointFailureCounter labeled.Counter
-	getJobsSuccessCounter        labeled.Counter
-	getJobsFailureCounter        labeled.Counter
-	getJobConfigSuccessCounter   labeled.Counter
-	getJobConfigFailureCounter   labeled.Counter
-	getClusterSuccessCounter     labeled.Counter
-	getClusterFailureCounter     labeled.Counter
-	getCheckpointsSuccessCounter labeled.Counter
-	getCheckpointsFailureCounter labeled.Counter
+	scope                         promutils.Scope
+	submitJobSuccessCounter       labeled.Counter
+	submitJobFailureCounter       labeled.Counter
+	cancelJobSuccessCounter       labeled.Counter
+	cancelJobFailureCounter       labeled.Counter
+	stopJobSuccessCounter         labeled.Counter
+	stopJobFailureCounter         labeled.Counter
+	forceCancelJobSuccessCounter  labeled.Counter
+	forceCancelJobFailureCounter  labeled.Counter
+	checkSavepointSuccessCounter  labeled.Counter
+	checkSavepointFailureCounter  labeled.Counter
+	rescaleJobSuccessCounter      labeled.Counter
+	rescaleJobFailureCounter      labeled.Counter
+	getJobsSuccessCounter         labeled.Counter
+	getJobsFailureCounter         labeled.Counter
+	getJobConfigSuccessCounter    labeled.Counter
+	getJobConfigFailureCounter    labeled.Counter
+	getClusterSuccessCounter      labeled.Counter
+	getClusterFailureCounter      labeled.Counter
+	getCheckpointsSuccessCounter  labeled.Counter
+	getCheckpointsFailureCounter  labeled.Counter
+	getBackpressureSuccessCounter labeled.Counter
+	getBackpressureFailureCounter labeled.Counter
+	getMetricsSuccessCounter      labeled.Counter
+	getMetricsFailureCounter      labeled.Counter
+	circuitOpenCounter            labeled.Counter
+
+	disposeSavepointSuccessCounter       labeled.Counter
+	disposeSavepointFailureCounter       labeled.Counter
+	checkSavepointDisposalSuccessCounter labeled.Counter
+	checkSavepointDisposalFailureCounter labeled.Counter
 }
 
 func newFlinkJobManagerClientMetrics(scope promutils.Scope) *flinkJobManagerClientMetrics {
 	flinkJmClientScope := scope.NewSubScope("flink_jm_client")
 	return &flinkJobManagerClientMetrics{
-		scope:                        scope,
-		submitJobSuccessCounter:      labeled.NewCounter("submit_job_success", "Flink job submission successful", flinkJmClientScope),
-		submitJobFailureCounter:      labeled.NewCounter("submit_job_failure", "Flink job submission failed", flinkJmClientScope),
-		cancelJobSuccessCounter:      labeled.NewCounter("cancel_job_success", "Flink job cancellation successful", flinkJmClientScope),
-		cancelJobFailureCounter:      labeled.NewCounter("cancel_job_failure", "Flink job cancellation failed", flinkJmClientScope),
-		forceCancelJobSuccessCounter: labeled.NewCounter("force_cancel_job_success", "Flink forced job cancellation successful", flinkJmClientScope),
-		forceCancelJobFailureCounter: labeled.NewCounter("force_cancel_job_failure", "Flink forced job cancellation failed", flinkJmClientScope),
-		checkSavepointSuccessCounter: labeled.NewCounter("check_savepoint_status_success", "Flink check savepoint status successful", flinkJmClientScope),
-		checkSavepointFailureCounter: labeled.NewCounter("check_savepoint_status_failure", "Flink check savepoint status failed", flinkJmClientScope),
-		getJobsSuccessCounter:        labeled.NewCounter("get_jobs_success", "Get flink jobs succeeded", flinkJmClientScope),
-		getJobsFailureCounter:        labeled.NewCounter("get_jobs_failure", "Get flink jobs failed", flinkJmClientScope),
-		getJobConfigSuccessCounter:   labeled.NewCounter("get_job_config_success", "Get flink job config succeeded", flinkJmClientScope),
-		getJobConfigFailureCounter:   labeled.NewCounter("get_job_config_failure", "Get flink job config failed", flinkJmClientScope),
-		getClusterSuccessCounter:     labeled.NewCounter("get_cluster_success", "Get cluster overview succeeded", flinkJmClientScope),
-		getClusterFailureCounter:     labeled.NewCounter("get_cluster_failure", "Get cluster overview failed", flinkJmClientScope),
-		getCheckpointsSuccessCounter: labeled.NewCounter("get_checkpoints_success", "Get checkpoint request succeeded", flinkJmClientScope),
-		getCheckpointsFailureCounter: labeled.NewCounter("get_checkpoints_failed", "Get checkpoint request failed", flinkJmClientScope),
+		scope:                         scope,
+		submitJobSuccessCounter:       labeled.NewCounter("submit_job_success", "Flink job submission successful", flinkJmClientScope),
+		submitJobFailureCounter:       labeled.NewCounter("submit_job_failure", "Flink job submission failed", flinkJmClientScope),
+		cancelJobSuccessCounter:       labeled.NewCounter("cancel_job_success", "Flink job cancellation successful", flinkJmClientScope),
+		cancelJobFailureCounter:       labeled.NewCounter("cancel_job_failure", "Flink job cancellation failed", flinkJmClientScope),
+		stopJobSuccessCounter:         labeled.NewCounter("stop_job_success", "Flink job stop with savepoint successful", flinkJmClientScope),
+		stopJobFailureCounter:         labeled.NewCounter("stop_job_failure", "Flink job stop with savepoint failed", flinkJmClientScope),
+		forceCancelJobSuccessCounter:  labeled.NewCounter("force_cancel_job_success", "Flink forced job cancellation successful", flinkJmClientScope),
+		forceCancelJobFailureCounter:  labeled.NewCounter("force_cancel_job_failure", "Flink forced job cancellation failed", flinkJmClientScope),
+		checkSavepointSuccessCounter:  labeled.NewCounter("check_savepoint_status_success", "Flink check savepoint status successful", flinkJmClientScope),
+		checkSavepointFailureCounter:  labeled.NewCounter("check_savepoint_status_failure", "Flink check savepoint status failed", flinkJmClientScope),
+		rescaleJobSuccessCounter:      labeled.NewCounter("rescale_job_success", "Flink job rescaling triggered successfully", flinkJmClientScope),
+		rescaleJobFailureCounter:      labeled.NewCounter("rescale_job_failure", "Flink job rescaling failed to trigger", flinkJmClientScope),
+		getJobsSuccessCounter:         labeled.NewCounter("get_jobs_success", "Get flink jobs succeeded", flinkJmClientScope),
+		getJobsFailureCounter:         labeled.NewCounter("get_jobs_failure", "Get flink jobs failed", flinkJmClientScope),
+		getJobConfigSuccessCounter:    labeled.NewCounter("get_job_config_success", "Get flink job config succeeded", flinkJmClientScope),
+		getJobConfigFailureCounter:    labeled.NewCounter("get_job_config_failure", "Get flink job config failed", flinkJmClientScope),
+		getClusterSuccessCounter:      labeled.NewCounter("get_cluster_success", "Get cluster overview succeeded", flinkJmClientScope),
+		getClusterFailureCounter:      labeled.NewCounter("get_cluster_failure", "Get cluster overview failed", flinkJmClientScope),
+		getCheckpointsSuccessCounter:  labeled.NewCounter("get_checkpoints_success", "Get checkpoint request succeeded", flinkJmClientScope),
+		getCheckpointsFailureCounter:  labeled.NewCounter("get_checkpoints_failed", "Get checkpoint request failed", flinkJmClientScope),
+		getBackpressureSuccessCounter: labeled.NewCounter("get_backpressure_success", "Get vertex backpressure succeeded", flinkJmClientScope),
+		getBackpressureFailureCounter: labeled.NewCounter("get_backpressure_failure", "Get vertex backpressure failed", flinkJmClientScope),
+		getMetricsSuccessCounter:      labeled.NewCounter("get_metrics_success", "Get flink metrics succeeded", flinkJmClientScope),
+		getMetricsFailureCounter:      labeled.NewCounter("get_metrics_failure", "Get flink metrics failed", flinkJmClientScope),
+		circuitOpenCounter:            labeled.NewCounter("circuit_open", "Request rejected as the JobManager is unreachable", flinkJmClientScope),
+
+		disposeSavepointSuccessCounter:       labeled.NewCounter("dispose_savepoint_success", "Flink savepoint disposal triggered successfully", flinkJmClientScope),
+		disposeSavepointFailureCounter:       labeled.NewCounter("dispose_savepoint_failure", "Flink savepoint disposal failed to trigger", flinkJmClientScope),
+		checkSavepointDisposalSuccessCounter: labeled.NewCounter("check_savepoint_disposal_status_success", "Flink check savepoint disposal status successful", flinkJmClientScope),
+		checkSavepointDisposalFailureCounter: labeled.NewCounter("check_savepoint_disposal_status_failure", "Flink check savepoint disposal status failed", flinkJmClientScope),
 	}
 }
 
 func (c *FlinkJobManagerClient) GetJobConfig(ctx context.Context, url, jobID string) (*JobConfigResponse, error) {
 	path := fmt.Sprintf(getJobConfigURL, jobID)
-	url = url + path
 
-	response, err := c.executeRequest(httpGet, url, nil)
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
 	if err != nil {
 		c.metrics.getJobConfigFailureCounter.Inc(ctx)
-		return nil, errors.Wrap(err, "GetJobConfig API request failed")
+		return nil, getRequestError(err, "GetJobConfig API request failed")
 	}
 
 	if response != nil && !response.IsSuccess() {
 		c.metrics.getJobConfigFailureCounter.Inc(ctx)
 		logger.Errorf(ctx, fmt.Sprintf("Get Jobconfig failed with response %v", response))
-		return nil, errors.New(fmt.Sprintf("Get Jobconfig failed with status %v", response.Status()))
+		return nil, getResponseError(response, "Get Jobconfig failed with status %v", response.Status())
 	}
 	var jobConfigResponse JobConfigResponse
 	if err := json.Unmarshal(response.Body(), &jobConfigResponse); err != nil {
 		logger.Errorf(ctx, "Unable to Unmarshal jobPlanResponse %v, err: %v", response, err)
-		return nil, err
+		return nil, getParseError(err)
 	}
 	c.metrics.getJobConfigSuccessCounter.Inc(ctx)
 	return &jobConfigResponse, nil
 }
 
 func (c *FlinkJobManagerClient) GetClusterOverview(ctx context.Context, url string) (*ClusterOverviewResponse, error) {
-	url = url + getOverviewURL
-	response, err := c.executeRequest(httpGet, url, nil)
+	response, err := c.executeRequest(ctx, httpGet, url, getOverviewURL, nil)
 	if err != nil {
 		c.metrics.getClusterFailureCounter.Inc(ctx)
-		return nil, errors.Wrap(err, "GetClusterOverview API request failed")
+		return nil, getRequestError(err, "GetClusterOverview API request failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.getClusterFailureCounter.Inc(ctx)
 		if response.StatusCode() != int(http.StatusNotFound) || response.StatusCode() != int(http.StatusServiceUnavailable) {
 			logger.Errorf(ctx, fmt.Sprintf("Get cluster overview failed with response %v", response))
 		}
-		return nil, errors.New(fmt.Sprintf("Get cluster overview failed with status %v", response.Status()))
+		return nil, getResponseError(response, "Get cluster overview failed with status %v", response.Status())
 	}
 	var clusterOverviewResponse ClusterOverviewResponse
 	if err = json.Unmarshal(response.Body(), &clusterOverviewResponse); err != nil {
 		logger.Errorf(ctx, "Unable to Unmarshal clusterOverviewResponse %v, err: %v", response, err)
-		return nil, err
+		return nil, getParseError(err)
 	}
 	c.metrics.getClusterSuccessCounter.Inc(ctx)
 	return &clusterOverviewResponse, nil
 }
 
-// Helper method to execute the requests
-func (c *FlinkJobManagerClient) executeRequest(
-	method string, url string, payload interface{}) (*resty.Response, error) {
-	var resp *resty.Response
-	var err error
-	if method == httpGet {
-		resp, err = c.client.R().Get(url)
-	} else if method == httpPatch {
-		resp, err = c.client.R().Patch(url)
-	} else if method == httpPost {
-		resp, err = c.client.R().
+// Helper method to execute the requests. Each attempt is bound to ctx and to the timeout for the request's class,
+// and failed attempts are retried with backoff. Requests to a JobManager that has been unreachable fail fast.
+func (c *FlinkJobManagerClient) executeRequest(ctx context.Context,
+	method string, baseURL string, path string, payload interface{}) (resp *resty.Response, err error) {
+	url := baseURL + path
+	_, span := tracing.StartSpan(ctx, "FlinkJobManagerClient."+method,
+		tracing.Key("http.method").String(method),
+		tracing.Key("http.url").String(url))
+	defer func() {
+		if resp != nil {
+			span.SetAttributes(tracing.Key("http.status_code").Int(resp.StatusCode()))
+		}
+		tracing.EndSpan(span, err)
+	}()
+
+	var policy requestPolicy
+	switch method {
+	case httpGet:
+		policy = c.read
+	case httpPost, httpPatch:
+		policy = c.write
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid method %s in request", method))
+	}
+
+	if err = c.breaker.allow(baseURL); err != nil {
+		c.metrics.circuitOpenCounter.Inc(ctx)
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err = c.executeAttempt(ctx, method, url, payload, policy.timeout)
+		if err == nil || attempt >= policy.retries || ctx.Err() != nil {
+			break
+		}
+		if !policy.idempotent && !isDialError(err) {
+			break
+		}
+		logger.Debugf(ctx, "Request %s %s failed, retrying: %v", method, url, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(getRetryWait(attempt)):
+		}
+	}
+
+	switch {
+	case err == nil:
+		c.breaker.record(baseURL, true)
+	case ctx.Err() != nil:
+		c.breaker.release(baseURL)
+	default:
+		c.breaker.record(baseURL, false)
+	}
+	return resp, err
+}
+
+func (c *FlinkJobManagerClient) executeAttempt(ctx context.Context,
+	method string, url string, payload interface{}, timeout time.Duration) (*resty.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, errors.Wrap(err, "rate limit wait failed")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request := c.client.R().SetContext(ctx)
+	switch method {
+	case httpGet:
+		return request.Get(url)
+	case httpPatch:
+		return request.Patch(url)
+	default:
+		return request.
 			SetHeader("Content-Type", "application/json").
 			SetBody(payload).
 			Post(url)
-	} else {
-		return nil, errors.New(fmt.Sprintf("Invalid method %s in request", method))
 	}
-	return resp, err
+}
+
+// Requests that fail without a response are assumed to be caused by the JobManager being unavailable
+func getRequestError(err error, message string) error {
+	return flinkErrors.WrapErrorf(flinkErrors.JobManagerUnavailableError, err, "%s", message)
+}
+
+// Returns an error for an unsuccessful response, classified by its status and carrying the errors reported by Flink
+func getResponseError(response *resty.Response, msgFmt string, args ...interface{}) error {
+	return flinkErrors.FlinkResponseErrorf(response.StatusCode(), getFlinkErrors(response), msgFmt, args...)
+}
+
+// Flink reports errors as a list of messages in the body of the response. Requests that do not reach a Flink REST
+// handler (e.g. through a proxy) may not have one.
+func getFlinkErrors(response *resty.Response) []string {
+	var errorResponse ErrorResponse
+	if err := json.Unmarshal(response.Body(), &errorResponse); err != nil {
+		return nil
+	}
+	return errorResponse.Errors
+}
+
+// Job submissions that Flink rejects as bad requests (e.g. for a missing jar) or that fail while running the job's main
+// method are caused by the application rather than by Flink
+func getSubmissionError(response *resty.Response) error {
+	flinkErrs := getFlinkErrors(response)
+	code := flinkErrors.GetCodeForHTTPStatus(response.StatusCode())
+	if response.StatusCode() == http.StatusBadRequest {
+		code = flinkErrors.JobSubmissionError
+	}
+	for _, flinkErr := range flinkErrs {
+		if strings.Contains(flinkErr, programInvocationException) {
+			code = flinkErrors.JobSubmissionError
+		}
+	}
+
+	return &flinkErrors.FlinkOperatorError{
+		Code:        code,
+		Message:     fmt.Sprintf("Job submission failed with status %v\n%s", response.Status(), string(response.Body())),
+		HTTPStatus:  response.StatusCode(),
+		FlinkErrors: flinkErrs,
+	}
+}
+
+func getParseError(err error) error {
+	return flinkErrors.WrapErrorf(flinkErrors.InvalidResponseError, err, "Unable to parse response from the JobManager")
+}
+
+// Exponential backoff between attempts, capped at maxRetryWait
+func getRetryWait(attempt int) time.Duration {
+	wait := minRetryWait << uint(attempt)
+	if wait <= 0 || wait > maxRetryWait {
+		return maxRetryWait
+	}
+	return wait
 }
 
 func (c *FlinkJobManagerClient) CancelJobWithSavepoint(ctx context.Context, url string, jobID string) (string, error) {
 	path := fmt.Sprintf(savepointURL, jobID)
 
-	url = url + path
 	cancelJobRequest := CancelJobRequest{
 		CancelJob: true,
 	}
-	response, err := c.executeRequest(httpPost, url, cancelJobRequest)
+	response, err := c.executeRequest(ctx, httpPost, url, path, cancelJobRequest)
 	if err != nil {
 		c.metrics.cancelJobFailureCounter.Inc(ctx)
-		return "", errors.Wrap(err, "Cancel job API request failed")
+		return "", getRequestError(err, "Cancel job API request failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.cancelJobFailureCounter.Inc(ctx)
 		logger.Errorf(ctx, fmt.Sprintf("Cancel job failed with response %v", response))
-		return "", errors.New(fmt.Sprintf("Cancel job failed with status %v", response.Status()))
+		return "", getResponseError(response, "Cancel job failed with status %v", response.Status())
 	}
 	var cancelJobResponse CancelJobResponse
 	if err = json.Unmarshal(response.Body(), &cancelJobResponse); err != nil {
 		logger.Errorf(ctx, "Unable to Unmarshal cancelJobResponse %v, err: %v", response, err)
-		return "", err
+		return "", getParseError(err)
 	}
 	c.metrics.cancelJobSuccessCounter.Inc(ctx)
 	return cancelJobResponse.TriggerID, nil
 }
 
-func (c *FlinkJobManagerClient) ForceCancelJob(ctx context.Context, url string, jobID string) error {
-	path := fmt.Sprintf(jobURL, jobID)
+// Stops the job after taking a savepoint, which unlike cancellation guarantees that no checkpoints are taken after the
+// savepoint. When drain is set, sources emit a final watermark first so that all event time timers fire. Returns a
+// trigger ID that can be polled with CheckSavepointStatus. Requires Flink 1.9 or later.
+func (c *FlinkJobManagerClient) StopJobWithSavepoint(ctx context.Context, url string, jobID string, drain bool) (string, error) {
+	if !c.version.AtLeast(StopWithSavepointVersion) {
+		c.metrics.stopJobFailureCounter.Inc(ctx)
+		return "", flinkErrors.Errorf(flinkErrors.UnsupportedOperationError, "stop with savepoint requires flink %s or later", StopWithSavepointVersion)
+	}
+
+	path := fmt.Sprintf(stopURL, jobID)
+	stopJobRequest := StopJobRequest{
+		Drain: drain,
+	}
+	response, err := c.executeRequest(ctx, httpPost, url, path, stopJobRequest)
+	if err != nil {
+		c.metrics.stopJobFailureCounter.Inc(ctx)
+		return "", getRequestError(err, "Stop job API request failed")
+	}
+	if response != nil && !response.IsSuccess() {
+		c.metrics.stopJobFailureCounter.Inc(ctx)
+		logger.Errorf(ctx, fmt.Sprintf("Stop job failed with response %v", response))
+		return "", getResponseError(response, "Stop job failed with status %v", response.Status())
+	}
+	var stopJobResponse CancelJobResponse
+	if err = json.Unmarshal(response.Body(), &stopJobResponse); err != nil {
+		logger.Errorf(ctx, "Unable to Unmarshal stopJobResponse %v, err: %v", response, err)
+		return "", getParseError(err)
+	}
+	c.metrics.stopJobSuccessCounter.Inc(ctx)
+	return stopJobResponse.TriggerID, nil
+}
+
+// Triggers the asynchronous rescaling of a running job to the given parallelism, returning a trigger ID that can be
+// polled with CheckRescaleStatus. Flink rescales the job by taking a savepoint and restarting it from the savepoint in
+// the same cluster, so the cluster must have enough free slots. Not supported from Flink 1.9.
+func (c *FlinkJobManagerClient) RescaleJob(ctx context.Context, url string, jobID string, parallelism int32) (string, error) {
+	if !c.version.SupportsRescaling() {
+		c.metrics.rescaleJobFailureCounter.Inc(ctx)
+		return "", flinkErrors.Errorf(flinkErrors.UnsupportedOperationError, "rescaling is not supported from flink %s", RescalingDisabledVersion)
+	}
+
+	path := fmt.Sprintf(rescalingURL, jobID, parallelism)
+	response, err := c.executeRequest(ctx, httpPatch, url, path, nil)
+	if err != nil {
+		c.metrics.rescaleJobFailureCounter.Inc(ctx)
+		return "", getRequestError(err, "Rescale job API request failed")
+	}
+	if response != nil && !response.IsSuccess() {
+		c.metrics.rescaleJobFailureCounter.Inc(ctx)
+		logger.Errorf(ctx, fmt.Sprintf("Rescale job failed with response %v", response))
+		return "", getResponseError(response, "Rescale job failed with status %v", response.Status())
+	}
+	var rescaleResponse CancelJobResponse
+	if err = json.Unmarshal(response.Body(), &rescaleResponse); err != nil {
+		logger.Errorf(ctx, "Unable to Unmarshal rescaleResponse %v, err: %v", response, err)
+		return "", getParseError(err)
+	}
+	c.metrics.rescaleJobSuccessCounter.Inc(ctx)
+	return rescaleResponse.TriggerID, nil
+}
 
-	url = url + path + "?mode=cancel"
+func (c *FlinkJobManagerClient) CheckRescaleStatus(ctx context.Context, url string, jobID string, triggerID string) (*SavepointResponse, error) {
+	path := fmt.Sprintf(checkRescalingStatusURL, jobID, triggerID)
 
-	response, err := c.executeRequest(httpPatch, url, nil)
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
+	if err != nil {
+		return nil, getRequestError(err, "Check rescale status API request failed")
+	}
+	if response != nil && !response.IsSuccess() {
+		logger.Errorf(ctx, fmt.Sprintf("Check rescale status failed with response %v", response))
+		return nil, getResponseError(response, "Check rescale status failed with status %v", response.Status())
+	}
+	var rescaleResponse SavepointResponse
+	if err = json.Unmarshal(response.Body(), &rescaleResponse); err != nil {
+		logger.Errorf(ctx, "Unable to Unmarshal rescaleResponse %v, err: %v", response, err)
+		return nil, getParseError(err)
+	}
+	return &rescaleResponse, nil
+}
+
+func (c *FlinkJobManagerClient) ForceCancelJob(ctx context.Context, url string, jobID string) error {
+	path := fmt.Sprintf(jobURL, jobID) + "?mode=cancel"
+
+	response, err := c.executeRequest(ctx, httpPatch, url, path, nil)
 	if err != nil {
 		c.metrics.forceCancelJobFailureCounter.Inc(ctx)
-		return errors.Wrap(err, "Force cancel job API request failed")
+		return getRequestError(err, "Force cancel job API request failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.forceCancelJobFailureCounter.Inc(ctx)
 		logger.Errorf(ctx, fmt.Sprintf("Force cancel job failed with response %v", response))
-		return errors.New(fmt.Sprintf("Force cancel job failed with status %v", response.Status()))
+		return getResponseError(response, "Force cancel job failed with status %v", response.Status())
 	}
 
 	c.metrics.forceCancelJobFailureCounter.Inc(ctx)
@@ -209,23 +514,21 @@ func (c *FlinkJobManagerClient) ForceCancelJob(ctx context.Context, url string,
 
 func (c *FlinkJobManagerClient) SubmitJob(ctx context.Context, url string, jarID string, submitJobRequest SubmitJobRequest) (*SubmitJobResponse, error) {
 	path := fmt.Sprintf(submitJobURL, jarID)
-	url = url + path
 
-	response, err := c.executeRequest(httpPost, url, submitJobRequest)
+	response, err := c.executeRequest(ctx, httpPost, url, path, submitJobRequest)
 	if err != nil {
 		c.metrics.submitJobFailureCounter.Inc(ctx)
-		return nil, errors.Wrap(err, "Submit job API request failed")
+		return nil, getRequestError(err, "Submit job API request failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.submitJobFailureCounter.Inc(ctx)
 		logger.Warnf(ctx, fmt.Sprintf("Job submission failed with response %v", response))
-		return nil, errors.New(fmt.Sprintf("Job submission failed with status %v\n%s",
-			response.Status(), string(response.Body())))
+		return nil, getSubmissionError(response)
 	}
 	var submitJobResponse SubmitJobResponse
 	if err = json.Unmarshal(response.Body(), &submitJobResponse); err != nil {
 		logger.Errorf(ctx, "Unable to Unmarshal submitJobResponse %v, err: %v", response, err)
-		return nil, err
+		return nil, getParseError(err)
 	}
 
 	c.metrics.submitJobSuccessCounter.Inc(ctx)
@@ -234,59 +537,104 @@ func (c *FlinkJobManagerClient) SubmitJob(ctx context.Context, url string, jarID
 
 func (c *FlinkJobManagerClient) CheckSavepointStatus(ctx context.Context, url string, jobID, triggerID string) (*SavepointResponse, error) {
 	path := fmt.Sprintf(checkSavepointStatusURL, jobID, triggerID)
-	url = url + path
 
-	response, err := c.executeRequest(httpGet, url, nil)
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
 	if err != nil {
 		c.metrics.checkSavepointFailureCounter.Inc(ctx)
-		return nil, errors.Wrap(err, "Check savepoint status API request failed")
+		return nil, getRequestError(err, "Check savepoint status API request failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.checkSavepointFailureCounter.Inc(ctx)
 		logger.Errorf(ctx, fmt.Sprintf("Check savepoint status failed with response %v", response))
-		return nil, errors.New(fmt.Sprintf("Check savepoint status failed with status %v", response.Status()))
+		return nil, getResponseError(response, "Check savepoint status failed with status %v", response.Status())
 	}
 	var savepointResponse SavepointResponse
 	if err = json.Unmarshal(response.Body(), &savepointResponse); err != nil {
 		logger.Errorf(ctx, "Unable to Unmarshal savepointResponse %v, err: %v", response, err)
-		return nil, err
+		return nil, getParseError(err)
 	}
 	c.metrics.cancelJobSuccessCounter.Inc(ctx)
 	return &savepointResponse, nil
 }
 
+// Triggers the asynchronous disposal of a savepoint, returning a trigger ID that can be polled with
+// CheckSavepointDisposalStatus
+func (c *FlinkJobManagerClient) DisposeSavepoint(ctx context.Context, url string, savepointPath string) (string, error) {
+	disposalRequest := SavepointDisposalRequest{
+		SavepointPath: savepointPath,
+	}
+	response, err := c.executeRequest(ctx, httpPost, url, savepointDisposalURL, disposalRequest)
+	if err != nil {
+		c.metrics.disposeSavepointFailureCounter.Inc(ctx)
+		return "", getRequestError(err, "Dispose savepoint API request failed")
+	}
+	if response != nil && !response.IsSuccess() {
+		c.metrics.disposeSavepointFailureCounter.Inc(ctx)
+		logger.Errorf(ctx, fmt.Sprintf("Dispose savepoint failed with response %v", response))
+		return "", getResponseError(response, "Dispose savepoint failed with status %v", response.Status())
+	}
+	var disposalResponse CancelJobResponse
+	if err = json.Unmarshal(response.Body(), &disposalResponse); err != nil {
+		logger.Errorf(ctx, "Unable to Unmarshal disposalResponse %v, err: %v", response, err)
+		return "", getParseError(err)
+	}
+	c.metrics.disposeSavepointSuccessCounter.Inc(ctx)
+	return disposalResponse.TriggerID, nil
+}
+
+func (c *FlinkJobManagerClient) CheckSavepointDisposalStatus(ctx context.Context, url string, triggerID string) (*SavepointResponse, error) {
+	path := fmt.Sprintf(savepointDisposalStatusURL, triggerID)
+
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
+	if err != nil {
+		c.metrics.checkSavepointDisposalFailureCounter.Inc(ctx)
+		return nil, getRequestError(err, "Check savepoint disposal status API request failed")
+	}
+	if response != nil && !response.IsSuccess() {
+		c.metrics.checkSavepointDisposalFailureCounter.Inc(ctx)
+		logger.Errorf(ctx, fmt.Sprintf("Check savepoint disposal status failed with response %v", response))
+		return nil, getResponseError(response, "Check savepoint disposal status failed with status %v", response.Status())
+	}
+	var disposalResponse SavepointResponse
+	if err = json.Unmarshal(response.Body(), &disposalResponse); err != nil {
+		logger.Errorf(ctx, "Unable to Unmarshal disposalResponse %v, err: %v", response, err)
+		return nil, getParseError(err)
+	}
+	c.metrics.checkSavepointDisposalSuccessCounter.Inc(ctx)
+	return &disposalResponse, nil
+}
+
 func (c *FlinkJobManagerClient) GetJobs(ctx context.Context, url string) (*GetJobsResponse, error) {
-	url = url + getJobsURL
-	response, err := c.executeRequest(httpGet, url, nil)
+	response, err := c.executeRequest(ctx, httpGet, url, getJobsURL, nil)
 	if err != nil {
 		c.metrics.getJobsFailureCounter.Inc(ctx)
-		return nil, errors.Wrap(err, "Get jobs API request failed")
+		return nil, getRequestError(err, "Get jobs API request failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.getJobsFailureCounter.Inc(ctx)
 		logger.Errorf(ctx, fmt.Sprintf("GetJobs failed with response %v", response))
-		return nil, errors.New(fmt.Sprintf("GetJobs request failed with status %v", response.Status()))
+		return nil, getResponseError(response, "GetJobs request failed with status %v", response.Status())
 	}
 	var getJobsResponse GetJobsResponse
 	if err = json.Unmarshal(response.Body(), &getJobsResponse); err != nil {
 		logger.Errorf(ctx, "%v", getJobsResponse)
 		logger.Errorf(ctx, "Unable to Unmarshal getJobsResponse %v, err: %v", response, err)
-		return nil, err
+		return nil, getParseError(err)
 	}
 	c.metrics.getJobsSuccessCounter.Inc(ctx)
 	return &getJobsResponse, nil
 }
 
 func (c *FlinkJobManagerClient) GetLatestCheckpoint(ctx context.Context, url string, jobID string) (*CheckpointStatistics, error) {
-	endpoint := fmt.Sprintf(url+checkpointsURL, jobID)
-	response, err := c.executeRequest(httpGet, endpoint, nil)
+	path := fmt.Sprintf(checkpointsURL, jobID)
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
 	if err != nil {
 		c.metrics.getCheckpointsFailureCounter.Inc(ctx)
-		return nil, errors.Wrap(err, "get checkpoints failed")
+		return nil, getRequestError(err, "get checkpoints failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.getCheckpointsFailureCounter.Inc(ctx)
-		return nil, errors.New(fmt.Sprintf("get checkpoints failed with response %v", response))
+		return nil, getResponseError(response, "get checkpoints failed with response %v", response)
 	}
 
 	var checkpointResponse CheckpointResponse
@@ -299,14 +647,13 @@ func (c *FlinkJobManagerClient) GetLatestCheckpoint(ctx context.Context, url str
 }
 
 func (c *FlinkJobManagerClient) GetTaskManagers(ctx context.Context, url string) (*TaskManagersResponse, error) {
-	endpoint := url + taskmanagersURL
-	response, err := c.executeRequest(httpGet, endpoint, nil)
+	response, err := c.executeRequest(ctx, httpGet, url, taskmanagersURL, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "get taskmanagers failed")
+		return nil, getRequestError(err, "get taskmanagers failed")
 	}
 
 	if response != nil && !response.IsSuccess() {
-		return nil, errors.New(fmt.Sprintf("get taskmanagers failed with response %v", response))
+		return nil, getResponseError(response, "get taskmanagers failed with response %v", response)
 	}
 
 	var taskmanagerResponse TaskManagersResponse
@@ -319,15 +666,15 @@ func (c *FlinkJobManagerClient) GetTaskManagers(ctx context.Context, url string)
 }
 
 func (c *FlinkJobManagerClient) GetCheckpointCounts(ctx context.Context, url string, jobID string) (*CheckpointResponse, error) {
-	endpoint := fmt.Sprintf(url+checkpointsURL, jobID)
-	response, err := c.executeRequest(httpGet, endpoint, nil)
+	path := fmt.Sprintf(checkpointsURL, jobID)
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
 	if err != nil {
 		c.metrics.getCheckpointsFailureCounter.Inc(ctx)
-		return nil, errors.Wrap(err, "get checkpoints failed")
+		return nil, getRequestError(err, "get checkpoints failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.getCheckpointsFailureCounter.Inc(ctx)
-		return nil, errors.New(fmt.Sprintf("get checkpoints failed with response %v", response))
+		return nil, getResponseError(response, "get checkpoints failed with response %v", response)
 	}
 
 	var checkpointResponse CheckpointResponse
@@ -340,14 +687,14 @@ func (c *FlinkJobManagerClient) GetCheckpointCounts(ctx context.Context, url str
 }
 
 func (c *FlinkJobManagerClient) GetJobOverview(ctx context.Context, url string, jobID string) (*FlinkJobOverview, error) {
-	endpoint := fmt.Sprintf(url+getJobsOverviewURL, jobID)
-	response, err := c.executeRequest(httpGet, endpoint, nil)
+	path := fmt.Sprintf(getJobsOverviewURL, jobID)
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "get job overview failed")
+		return nil, getRequestError(err, "get job overview failed")
 	}
 	if response != nil && !response.IsSuccess() {
 		c.metrics.getCheckpointsFailureCounter.Inc(ctx)
-		return nil, errors.New(fmt.Sprintf("get job overview failed with response %v", response))
+		return nil, getResponseError(response, "get job overview failed with response %v", response)
 	}
 
 	var jobOverviewResponse FlinkJobOverview
@@ -358,11 +705,153 @@ func (c *FlinkJobManagerClient) GetJobOverview(ctx context.Context, url string,
 	return &jobOverviewResponse, nil
 }
 
-func NewFlinkJobManagerClient(config config.RuntimeConfig) FlinkAPIInterface {
-	client := resty.SetRetryCount(retryCount).SetTimeout(timeOut)
-	metrics := newFlinkJobManagerClientMetrics(config.MetricsScope)
+func (c *FlinkJobManagerClient) GetVertexBackpressure(ctx context.Context, url string, jobID string, vertexID string) (*VertexBackpressureResponse, error) {
+	path := fmt.Sprintf(vertexBackpressureURL, jobID, vertexID)
+	response, err := c.executeRequest(ctx, httpGet, url, path, nil)
+	if err != nil {
+		c.metrics.getBackpressureFailureCounter.Inc(ctx)
+		return nil, getRequestError(err, "get vertex backpressure failed")
+	}
+	if response != nil && !response.IsSuccess() {
+		c.metrics.getBackpressureFailureCounter.Inc(ctx)
+		return nil, getResponseError(response, "get vertex backpressure failed with response %v", response)
+	}
+
+	var backpressureResponse VertexBackpressureResponse
+	if err = json.Unmarshal(response.Body(), &backpressureResponse); err != nil {
+		logger.Errorf(ctx, "Failed to unmarshal VertexBackpressureResponse %v, err %v", response, err)
+		return nil, getParseError(err)
+	}
+
+	c.metrics.getBackpressureSuccessCounter.Inc(ctx)
+	return &backpressureResponse, nil
+}
+
+// Returns the job's metrics. As Flink only aggregates job metrics across jobs, queries with aggregations are sent to
+// the aggregated job metrics endpoint restricted to this job.
+func (c *FlinkJobManagerClient) GetJobMetrics(ctx context.Context, url string, jobID string, query MetricsQuery) ([]Metric, error) {
+	path := fmt.Sprintf(jobMetricsURL, jobID)
+	params := getMetricsQueryParams(query)
+	if len(query.Aggregations) > 0 {
+		path = aggregatedJobMetricsURL
+		params.Set("jobs", jobID)
+	}
+	return c.getMetrics(ctx, url, path, params)
+}
+
+// Returns the metrics of a job vertex. Queries with aggregations return the metrics aggregated across the vertex's
+// subtasks, which unlike the vertex metrics are not prefixed with the subtask index.
+func (c *FlinkJobManagerClient) GetVertexMetrics(ctx context.Context, url string, jobID string, vertexID string,
+	query MetricsQuery) ([]Metric, error) {
+	path := fmt.Sprintf(vertexMetricsURL, jobID, vertexID)
+	if len(query.Aggregations) > 0 {
+		path = fmt.Sprintf(subtaskMetricsURL, jobID, vertexID)
+	}
+	return c.getMetrics(ctx, url, path, getMetricsQueryParams(query))
+}
+
+// Returns the metrics of a taskmanager. If taskManagerID is empty, or the query has aggregations, the metrics are
+// aggregated across all taskmanagers, or the given one.
+func (c *FlinkJobManagerClient) GetTaskManagerMetrics(ctx context.Context, url string, taskManagerID string,
+	query MetricsQuery) ([]Metric, error) {
+	params := getMetricsQueryParams(query)
+	if taskManagerID != "" && len(query.Aggregations) == 0 {
+		return c.getMetrics(ctx, url, fmt.Sprintf(taskManagerMetricsURL, taskManagerID), params)
+	}
+	if taskManagerID != "" {
+		params.Set("taskmanagers", taskManagerID)
+	}
+	return c.getMetrics(ctx, url, aggregatedTaskManagerMetricsURL, params)
+}
+
+func (c *FlinkJobManagerClient) GetJobManagerMetrics(ctx context.Context, url string, metrics []string) ([]Metric, error) {
+	return c.getMetrics(ctx, url, jobManagerMetricsURL, getMetricsQueryParams(MetricsQuery{Metrics: metrics}))
+}
+
+func (c *FlinkJobManagerClient) getMetrics(ctx context.Context, baseURL string, path string,
+	params url.Values) ([]Metric, error) {
+	if len(params) > 0 {
+		path = path + "?" + params.Encode()
+	}
+	response, err := c.executeRequest(ctx, httpGet, baseURL, path, nil)
+	if err != nil {
+		c.metrics.getMetricsFailureCounter.Inc(ctx)
+		return nil, getRequestError(err, "get metrics failed")
+	}
+	if response != nil && !response.IsSuccess() {
+		c.metrics.getMetricsFailureCounter.Inc(ctx)
+		return nil, getResponseError(response, "get metrics failed with response %v", response)
+	}
+
+	var metrics []Metric
+	if err = json.Unmarshal(response.Body(), &metrics); err != nil {
+		logger.Errorf(ctx, "Failed to unmarshal metrics response %v, err %v", response, err)
+		return nil, getParseError(err)
+	}
+
+	c.metrics.getMetricsSuccessCounter.Inc(ctx)
+	return metrics, nil
+}
+
+func getMetricsQueryParams(query MetricsQuery) url.Values {
+	params := url.Values{}
+	if len(query.Metrics) > 0 {
+		params.Set("get", strings.Join(query.Metrics, ","))
+	}
+	if len(query.Aggregations) > 0 {
+		aggregations := make([]string, len(query.Aggregations))
+		for i, aggregation := range query.Aggregations {
+			aggregations[i] = string(aggregation)
+		}
+		params.Set("agg", strings.Join(aggregations, ","))
+	}
+	return params
+}
+
+func (c *FlinkJobManagerClient) WithSecurity(security RestSecurity) FlinkAPIInterface {
+	client := resty.New()
+	if security.TLSConfig != nil {
+		client.SetTLSClientConfig(security.TLSConfig)
+	}
+	if security.BearerToken != "" {
+		client.SetAuthToken(security.BearerToken)
+	} else if security.Username != "" {
+		client.SetBasicAuth(security.Username, security.Password)
+	}
+	secureClient := *c
+	secureClient.client = client
+	return &secureClient
+}
+
+func (c *FlinkJobManagerClient) WithVersion(version FlinkVersion) FlinkAPIInterface {
+	versionedClient := *c
+	versionedClient.version = version
+	return &versionedClient
+}
+
+func (c *FlinkJobManagerClient) ForgetJobManager(url string) {
+	c.breaker.forget(url)
+}
+
+func NewFlinkJobManagerClient(runtimeConfig config.RuntimeConfig) FlinkAPIInterface {
+	clientConfig := config.GetConfig().FlinkClient
+	metrics := newFlinkJobManagerClientMetrics(runtimeConfig.MetricsScope)
+
+	var limiter *rate.Limiter
+	if clientConfig.RateLimitQPS > 0 {
+		burst := clientConfig.RateLimitBurst
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(clientConfig.RateLimitQPS), burst)
+	}
+
 	return &FlinkJobManagerClient{
-		client:  client,
+		client:  resty.New(),
 		metrics: metrics,
+		read:    newRequestPolicy(clientConfig.ReadTimeout.Duration, clientConfig.ReadRetries, true),
+		write:   newRequestPolicy(clientConfig.WriteTimeout.Duration, clientConfig.WriteRetries, false),
+		breaker: newCircuitBreaker(clientConfig.CircuitBreakerThreshold, clientConfig.CircuitBreakerResetTimeout.Duration),
+		limiter: limiter,
 	}
 }
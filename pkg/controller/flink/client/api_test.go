@@ -2,18 +2,25 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"net"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty"
 	"github.com/jarcoal/httpmock"
 	mockScope "github.com/lyft/flytestdlib/promutils"
 	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 
 	"strings"
 
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
 	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
 )
 
 const testURL = "http://abc.com"
@@ -24,12 +31,25 @@ const fakeJobConfigURL = "http://abc.com/jobs/1/config"
 const fakeSavepointURL = "http://abc.com/jobs/1/savepoints/2"
 const fakeSubmitURL = "http://abc.com/jars/1/run"
 const fakeCancelURL = "http://abc.com/jobs/1/savepoints"
+const fakeStopURL = "http://abc.com/jobs/1/stop"
 const fakeTaskmanagersURL = "http://abc.com/taskmanagers"
+const fakeJobOverviewURL = "http://abc.com/jobs/1"
+const fakeBackpressureURL = "http://abc.com/jobs/1/vertices/v1/backpressure"
+const fakeRescalingURL = "http://abc.com/jobs/1/rescaling?parallelism=4"
+const fakeRescalingStatusURL = "http://abc.com/jobs/1/rescaling/2"
+const fakeSavepointDisposalURL = "http://abc.com/savepoint-disposal"
+const fakeSavepointDisposalStatusURL = "http://abc.com/savepoint-disposal/2"
+const fakeJobMetricsURL = "http://abc.com/jobs/1/metrics?get=uptime%2CnumRestarts"
+const fakeAggregatedJobMetricsURL = "http://abc.com/jobs/metrics?agg=max&get=lastCheckpointSize&jobs=1"
+const fakeSubtaskMetricsURL = "http://abc.com/jobs/1/vertices/v1/subtasks/metrics?agg=sum%2Cmax&get=numRecordsIn"
+const fakeTaskManagerMetricsURL = "http://abc.com/taskmanagers/tm1/metrics?get=Status.JVM.CPU.Load"
+const fakeAggregatedTaskManagerMetricsURL = "http://abc.com/taskmanagers/metrics?agg=avg&get=Status.JVM.CPU.Load"
+const fakeJobManagerMetricsURL = "http://abc.com/jobmanager/metrics?get=numRegisteredTaskManagers"
 
 func getTestClient() FlinkJobManagerClient {
-	client := resty.SetRetryCount(1)
 	return FlinkJobManagerClient{
-		client: client,
+		client:  resty.New(),
+		breaker: newCircuitBreaker(0, 0),
 	}
 }
 
@@ -83,7 +103,7 @@ func TestGetJobs500Response(t *testing.T) {
 	client := getTestJobManagerClient()
 	resp, err := client.GetJobs(ctx, testURL)
 	assert.Nil(t, resp)
-	assert.EqualError(t, err, "GetJobs request failed with status 500")
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [GetJobs request failed with status 500]")
 }
 
 func TestGetJobsError(t *testing.T) {
@@ -96,7 +116,7 @@ func TestGetJobsError(t *testing.T) {
 	resp, err := client.GetJobs(ctx, testURL)
 	assert.Nil(t, resp)
 	assert.NotNil(t, err)
-	assert.True(t, strings.HasPrefix(err.Error(), "Get jobs API request failed"))
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [JobManagerUnavailableError] Reason: [Get jobs API request failed"))
 }
 
 func TestGetJobsFlinkJobUnmarshal(t *testing.T) {
@@ -154,7 +174,7 @@ func TestGetCluster500Response(t *testing.T) {
 	client := getTestJobManagerClient()
 	resp, err := client.GetClusterOverview(ctx, testURL)
 	assert.Nil(t, resp)
-	assert.EqualError(t, err, "Get cluster overview failed with status 500")
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [Get cluster overview failed with status 500]")
 }
 
 func TestGetCluster503Response(t *testing.T) {
@@ -167,7 +187,7 @@ func TestGetCluster503Response(t *testing.T) {
 	client := getTestJobManagerClient()
 	resp, err := client.GetClusterOverview(ctx, testURL)
 	assert.Nil(t, resp)
-	assert.EqualError(t, err, "Get cluster overview failed with status 503")
+	assert.EqualError(t, err, "ErrorCode: [JobManagerUnavailableError] Reason: [Get cluster overview failed with status 503]")
 }
 
 func TestGetClusterOverviewError(t *testing.T) {
@@ -180,7 +200,7 @@ func TestGetClusterOverviewError(t *testing.T) {
 	resp, err := client.GetClusterOverview(ctx, testURL)
 	assert.Nil(t, resp)
 	assert.NotNil(t, err)
-	assert.True(t, strings.HasPrefix(err.Error(), "GetClusterOverview API request failed"))
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [JobManagerUnavailableError] Reason: [GetClusterOverview API request failed"))
 }
 
 func TestGetJobConfigHappyCase(t *testing.T) {
@@ -222,7 +242,7 @@ func TestGetJobConfig500Response(t *testing.T) {
 	client := getTestJobManagerClient()
 	resp, err := client.GetJobConfig(ctx, testURL, "1")
 	assert.Nil(t, resp)
-	assert.EqualError(t, err, "Get Jobconfig failed with status 500")
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [Get Jobconfig failed with status 500]")
 }
 
 func TestGetJobConfigError(t *testing.T) {
@@ -235,7 +255,7 @@ func TestGetJobConfigError(t *testing.T) {
 	resp, err := client.GetJobConfig(ctx, testURL, "1")
 	assert.Nil(t, resp)
 	assert.NotNil(t, err)
-	assert.True(t, strings.HasPrefix(err.Error(), "GetJobConfig API request failed"))
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [JobManagerUnavailableError] Reason: [GetJobConfig API request failed"))
 }
 
 func TestCheckSavepointHappyCase(t *testing.T) {
@@ -279,7 +299,7 @@ func TestCheckSavepoint500Response(t *testing.T) {
 	client := getTestJobManagerClient()
 	resp, err := client.CheckSavepointStatus(ctx, testURL, "1", "2")
 	assert.Nil(t, resp)
-	assert.EqualError(t, err, "Check savepoint status failed with status 500")
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [Check savepoint status failed with status 500]")
 }
 
 func TestCheckSavepointError(t *testing.T) {
@@ -292,7 +312,7 @@ func TestCheckSavepointError(t *testing.T) {
 	resp, err := client.CheckSavepointStatus(ctx, testURL, "1", "2")
 	assert.Nil(t, resp)
 	assert.NotNil(t, err)
-	assert.True(t, strings.HasPrefix(err.Error(), "Check savepoint status API request failed"))
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [JobManagerUnavailableError] Reason: [Check savepoint status API request failed"))
 }
 
 func TestSubmitJobHappyCase(t *testing.T) {
@@ -340,7 +360,7 @@ func TestSubmitJob500Response(t *testing.T) {
 		Parallelism: 10,
 	})
 	assert.Nil(t, resp)
-	assert.EqualError(t, err, "Job submission failed with status 500\ncould not submit")
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [Job submission failed with status 500\ncould not submit]")
 }
 
 func TestSubmitJobError(t *testing.T) {
@@ -355,7 +375,25 @@ func TestSubmitJobError(t *testing.T) {
 	})
 	assert.Nil(t, resp)
 	assert.NotNil(t, err)
-	assert.True(t, strings.HasPrefix(err.Error(), "Submit job API request failed"))
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [JobManagerUnavailableError] Reason: [Submit job API request failed"))
+}
+
+func TestSubmitJobProgramInvocationError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder := httpmock.NewStringResponder(500,
+		`{"errors":["org.apache.flink.client.program.ProgramInvocationException: The main method caused an error."]}`)
+	httpmock.RegisterResponder("POST", fakeSubmitURL, responder)
+
+	client := getTestJobManagerClient()
+	_, err := client.SubmitJob(ctx, testURL, "1", SubmitJobRequest{
+		Parallelism: 10,
+	})
+	code, _ := flinkErrors.GetErrorCode(err)
+	assert.Equal(t, flinkErrors.JobSubmissionError, code)
+	assert.True(t, flinkErrors.IsUserError(err))
+	assert.Equal(t, 500, flinkErrors.GetHTTPStatus(err))
 }
 
 func TestCancelJobHappyCase(t *testing.T) {
@@ -374,6 +412,159 @@ func TestCancelJobHappyCase(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestStopJobWithSavepoint(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	response := CancelJobResponse{
+		TriggerID: "133",
+	}
+	httpmock.RegisterResponder("POST", fakeStopURL, func(req *http.Request) (*http.Response, error) {
+		var request StopJobRequest
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		assert.True(t, request.Drain)
+		return httpmock.NewJsonResponse(202, response)
+	})
+
+	client := getTestJobManagerClient().WithVersion(FlinkVersion{Major: 1, Minor: 9})
+	resp, err := client.StopJobWithSavepoint(ctx, testURL, "1", true)
+	assert.Equal(t, response.TriggerID, resp)
+	assert.NoError(t, err)
+}
+
+func TestStopJobWithSavepointUnsupportedVersion(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+
+	client := getTestJobManagerClient().WithVersion(FlinkVersion{Major: 1, Minor: 8})
+	resp, err := client.StopJobWithSavepoint(ctx, testURL, "1", false)
+	assert.Empty(t, resp)
+	assert.EqualError(t, err, "ErrorCode: [UnsupportedOperationError] Reason: [stop with savepoint requires flink 1.9 or later]")
+}
+
+func TestRescaleJobHappyCase(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	response := CancelJobResponse{
+		TriggerID: "2",
+	}
+	responder, _ := httpmock.NewJsonResponder(200, response)
+	httpmock.RegisterResponder("PATCH", fakeRescalingURL, responder)
+
+	client := getTestJobManagerClient().WithVersion(FlinkVersion{Major: 1, Minor: 8})
+	resp, err := client.RescaleJob(ctx, testURL, "1", 4)
+	assert.Equal(t, response.TriggerID, resp)
+	assert.NoError(t, err)
+}
+
+func TestRescaleJobUnsupportedVersion(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+
+	client := getTestJobManagerClient().WithVersion(FlinkVersion{Major: 1, Minor: 9})
+	resp, err := client.RescaleJob(ctx, testURL, "1", 4)
+	assert.Empty(t, resp)
+	assert.EqualError(t, err, "ErrorCode: [UnsupportedOperationError] Reason: [rescaling is not supported from flink 1.9]")
+}
+
+func TestRescaleJob500Response(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder, _ := httpmock.NewJsonResponder(500, nil)
+	httpmock.RegisterResponder("PATCH", fakeRescalingURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.RescaleJob(ctx, testURL, "1", 4)
+	assert.Empty(t, resp)
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [Rescale job failed with status 500]")
+}
+
+func TestCheckRescaleStatusHappyCase(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	response := SavepointResponse{
+		SavepointStatus: SavepointStatusResponse{
+			Status: SavePointInProgress,
+		},
+	}
+	responder, _ := httpmock.NewJsonResponder(200, response)
+	httpmock.RegisterResponder("GET", fakeRescalingStatusURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.CheckRescaleStatus(ctx, testURL, "1", "2")
+	assert.Equal(t, response, *resp)
+	assert.NoError(t, err)
+}
+
+func TestDisposeSavepointHappyCase(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	response := CancelJobResponse{
+		TriggerID: "2",
+	}
+	httpmock.RegisterResponder("POST", fakeSavepointDisposalURL, func(req *http.Request) (*http.Response, error) {
+		var request SavepointDisposalRequest
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		assert.Equal(t, "s3://savepoints/sp-1", request.SavepointPath)
+		return httpmock.NewJsonResponse(200, response)
+	})
+
+	client := getTestJobManagerClient()
+	resp, err := client.DisposeSavepoint(ctx, testURL, "s3://savepoints/sp-1")
+	assert.Equal(t, response.TriggerID, resp)
+	assert.NoError(t, err)
+}
+
+func TestDisposeSavepoint500Response(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder, _ := httpmock.NewJsonResponder(500, nil)
+	httpmock.RegisterResponder("POST", fakeSavepointDisposalURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.DisposeSavepoint(ctx, testURL, "s3://savepoints/sp-1")
+	assert.Empty(t, resp)
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [Dispose savepoint failed with status 500]")
+}
+
+func TestCheckSavepointDisposalStatusHappyCase(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	response := SavepointResponse{
+		SavepointStatus: SavepointStatusResponse{
+			Status: SavePointCompleted,
+		},
+	}
+	responder, _ := httpmock.NewJsonResponder(200, response)
+	httpmock.RegisterResponder("GET", fakeSavepointDisposalStatusURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.CheckSavepointDisposalStatus(ctx, testURL, "2")
+	assert.Equal(t, response, *resp)
+	assert.NoError(t, err)
+}
+
+func TestCheckSavepointDisposalStatusError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	httpmock.RegisterResponder("GET", fakeSavepointDisposalStatusURL, nil)
+
+	client := getTestJobManagerClient()
+	resp, err := client.CheckSavepointDisposalStatus(ctx, testURL, "2")
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [JobManagerUnavailableError] Reason: [Check savepoint disposal status API request failed"))
+}
+
 func TestCancelJobInvalidResponse(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -397,7 +588,7 @@ func TestCancelJob500Response(t *testing.T) {
 	client := getTestJobManagerClient()
 	resp, err := client.CancelJobWithSavepoint(ctx, testURL, "1")
 	assert.Empty(t, resp)
-	assert.EqualError(t, err, "Cancel job failed with status 500")
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [Cancel job failed with status 500]")
 }
 
 func TestCancelJobError(t *testing.T) {
@@ -410,7 +601,7 @@ func TestCancelJobError(t *testing.T) {
 	resp, err := client.CancelJobWithSavepoint(ctx, testURL, "1")
 	assert.Empty(t, resp)
 	assert.NotNil(t, err)
-	assert.True(t, strings.HasPrefix(err.Error(), "Cancel job API request failed"))
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [JobManagerUnavailableError] Reason: [Cancel job API request failed"))
 }
 
 func TestHttpGetNon200Response(t *testing.T) {
@@ -430,7 +621,7 @@ func TestHttpGetNon200Response(t *testing.T) {
 	client := getTestJobManagerClient()
 	_, err := client.GetJobs(ctx, testURL)
 	assert.NotNil(t, err)
-	assert.EqualError(t, err, "GetJobs request failed with status 500")
+	assert.EqualError(t, err, "ErrorCode: [FlinkInternalError] Reason: [GetJobs request failed with status 500]")
 }
 
 func TestClientInvalidMethod(t *testing.T) {
@@ -438,7 +629,7 @@ func TestClientInvalidMethod(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	client := getTestClient()
-	_, err := client.executeRequest("random", testURL, nil)
+	_, err := client.executeRequest(context.Background(), "random", testURL, getJobsURL, nil)
 	assert.NotNil(t, err)
 	assert.EqualError(t, err, "Invalid method random in request")
 }
@@ -476,3 +667,272 @@ func TestGetTaskManagersInvalidResponse(t *testing.T) {
 	_, err := client.GetJobs(ctx, testURL)
 	assert.NotNil(t, err)
 }
+
+func TestGetVertexBackpressureHappyCase(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	mockResponse := `{"status":"ok","backpressure-level":"high","end-timestamp":1555611965910,
+		"subtasks":[{"subtask":0,"backpressure-level":"high","ratio":0.8}]}`
+	responder := httpmock.NewStringResponder(200, mockResponse)
+	httpmock.RegisterResponder("GET", fakeBackpressureURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetVertexBackpressure(ctx, testURL, "1", "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, BackpressureStatusOk, resp.Status)
+	assert.Equal(t, BackpressureLevelHigh, resp.BackpressureLevel)
+	assert.Equal(t, 1, len(resp.Subtasks))
+	assert.Equal(t, 0.8, resp.Subtasks[0].Ratio)
+}
+
+func TestGetVertexBackpressure500Response(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder, _ := httpmock.NewJsonResponder(500, nil)
+	httpmock.RegisterResponder("GET", fakeBackpressureURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetVertexBackpressure(ctx, testURL, "1", "v1")
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [FlinkInternalError] Reason: [get vertex backpressure failed with response"))
+}
+
+func TestGetJobOverviewVertices(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	mockResponse := `{"jid":"1","state":"RUNNING","vertices":[{"id":"v1","name":"Source","parallelism":2,
+		"status":"RUNNING","tasks":{"RUNNING":1,"FAILED":1}}]}`
+	responder := httpmock.NewStringResponder(200, mockResponse)
+	httpmock.RegisterResponder("GET", fakeJobOverviewURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetJobOverview(ctx, testURL, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp.Vertices))
+	assert.Equal(t, "Source", resp.Vertices[0].Name)
+	assert.Equal(t, int32(2), resp.Vertices[0].Parallelism)
+	assert.Equal(t, Running, resp.Vertices[0].Status)
+	assert.Equal(t, int32(1), resp.Vertices[0].Tasks["FAILED"])
+}
+
+func TestGetJobOverview404Response(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder := httpmock.NewStringResponder(404, `{"errors":["Job 1 not found"]}`)
+	httpmock.RegisterResponder("GET", fakeJobOverviewURL, responder)
+
+	client := getTestJobManagerClient()
+	_, err := client.GetJobOverview(ctx, testURL, "1")
+	code, _ := flinkErrors.GetErrorCode(err)
+	assert.Equal(t, flinkErrors.FlinkNotFoundError, code)
+	assert.False(t, flinkErrors.IsRetryable(err))
+	assert.Equal(t, []string{"Job 1 not found"}, flinkErrors.GetFlinkErrors(err))
+}
+
+func TestWithSecurityAuthentication(t *testing.T) {
+	ctx := context.Background()
+	jmClient := getTestJobManagerClient()
+
+	for _, test := range []struct {
+		security      RestSecurity
+		authorization string
+	}{
+		{RestSecurity{BearerToken: "token"}, "Bearer token"},
+		{RestSecurity{Username: "user", Password: "pass"}, "Basic dXNlcjpwYXNz"},
+	} {
+		secureClient := jmClient.WithSecurity(test.security).(*FlinkJobManagerClient)
+		httpmock.ActivateNonDefault(secureClient.client.GetClient())
+
+		authorization := test.authorization
+		httpmock.RegisterResponder("GET", fakeJobsURL, func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, authorization, req.Header.Get("Authorization"))
+			return httpmock.NewJsonResponse(200, GetJobsResponse{})
+		})
+
+		_, err := secureClient.GetJobs(ctx, testURL)
+		assert.NoError(t, err)
+		httpmock.DeactivateAndReset()
+	}
+}
+
+func TestRequestRetries(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+
+	attempts := 0
+	httpmock.RegisterResponder("GET", fakeJobsURL, func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return httpmock.NewJsonResponse(200, GetJobsResponse{})
+	})
+
+	client := getTestJobManagerClient().(*FlinkJobManagerClient)
+	client.read = newRequestPolicy(time.Second, 2, true)
+	_, err := client.GetJobs(ctx, testURL)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestWriteRequestRetries(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+
+	attempts := 0
+	httpmock.RegisterResponder("POST", fakeCancelURL, func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 2 {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		}
+		return nil, errors.New("connection reset by peer")
+	})
+
+	client := getTestJobManagerClient().(*FlinkJobManagerClient)
+	client.write = newRequestPolicy(time.Second, 3, false)
+	_, err := client.CancelJobWithSavepoint(ctx, testURL, "1")
+	assert.Error(t, err)
+
+	// the request is retried after the connection was refused, but not once it may have reached the JobManager
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRequestCircuitBreaker(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+
+	attempts := 0
+	httpmock.RegisterResponder("GET", fakeJobsURL, func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	})
+
+	client := getTestJobManagerClient().(*FlinkJobManagerClient)
+	client.breaker = newCircuitBreaker(2, time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err := client.GetJobs(ctx, testURL)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, attempts)
+
+	// the JobManager is not contacted again until the reset timeout has passed
+	_, err := client.WithVersion(FlinkVersion{Major: 1, Minor: 9}).GetJobs(ctx, testURL)
+	assert.Equal(t, ErrCircuitOpen, errors.Cause(err))
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRequestCancelledContext(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := getTestJobManagerClient().(*FlinkJobManagerClient)
+	client.limiter = rate.NewLimiter(rate.Limit(1), 1)
+	client.breaker = newCircuitBreaker(1, time.Minute)
+	_, err := client.GetJobs(ctx, testURL)
+	assert.True(t, strings.Contains(err.Error(), "rate limit wait failed"))
+
+	// requests abandoned by the caller do not count against the JobManager
+	assert.NoError(t, client.breaker.allow(testURL))
+}
+
+func TestGetJobMetricsHappyCase(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder := httpmock.NewStringResponder(200, `[{"id":"uptime","value":"1000"},{"id":"numRestarts","value":"2"}]`)
+	httpmock.RegisterResponder("GET", fakeJobMetricsURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetJobMetrics(ctx, testURL, "1", MetricsQuery{
+		Metrics: []string{"uptime", "numRestarts"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Metric{{ID: "uptime", Value: "1000"}, {ID: "numRestarts", Value: "2"}}, resp)
+}
+
+func TestGetJobMetricsAggregated(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder := httpmock.NewStringResponder(200, `[{"id":"lastCheckpointSize","max":1024.0}]`)
+	httpmock.RegisterResponder("GET", fakeAggregatedJobMetricsURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetJobMetrics(ctx, testURL, "1", MetricsQuery{
+		Metrics:      []string{"lastCheckpointSize"},
+		Aggregations: []MetricAggregation{MetricAggregationMax},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp))
+	value, ok := resp[0].GetAggregate(MetricAggregationMax)
+	assert.True(t, ok)
+	assert.Equal(t, 1024.0, value)
+	_, ok = resp[0].GetAggregate(MetricAggregationSum)
+	assert.False(t, ok)
+}
+
+func TestGetVertexMetricsAggregated(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder := httpmock.NewStringResponder(200, `[{"id":"numRecordsIn","sum":300.0,"max":200.0}]`)
+	httpmock.RegisterResponder("GET", fakeSubtaskMetricsURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetVertexMetrics(ctx, testURL, "1", "v1", MetricsQuery{
+		Metrics:      []string{"numRecordsIn"},
+		Aggregations: []MetricAggregation{MetricAggregationSum, MetricAggregationMax},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp))
+	assert.Equal(t, 300.0, *resp[0].Sum)
+	assert.Equal(t, 200.0, *resp[0].Max)
+}
+
+func TestGetTaskManagerMetrics(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	httpmock.RegisterResponder("GET", fakeTaskManagerMetricsURL,
+		httpmock.NewStringResponder(200, `[{"id":"Status.JVM.CPU.Load","value":"0.5"}]`))
+	httpmock.RegisterResponder("GET", fakeAggregatedTaskManagerMetricsURL,
+		httpmock.NewStringResponder(200, `[{"id":"Status.JVM.CPU.Load","avg":0.25}]`))
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetTaskManagerMetrics(ctx, testURL, "tm1", MetricsQuery{
+		Metrics: []string{"Status.JVM.CPU.Load"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "0.5", resp[0].Value)
+
+	resp, err = client.GetTaskManagerMetrics(ctx, testURL, "", MetricsQuery{
+		Metrics:      []string{"Status.JVM.CPU.Load"},
+		Aggregations: []MetricAggregation{MetricAggregationAvg},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.25, *resp[0].Avg)
+}
+
+func TestGetJobManagerMetrics500Response(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	ctx := context.Background()
+	responder, _ := httpmock.NewJsonResponder(500, nil)
+	httpmock.RegisterResponder("GET", fakeJobManagerMetricsURL, responder)
+
+	client := getTestJobManagerClient()
+	resp, err := client.GetJobManagerMetrics(ctx, testURL, []string{"numRegisteredTaskManagers"})
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "ErrorCode: [FlinkInternalError] Reason: [get metrics failed with response"))
+}
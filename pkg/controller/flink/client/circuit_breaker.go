@@ -0,0 +1,109 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Returned (wrapped) for requests that are rejected because the JobManager has been unreachable
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Tracks connection failures per JobManager. Once requests to a JobManager have failed threshold times in a row,
+// further requests fail immediately until resetTimeout has passed, after which a single request is let through to
+// probe whether it has recovered. A threshold of zero disables the breaker.
+type circuitBreaker struct {
+	threshold    int
+	resetTimeout time.Duration
+	now          func() time.Time
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, resetTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:    threshold,
+		resetTimeout: resetTimeout,
+		now:          time.Now,
+		circuits:     map[string]*circuit{},
+	}
+}
+
+// Returns an error if requests to the JobManager should not be attempted. If nil is returned, the caller must report
+// the outcome of the request with either record or release.
+func (b *circuitBreaker) allow(key string) error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok || c.failures < b.threshold {
+		return nil
+	}
+	if c.probing || b.now().Sub(c.openedAt) < b.resetTimeout {
+		return errors.Wrapf(ErrCircuitOpen, "%d consecutive failures connecting to %s", c.failures, key)
+	}
+
+	// half-open: let this request through to see if the JobManager has recovered
+	c.probing = true
+	return nil
+}
+
+// Records whether a request to the JobManager succeeded
+func (b *circuitBreaker) record(key string, success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if success {
+		delete(b.circuits, key)
+		return
+	}
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.failures >= b.threshold {
+		c.openedAt = b.now()
+	}
+}
+
+// Reports that a request was abandoned by the caller, which tells us nothing about the JobManager
+func (b *circuitBreaker) release(key string) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if c, ok := b.circuits[key]; ok {
+		c.probing = false
+	}
+}
+
+// Drops all state for the JobManager, which is no longer going to be contacted
+func (b *circuitBreaker) forget(key string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.circuits, key)
+}
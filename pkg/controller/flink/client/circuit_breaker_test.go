@@ -0,0 +1,75 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time {
+		return now
+	}
+
+	breaker.record("jm1", false)
+	assert.NoError(t, breaker.allow("jm1"))
+	breaker.record("jm1", false)
+
+	// the circuit is open, but only for the failing JobManager
+	err := breaker.allow("jm1")
+	assert.Equal(t, ErrCircuitOpen, errors.Cause(err))
+	assert.NoError(t, breaker.allow("jm2"))
+
+	// after the reset timeout a single probe is let through
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.allow("jm1"))
+	assert.Error(t, breaker.allow("jm1"))
+
+	// a failed probe re-opens the circuit
+	breaker.record("jm1", false)
+	assert.Error(t, breaker.allow("jm1"))
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.allow("jm1"))
+	breaker.record("jm1", true)
+	assert.NoError(t, breaker.allow("jm1"))
+	assert.NoError(t, breaker.allow("jm1"))
+}
+
+func TestCircuitBreakerRelease(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time {
+		return now
+	}
+
+	breaker.record("jm1", false)
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.allow("jm1"))
+
+	// an abandoned probe allows another one to be made
+	breaker.release("jm1")
+	assert.NoError(t, breaker.allow("jm1"))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := newCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		breaker.record("jm1", false)
+	}
+	assert.NoError(t, breaker.allow("jm1"))
+}
+
+func TestCircuitBreakerForget(t *testing.T) {
+	breaker := newCircuitBreaker(1, time.Minute)
+	breaker.record("jm1", false)
+	assert.Error(t, breaker.allow("jm1"))
+
+	breaker.forget("jm1")
+	assert.Empty(t, breaker.circuits)
+	assert.NoError(t, breaker.allow("jm1"))
+}
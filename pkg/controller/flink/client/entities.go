@@ -36,6 +36,20 @@ type CancelJobRequest struct {
 	TargetDirectory string `json:"target-directory,omitempty"`
 }
 
+type StopJobRequest struct {
+	Drain           bool   `json:"drain"`
+	TargetDirectory string `json:"targetDirectory,omitempty"`
+}
+
+type SavepointDisposalRequest struct {
+	SavepointPath string `json:"savepoint-path"`
+}
+
+// Body of unsuccessful responses from Flink's REST handlers
+type ErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
 type SubmitJobRequest struct {
 	SavepointPath string `json:"savepointPath"`
 	Parallelism   int32  `json:"parallelism"`
@@ -89,10 +103,21 @@ type FlinkJob struct {
 }
 
 type FlinkJobOverview struct {
-	JobID     string   `json:"jid"`
-	State     JobState `json:"state"`
-	StartTime int64    `json:"start-time"`
-	EndTime   int64    `json:"end-time"`
+	JobID     string           `json:"jid"`
+	State     JobState         `json:"state"`
+	StartTime int64            `json:"start-time"`
+	EndTime   int64            `json:"end-time"`
+	Vertices  []FlinkJobVertex `json:"vertices"`
+}
+
+type FlinkJobVertex struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Parallelism int32            `json:"parallelism"`
+	Status      JobState         `json:"status"`
+	StartTime   int64            `json:"start-time"`
+	EndTime     int64            `json:"end-time"`
+	Tasks       map[string]int32 `json:"tasks"`
 }
 
 type ClusterOverviewResponse struct {
@@ -131,6 +156,35 @@ type CheckpointResponse struct {
 	History []CheckpointStatistics `json:"history"`
 }
 
+type BackpressureStatus string
+
+const (
+	// Flink returns "deprecated" when the last sample is stale and a new one has been triggered
+	BackpressureStatusDeprecated BackpressureStatus = "deprecated"
+	BackpressureStatusOk         BackpressureStatus = "ok"
+)
+
+type BackpressureLevel string
+
+const (
+	BackpressureLevelOk   BackpressureLevel = "ok"
+	BackpressureLevelLow  BackpressureLevel = "low"
+	BackpressureLevelHigh BackpressureLevel = "high"
+)
+
+type SubtaskBackpressure struct {
+	Subtask           int32             `json:"subtask"`
+	BackpressureLevel BackpressureLevel `json:"backpressure-level"`
+	Ratio             float64           `json:"ratio"`
+}
+
+type VertexBackpressureResponse struct {
+	Status            BackpressureStatus    `json:"status"`
+	BackpressureLevel BackpressureLevel     `json:"backpressure-level"`
+	EndTimestamp      int64                 `json:"end-timestamp"`
+	Subtasks          []SubtaskBackpressure `json:"subtasks"`
+}
+
 type TaskManagerStats struct {
 	Path                   string `json:"path"`
 	DataPort               int32  `json:"dataPort"`
@@ -142,3 +196,50 @@ type TaskManagerStats struct {
 type TaskManagersResponse struct {
 	TaskManagers []TaskManagerStats `json:"taskmanagers"`
 }
+
+type MetricAggregation string
+
+const (
+	MetricAggregationMin MetricAggregation = "min"
+	MetricAggregationMax MetricAggregation = "max"
+	MetricAggregationAvg MetricAggregation = "avg"
+	MetricAggregationSum MetricAggregation = "sum"
+)
+
+// Selects the metrics returned by a metrics query. When no metrics are given, Flink returns the IDs of the available
+// metrics without values. Aggregations are only supported across subtasks, taskmanagers or jobs, and select which
+// aggregated values are returned instead of the raw value.
+type MetricsQuery struct {
+	Metrics      []string
+	Aggregations []MetricAggregation
+}
+
+// A single metric in a metrics response. Value is set for queries without aggregations, otherwise the requested
+// aggregated values are set.
+type Metric struct {
+	ID    string   `json:"id"`
+	Value string   `json:"value,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Avg   *float64 `json:"avg,omitempty"`
+	Sum   *float64 `json:"sum,omitempty"`
+}
+
+// Returns the aggregated value of the metric, and whether it was present in the response
+func (m Metric) GetAggregate(aggregation MetricAggregation) (float64, bool) {
+	var value *float64
+	switch aggregation {
+	case MetricAggregationMin:
+		value = m.Min
+	case MetricAggregationMax:
+		value = m.Max
+	case MetricAggregationAvg:
+		value = m.Avg
+	case MetricAggregationSum:
+		value = m.Sum
+	}
+	if value == nil {
+		return 0, false
+	}
+	return *value, true
+}
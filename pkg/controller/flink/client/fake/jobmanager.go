@@ -0,0 +1,851 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+)
+
+// Path prefix added by the Kubernetes API server proxy, which the operator uses when running outside the cluster. Each
+// proxied service is modeled as a separate cluster.
+var proxyPrefixRegex = regexp.MustCompile(`^(/api/v1/namespaces/[^/]+/services/[^/]+/proxy)(/.*)$`)
+
+const (
+	DefaultTaskManagers        = 1
+	DefaultSlotsPerTaskManager = 4
+	DefaultSavepointDirectory  = "file:///tmp/flink/savepoints"
+	DefaultCheckpointDirectory = "file:///tmp/flink/checkpoints"
+)
+
+// Controls the behavior of the clusters served by the fake JobManager. Zero durations mean the corresponding
+// transition happens on the next request.
+type Config struct {
+	TaskManagers        int
+	SlotsPerTaskManager int
+
+	// Time a submitted job spends in CREATED before it starts running, provided enough slots are available
+	JobStartDelay time.Duration
+
+	// Time between triggering a savepoint and it completing
+	SavepointDuration time.Duration
+
+	// Interval between completed checkpoints of running jobs; checkpoints are disabled if zero
+	CheckpointInterval time.Duration
+
+	SavepointDirectory  string
+	CheckpointDirectory string
+}
+
+// An in-process fake of the Flink JobManager REST API, implementing the endpoints used by client.FlinkAPIInterface.
+// It models the lifecycle of submitted jobs, asynchronous savepoints, periodic checkpoints and taskmanager heartbeats,
+// and allows faults to be injected. Time is advanced lazily from the configured clock, so tests can control it
+// precisely with SetClock.
+type JobManager struct {
+	config Config
+
+	lock     sync.Mutex
+	now      func() time.Time
+	clusters map[string]*cluster
+	nextID   int
+
+	// savepoints that have been taken and not disposed, and disposals by trigger ID
+	savepoints map[string]bool
+	disposals  map[string]*disposal
+
+	// values reported for metrics of any scope, in addition to the uptime and numRestarts job metrics
+	metrics map[string]float64
+
+	// faults
+	latency          time.Duration
+	unavailable      bool
+	savepointFailure string
+	heartbeatsLostAt *time.Time
+}
+
+type cluster struct {
+	jobs []*job
+}
+
+type job struct {
+	id           string
+	request      client.SubmitJobRequest
+	state        client.JobState
+	submitTime   time.Time
+	startTime    time.Time
+	endTime      time.Time
+	restoredTime time.Time
+	savepoints   map[string]*savepoint
+	rescalings   map[string]*rescaling
+
+	// set once the job has been stopped with a savepoint after draining its pipeline
+	drained bool
+}
+
+type rescaling struct {
+	triggerTime time.Time
+	parallelism int32
+	failure     string
+	completed   bool
+}
+
+type disposal struct {
+	triggerTime time.Time
+	failure     string
+}
+
+type savepoint struct {
+	triggerTime time.Time
+	location    string
+	failure     string
+	completed   bool
+
+	// the state the job moves to once the savepoint completes, if the savepoint was taken to cancel or stop it
+	terminalState client.JobState
+	drain         bool
+}
+
+func NewJobManager(config Config) *JobManager {
+	if config.TaskManagers == 0 {
+		config.TaskManagers = DefaultTaskManagers
+	}
+	if config.SlotsPerTaskManager == 0 {
+		config.SlotsPerTaskManager = DefaultSlotsPerTaskManager
+	}
+	if config.SavepointDirectory == "" {
+		config.SavepointDirectory = DefaultSavepointDirectory
+	}
+	if config.CheckpointDirectory == "" {
+		config.CheckpointDirectory = DefaultCheckpointDirectory
+	}
+
+	return &JobManager{
+		config:     config,
+		now:        time.Now,
+		clusters:   map[string]*cluster{},
+		savepoints: map[string]bool{},
+		disposals:  map[string]*disposal{},
+		metrics:    map[string]float64{},
+	}
+}
+
+// Replaces the clock used to advance jobs, savepoints and checkpoints
+func (j *JobManager) SetClock(now func() time.Time) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.now = now
+}
+
+// Delays every response by the given duration
+func (j *JobManager) SetLatency(latency time.Duration) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.latency = latency
+}
+
+// Makes every endpoint return 503, as the JobManager does while it is starting up
+func (j *JobManager) SetUnavailable(unavailable bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.unavailable = unavailable
+}
+
+// Causes savepoints triggered from now on to fail with the given cause. An empty cause lets them succeed again.
+func (j *JobManager) FailSavepoints(cause string) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.savepointFailure = cause
+}
+
+// Stops taskmanager heartbeats, so that taskmanagers are reported with a heartbeat that ages from now on
+func (j *JobManager) StopHeartbeats() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	now := j.now()
+	j.heartbeatsLostAt = &now
+}
+
+func (j *JobManager) ResumeHeartbeats() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.heartbeatsLostAt = nil
+}
+
+// Moves a running job to FAILING, where it stays until RecoverJob is called
+func (j *JobManager) FailJob(jobID string) error {
+	return j.setJobState(jobID, client.Running, client.Failing)
+}
+
+// Moves a FAILING job back to RUNNING
+func (j *JobManager) RecoverJob(jobID string) error {
+	return j.setJobState(jobID, client.Failing, client.Running)
+}
+
+// Returns true if the job was stopped after draining its pipeline, so that its sources emitted the final watermark
+// and all event time timers fired before the savepoint was taken
+func (j *JobManager) IsDrained(jobID string) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	c, found := j.findJob(jobID)
+	if found == nil {
+		return false
+	}
+	j.updateCluster(c)
+	return found.drained
+}
+
+// Returns true if a savepoint has been taken at the location and has not been disposed
+func (j *JobManager) HasSavepoint(location string) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.savepoints[location]
+}
+
+// Sets the value reported for a metric. The value is reported for every job, vertex, subtask and taskmanager and for
+// the jobmanager, so aggregated sums are the value multiplied by the number of subtasks or taskmanagers.
+func (j *JobManager) SetMetric(name string, value float64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.metrics[name] = value
+}
+
+// Returns the request a job was submitted with
+func (j *JobManager) SubmitRequest(jobID string) (client.SubmitJobRequest, bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	_, found := j.findJob(jobID)
+	if found == nil {
+		return client.SubmitJobRequest{}, false
+	}
+	return found.request, true
+}
+
+func (j *JobManager) setJobState(jobID string, from client.JobState, to client.JobState) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	c, found := j.findJob(jobID)
+	if found == nil {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+	j.updateCluster(c)
+	if found.state != from {
+		return fmt.Errorf("job %s is %s, not %s", jobID, found.state, from)
+	}
+	found.state = to
+	return nil
+}
+
+func (j *JobManager) findJob(jobID string) (*cluster, *job) {
+	for _, c := range j.clusters {
+		for _, jb := range c.jobs {
+			if jb.id == jobID {
+				return c, jb
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (j *JobManager) generateID() string {
+	j.nextID++
+	return fmt.Sprintf("%032x", j.nextID)
+}
+
+func (j *JobManager) getCluster(key string) *cluster {
+	c, ok := j.clusters[key]
+	if !ok {
+		c = &cluster{}
+		j.clusters[key] = c
+	}
+	return c
+}
+
+func (j *JobManager) totalSlots() int32 {
+	return int32(j.config.TaskManagers * j.config.SlotsPerTaskManager)
+}
+
+func (j *JobManager) availableSlots(c *cluster) int32 {
+	available := j.totalSlots()
+	for _, jb := range c.jobs {
+		if isActive(jb.state) && jb.state != client.Created {
+			available -= jb.parallelism()
+		}
+	}
+	return available
+}
+
+func isActive(state client.JobState) bool {
+	switch state {
+	case client.Canceled, client.Finished, client.Failed:
+		return false
+	default:
+		return true
+	}
+}
+
+func (jb *job) parallelism() int32 {
+	if jb.request.Parallelism > 0 {
+		return jb.request.Parallelism
+	}
+	return 1
+}
+
+// Advances the jobs and savepoints of the cluster to the current time
+func (j *JobManager) updateCluster(c *cluster) {
+	now := j.now()
+	for _, jb := range c.jobs {
+		for _, sp := range jb.savepoints {
+			if sp.completed || now.Sub(sp.triggerTime) < j.config.SavepointDuration {
+				continue
+			}
+			sp.completed = true
+			if sp.failure == "" {
+				j.savepoints[sp.location] = true
+			}
+			if sp.failure == "" && sp.terminalState != "" {
+				jb.state = sp.terminalState
+				jb.endTime = sp.triggerTime.Add(j.config.SavepointDuration)
+				jb.drained = sp.drain
+			}
+		}
+
+		// rescaling takes a savepoint and restarts the job from it with the new parallelism
+		for _, rs := range jb.rescalings {
+			if rs.completed || now.Sub(rs.triggerTime) < j.config.SavepointDuration {
+				continue
+			}
+			rs.completed = true
+			if rs.failure == "" && j.availableSlots(c)+jb.parallelism() < rs.parallelism {
+				rs.failure = fmt.Sprintf("Not enough slots to rescale job %s to parallelism %d", jb.id, rs.parallelism)
+			}
+			if rs.failure == "" {
+				jb.request.Parallelism = rs.parallelism
+			}
+		}
+
+		if jb.state == client.Created && now.Sub(jb.submitTime) >= j.config.JobStartDelay &&
+			j.availableSlots(c) >= jb.parallelism() {
+			jb.state = client.Running
+			jb.startTime = now
+			if jb.request.SavepointPath != "" {
+				jb.restoredTime = now
+			}
+		}
+	}
+}
+
+func (j *JobManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	j.lock.Lock()
+	latency := j.latency
+	j.lock.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(latency):
+		}
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.unavailable {
+		writeError(w, http.StatusServiceUnavailable, "JobManager is not available")
+		return
+	}
+
+	key, path := "", r.URL.Path
+	if matches := proxyPrefixRegex.FindStringSubmatch(path); matches != nil {
+		key, path = matches[1], matches[2]
+	}
+	c := j.getCluster(key)
+	j.updateCluster(c)
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "overview":
+		j.handleOverview(w, c)
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "taskmanagers":
+		j.handleTaskManagers(w)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "taskmanagers" && parts[1] == "metrics":
+		j.handleMetrics(w, r, nil, j.config.TaskManagers)
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "taskmanagers" && parts[2] == "metrics":
+		j.handleMetrics(w, r, nil, 1)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "jobmanager" && parts[1] == "metrics":
+		j.handleMetrics(w, r, nil, 1)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "jars" && parts[2] == "run":
+		j.handleSubmitJob(w, r, c)
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "jobs":
+		j.handleGetJobs(w, c)
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "savepoint-disposal":
+		j.handleDisposeSavepoint(w, r)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "savepoint-disposal":
+		j.handleDisposalStatus(w, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "jobs" && parts[1] == "metrics":
+		jb := c.getJob(r.URL.Query().Get("jobs"))
+		if jb == nil {
+			writeJSON(w, http.StatusOK, []client.Metric{})
+			return
+		}
+		j.handleMetrics(w, r, jb, 1)
+	case len(parts) >= 2 && parts[0] == "jobs":
+		jb := c.getJob(parts[1])
+		if jb == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Job %s not found", parts[1]))
+			return
+		}
+		j.handleJob(w, r, c, jb, parts[2:])
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+func (j *JobManager) handleJob(w http.ResponseWriter, r *http.Request, c *cluster, jb *job, parts []string) {
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 0:
+		j.handleJobOverview(w, jb)
+	case r.Method == http.MethodPatch && len(parts) == 0:
+		if isActive(jb.state) {
+			jb.state = client.Canceled
+			jb.endTime = j.now()
+		}
+		writeJSON(w, http.StatusAccepted, struct{}{})
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "config":
+		writeJSON(w, http.StatusOK, client.JobConfigResponse{
+			JobID: jb.id,
+			ExecutionConfig: client.JobExecutionConfig{
+				Parallelism: jb.parallelism(),
+			},
+		})
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "checkpoints":
+		j.handleCheckpoints(w, jb)
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "savepoints":
+		var request client.CancelJobRequest
+		if !readJSON(w, r, &request) {
+			return
+		}
+		terminalState := client.JobState("")
+		if request.CancelJob {
+			terminalState = client.Canceled
+		}
+		j.triggerSavepoint(w, jb, terminalState, false)
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "stop":
+		var request client.StopJobRequest
+		if !readJSON(w, r, &request) {
+			return
+		}
+		j.triggerSavepoint(w, jb, client.Finished, request.Drain)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "savepoints":
+		j.handleSavepointStatus(w, jb, parts[1])
+	case r.Method == http.MethodPatch && len(parts) == 1 && parts[0] == "rescaling":
+		j.handleRescale(w, r, jb)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "rescaling":
+		j.handleRescaleStatus(w, jb, parts[1])
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "vertices" && parts[2] == "backpressure":
+		j.handleBackpressure(w, jb)
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "metrics":
+		j.handleMetrics(w, r, jb, 1)
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "vertices" && parts[2] == "metrics":
+		j.handleMetrics(w, r, nil, 1)
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[0] == "vertices" && parts[2] == "subtasks" &&
+		parts[3] == "metrics":
+		j.handleMetrics(w, r, nil, int(jb.parallelism()))
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+func (c *cluster) getJob(jobID string) *job {
+	for _, jb := range c.jobs {
+		if jb.id == jobID {
+			return jb
+		}
+	}
+	return nil
+}
+
+func (j *JobManager) handleOverview(w http.ResponseWriter, c *cluster) {
+	writeJSON(w, http.StatusOK, client.ClusterOverviewResponse{
+		TaskManagerCount:  int32(j.config.TaskManagers),
+		SlotsAvailable:    j.availableSlots(c),
+		NumberOfTaskSlots: j.totalSlots(),
+	})
+}
+
+func (j *JobManager) handleTaskManagers(w http.ResponseWriter) {
+	// despite its name, Flink reports the time of the last heartbeat in milliseconds since the epoch
+	lastHeartbeat := j.now()
+	if j.heartbeatsLostAt != nil {
+		lastHeartbeat = *j.heartbeatsLostAt
+	}
+
+	response := client.TaskManagersResponse{}
+	for i := 0; i < j.config.TaskManagers; i++ {
+		response.TaskManagers = append(response.TaskManagers, client.TaskManagerStats{
+			Path:                   fmt.Sprintf("akka.tcp://flink@taskmanager-%d:6122/user/taskmanager_0", i),
+			DataPort:               6121,
+			TimeSinceLastHeartbeat: toMillis(lastHeartbeat),
+			SlotsNumber:            int32(j.config.SlotsPerTaskManager),
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (j *JobManager) handleSubmitJob(w http.ResponseWriter, r *http.Request, c *cluster) {
+	var request client.SubmitJobRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	jb := &job{
+		id:         j.generateID(),
+		request:    request,
+		state:      client.Created,
+		submitTime: j.now(),
+		savepoints: map[string]*savepoint{},
+		rescalings: map[string]*rescaling{},
+	}
+	c.jobs = append(c.jobs, jb)
+	j.updateCluster(c)
+
+	writeJSON(w, http.StatusOK, client.SubmitJobResponse{
+		JobID: jb.id,
+	})
+}
+
+func (j *JobManager) handleGetJobs(w http.ResponseWriter, c *cluster) {
+	response := client.GetJobsResponse{
+		Jobs: []client.FlinkJob{},
+	}
+	for _, jb := range c.jobs {
+		response.Jobs = append(response.Jobs, client.FlinkJob{
+			JobID:  jb.id,
+			Status: jb.state,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (j *JobManager) handleJobOverview(w http.ResponseWriter, jb *job) {
+	overview := client.FlinkJobOverview{
+		JobID:     jb.id,
+		State:     jb.state,
+		StartTime: toMillis(jb.startTime),
+		EndTime:   -1,
+	}
+	if !jb.endTime.IsZero() {
+		overview.EndTime = toMillis(jb.endTime)
+	}
+
+	taskState := jb.state
+	if jb.state == client.Failing {
+		taskState = client.Failed
+	}
+	for i, name := range []string{"Source", "Sink"} {
+		overview.Vertices = append(overview.Vertices, client.FlinkJobVertex{
+			ID:          fmt.Sprintf("%s%02d", jb.id[:30], i),
+			Name:        name,
+			Parallelism: jb.parallelism(),
+			Status:      jb.state,
+			StartTime:   overview.StartTime,
+			EndTime:     overview.EndTime,
+			Tasks: map[string]int32{
+				string(taskState): jb.parallelism(),
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, overview)
+}
+
+func (j *JobManager) handleCheckpoints(w http.ResponseWriter, jb *job) {
+	response := client.CheckpointResponse{
+		Counts: map[string]int32{
+			"completed":   0,
+			"failed":      0,
+			"in_progress": 0,
+			"restored":    0,
+			"total":       0,
+		},
+		History: []client.CheckpointStatistics{},
+	}
+
+	if !jb.startTime.IsZero() && j.config.CheckpointInterval > 0 {
+		end := j.now()
+		if !jb.endTime.IsZero() {
+			end = jb.endTime
+		}
+		completed := int(end.Sub(jb.startTime) / j.config.CheckpointInterval)
+		for id := completed; id > 0 && len(response.History) < 10; id-- {
+			triggered := jb.startTime.Add(time.Duration(id) * j.config.CheckpointInterval)
+			response.History = append(response.History, client.CheckpointStatistics{
+				ID:                 uint(id),
+				Status:             client.CheckpointCompleted,
+				TriggerTimestamp:   toMillis(triggered),
+				LatestAckTimestamp: toMillis(triggered),
+				NumSubtasks:        int64(2 * jb.parallelism()),
+				ExternalPath:       fmt.Sprintf("%s/%s/chk-%d", j.config.CheckpointDirectory, jb.id, id),
+			})
+		}
+		if completed > 0 {
+			response.Latest.Completed = &response.History[0]
+		}
+		response.Counts["completed"] = int32(completed)
+		response.Counts["total"] = int32(completed)
+	}
+
+	if !jb.restoredTime.IsZero() {
+		response.Counts["restored"] = 1
+		response.Latest.Restored = &client.CheckpointStatistics{
+			IsSavepoint:       true,
+			ExternalPath:      jb.request.SavepointPath,
+			RestoredTimeStamp: toMillis(jb.restoredTime),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (j *JobManager) triggerSavepoint(w http.ResponseWriter, jb *job, terminalState client.JobState, drain bool) {
+	triggerID := j.generateID()
+	sp := &savepoint{
+		triggerTime:   j.now(),
+		failure:       j.savepointFailure,
+		terminalState: terminalState,
+		drain:         drain,
+	}
+	if jb.state != client.Running {
+		sp.failure = fmt.Sprintf("Job %s is not in state RUNNING but %s instead", jb.id, jb.state)
+	}
+	if sp.failure == "" {
+		sp.location = fmt.Sprintf("%s/savepoint-%s-%s", j.config.SavepointDirectory, jb.id[:6], triggerID[20:])
+	}
+	jb.savepoints[triggerID] = sp
+
+	writeJSON(w, http.StatusAccepted, client.CancelJobResponse{
+		TriggerID: triggerID,
+	})
+}
+
+func (j *JobManager) handleSavepointStatus(w http.ResponseWriter, jb *job, triggerID string) {
+	sp, ok := jb.savepoints[triggerID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Operation %s not found", triggerID))
+		return
+	}
+
+	response := client.SavepointResponse{
+		SavepointStatus: client.SavepointStatusResponse{
+			Status: client.SavePointInProgress,
+		},
+	}
+	if sp.completed {
+		response.SavepointStatus.Status = client.SavePointCompleted
+		if sp.failure != "" {
+			response.Operation.FailureCause = client.FailureCause{
+				Class:      "java.util.concurrent.CompletionException",
+				StackTrace: sp.failure,
+			}
+		} else {
+			response.Operation.Location = sp.location
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (j *JobManager) handleRescale(w http.ResponseWriter, r *http.Request, jb *job) {
+	parallelism, err := strconv.Atoi(r.URL.Query().Get("parallelism"))
+	if err != nil || parallelism <= 0 {
+		writeError(w, http.StatusBadRequest, "Parallelism must be a positive integer")
+		return
+	}
+
+	rs := &rescaling{
+		triggerTime: j.now(),
+		parallelism: int32(parallelism),
+	}
+	if jb.state != client.Running {
+		rs.failure = fmt.Sprintf("Job %s is not in state RUNNING but %s instead", jb.id, jb.state)
+	}
+	triggerID := j.generateID()
+	jb.rescalings[triggerID] = rs
+
+	writeJSON(w, http.StatusOK, client.CancelJobResponse{
+		TriggerID: triggerID,
+	})
+}
+
+func (j *JobManager) handleRescaleStatus(w http.ResponseWriter, jb *job, triggerID string) {
+	rs, ok := jb.rescalings[triggerID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Operation %s not found", triggerID))
+		return
+	}
+
+	response := client.SavepointResponse{
+		SavepointStatus: client.SavepointStatusResponse{
+			Status: client.SavePointInProgress,
+		},
+	}
+	if rs.completed {
+		response.SavepointStatus.Status = client.SavePointCompleted
+		if rs.failure != "" {
+			response.Operation.FailureCause = client.FailureCause{
+				Class:      "org.apache.flink.runtime.rest.handler.RestHandlerException",
+				StackTrace: rs.failure,
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (j *JobManager) handleDisposeSavepoint(w http.ResponseWriter, r *http.Request) {
+	var request client.SavepointDisposalRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	d := &disposal{
+		triggerTime: j.now(),
+	}
+	if j.savepoints[request.SavepointPath] {
+		delete(j.savepoints, request.SavepointPath)
+	} else {
+		d.failure = fmt.Sprintf("Savepoint %s does not exist", request.SavepointPath)
+	}
+	triggerID := j.generateID()
+	j.disposals[triggerID] = d
+
+	writeJSON(w, http.StatusOK, client.CancelJobResponse{
+		TriggerID: triggerID,
+	})
+}
+
+func (j *JobManager) handleDisposalStatus(w http.ResponseWriter, triggerID string) {
+	d, ok := j.disposals[triggerID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Operation %s not found", triggerID))
+		return
+	}
+
+	response := client.SavepointResponse{
+		SavepointStatus: client.SavepointStatusResponse{
+			Status: client.SavePointInProgress,
+		},
+	}
+	if j.now().Sub(d.triggerTime) >= j.config.SavepointDuration {
+		response.SavepointStatus.Status = client.SavePointCompleted
+		if d.failure != "" {
+			response.Operation.FailureCause = client.FailureCause{
+				Class:      "java.io.FileNotFoundException",
+				StackTrace: d.failure,
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (j *JobManager) handleBackpressure(w http.ResponseWriter, jb *job) {
+	response := client.VertexBackpressureResponse{
+		Status:            client.BackpressureStatusOk,
+		BackpressureLevel: client.BackpressureLevelOk,
+		EndTimestamp:      toMillis(j.now()),
+	}
+	for i := int32(0); i < jb.parallelism(); i++ {
+		response.Subtasks = append(response.Subtasks, client.SubtaskBackpressure{
+			Subtask:           i,
+			BackpressureLevel: client.BackpressureLevelOk,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Serves a metrics query. Without the get parameter the available metrics are listed; with the agg parameter the
+// requested aggregations across count identical components are returned instead of the value.
+func (j *JobManager) handleMetrics(w http.ResponseWriter, r *http.Request, jb *job, count int) {
+	values := make(map[string]float64, len(j.metrics)+2)
+	for name, value := range j.metrics {
+		values[name] = value
+	}
+	if jb != nil {
+		values["numRestarts"] = 0
+		values["uptime"] = 0
+		if jb.state == client.Running {
+			values["uptime"] = float64(j.now().Sub(jb.startTime) / time.Millisecond)
+		}
+	}
+
+	query := r.URL.Query()
+	response := []client.Metric{}
+	if query.Get("get") == "" {
+		for name := range values {
+			response = append(response, client.Metric{ID: name})
+		}
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+
+	for _, name := range strings.Split(query.Get("get"), ",") {
+		value, ok := values[name]
+		if !ok {
+			// Flink omits metrics that do not exist
+			continue
+		}
+
+		metric := client.Metric{ID: name}
+		if query.Get("agg") == "" {
+			metric.Value = strconv.FormatFloat(value, 'f', -1, 64)
+		} else {
+			sum := value * float64(count)
+			for _, aggregation := range strings.Split(query.Get("agg"), ",") {
+				switch client.MetricAggregation(aggregation) {
+				case client.MetricAggregationMin:
+					metric.Min = &value
+				case client.MetricAggregationMax:
+					metric.Max = &value
+				case client.MetricAggregationAvg:
+					metric.Avg = &value
+				case client.MetricAggregationSum:
+					metric.Sum = &sum
+				}
+			}
+		}
+		response = append(response, metric)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func toMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return -1
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Request did not match expected format: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Errors are returned in the same format as Flink's REST handlers
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string][]string{
+		"errors": {message},
+	})
+}
@@ -0,0 +1,344 @@
+package fake
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	mockScope "github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func getTestServer(cfg Config) (*JobManager, *testClock, *httptest.Server, client.FlinkAPIInterface) {
+	clock := &testClock{now: time.Now()}
+	jm := NewJobManager(cfg)
+	jm.SetClock(clock.Now)
+	server := httptest.NewServer(jm)
+
+	labeled.SetMetricKeys(common.GetValidLabelNames()...)
+	flinkClient := client.NewFlinkJobManagerClient(config.RuntimeConfig{
+		MetricsScope: mockScope.NewTestScope(),
+	}).WithVersion(client.FlinkVersion{Major: 1, Minor: 9})
+
+	return jm, clock, server, flinkClient
+}
+
+func TestJobLifecycle(t *testing.T) {
+	jm, clock, server, flinkClient := getTestServer(Config{
+		TaskManagers:        2,
+		SlotsPerTaskManager: 2,
+		JobStartDelay:       10 * time.Second,
+		SavepointDuration:   30 * time.Second,
+		CheckpointInterval:  time.Minute,
+	})
+	defer server.Close()
+	ctx := context.Background()
+
+	submitResponse, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{
+		Parallelism:   3,
+		SavepointPath: "s3://savepoints/1",
+	})
+	assert.NoError(t, err)
+	jobID := submitResponse.JobID
+	request, ok := jm.SubmitRequest(jobID)
+	assert.True(t, ok)
+	assert.Equal(t, "s3://savepoints/1", request.SavepointPath)
+
+	jobs, err := flinkClient.GetJobs(ctx, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.FlinkJob{{JobID: jobID, Status: client.Created}}, jobs.Jobs)
+
+	clock.Advance(10 * time.Second)
+	overview, err := flinkClient.GetJobOverview(ctx, server.URL, jobID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.Running, overview.State)
+	assert.Equal(t, 2, len(overview.Vertices))
+
+	cluster, err := flinkClient.GetClusterOverview(ctx, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), cluster.TaskManagerCount)
+	assert.Equal(t, int32(4), cluster.NumberOfTaskSlots)
+	assert.Equal(t, int32(1), cluster.SlotsAvailable)
+
+	jobConfig, err := flinkClient.GetJobConfig(ctx, server.URL, jobID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), jobConfig.ExecutionConfig.Parallelism)
+
+	clock.Advance(150 * time.Second)
+	checkpoints, err := flinkClient.GetCheckpointCounts(ctx, server.URL, jobID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), checkpoints.Counts["completed"])
+	assert.Equal(t, int32(1), checkpoints.Counts["restored"])
+	assert.Equal(t, "s3://savepoints/1", checkpoints.Latest.Restored.ExternalPath)
+	assert.Equal(t, uint(2), checkpoints.Latest.Completed.ID)
+
+	triggerID, err := flinkClient.CancelJobWithSavepoint(ctx, server.URL, jobID)
+	assert.NoError(t, err)
+	savepoint, err := flinkClient.CheckSavepointStatus(ctx, server.URL, jobID, triggerID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.SavePointInProgress, savepoint.SavepointStatus.Status)
+
+	clock.Advance(30 * time.Second)
+	savepoint, err = flinkClient.CheckSavepointStatus(ctx, server.URL, jobID, triggerID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.SavePointCompleted, savepoint.SavepointStatus.Status)
+	assert.NotEmpty(t, savepoint.Operation.Location)
+
+	jobs, err = flinkClient.GetJobs(ctx, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, client.Canceled, jobs.Jobs[0].Status)
+}
+
+func TestJobWaitsForSlots(t *testing.T) {
+	_, _, server, flinkClient := getTestServer(Config{})
+	defer server.Close()
+	ctx := context.Background()
+
+	response, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{
+		Parallelism: DefaultSlotsPerTaskManager + 1,
+	})
+	assert.NoError(t, err)
+
+	overview, err := flinkClient.GetJobOverview(ctx, server.URL, response.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.Created, overview.State)
+}
+
+func TestStopWithSavepoint(t *testing.T) {
+	jm, _, server, flinkClient := getTestServer(Config{})
+	defer server.Close()
+	ctx := context.Background()
+
+	for i, drain := range []bool{true, false} {
+		response, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{Parallelism: 1})
+		assert.NoError(t, err)
+
+		triggerID, err := flinkClient.StopJobWithSavepoint(ctx, server.URL, response.JobID, drain)
+		assert.NoError(t, err)
+		savepoint, err := flinkClient.CheckSavepointStatus(ctx, server.URL, response.JobID, triggerID)
+		assert.NoError(t, err)
+		assert.Equal(t, client.SavePointCompleted, savepoint.SavepointStatus.Status)
+
+		jobs, err := flinkClient.GetJobs(ctx, server.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, client.Finished, jobs.Jobs[i].Status)
+		assert.Equal(t, drain, jm.IsDrained(response.JobID))
+	}
+}
+
+func TestSavepointFailure(t *testing.T) {
+	jm, _, server, flinkClient := getTestServer(Config{})
+	defer server.Close()
+	ctx := context.Background()
+
+	response, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{Parallelism: 1})
+	assert.NoError(t, err)
+
+	jm.FailSavepoints("checkpoint coordinator is shut down")
+	triggerID, err := flinkClient.CancelJobWithSavepoint(ctx, server.URL, response.JobID)
+	assert.NoError(t, err)
+	savepoint, err := flinkClient.CheckSavepointStatus(ctx, server.URL, response.JobID, triggerID)
+	assert.NoError(t, err)
+	assert.Empty(t, savepoint.Operation.Location)
+	assert.Equal(t, "checkpoint coordinator is shut down", savepoint.Operation.FailureCause.StackTrace)
+
+	// the job keeps running when the savepoint fails
+	jobs, err := flinkClient.GetJobs(ctx, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, client.Running, jobs.Jobs[0].Status)
+}
+
+func TestRescaling(t *testing.T) {
+	_, clock, server, flinkClient := getTestServer(Config{
+		TaskManagers:        1,
+		SlotsPerTaskManager: 4,
+		SavepointDuration:   10 * time.Second,
+	})
+	defer server.Close()
+	ctx := context.Background()
+	flinkClient = flinkClient.WithVersion(client.FlinkVersion{Major: 1, Minor: 8})
+
+	response, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{Parallelism: 2})
+	assert.NoError(t, err)
+
+	triggerID, err := flinkClient.RescaleJob(ctx, server.URL, response.JobID, 3)
+	assert.NoError(t, err)
+	status, err := flinkClient.CheckRescaleStatus(ctx, server.URL, response.JobID, triggerID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.SavePointInProgress, status.SavepointStatus.Status)
+
+	clock.Advance(10 * time.Second)
+	status, err = flinkClient.CheckRescaleStatus(ctx, server.URL, response.JobID, triggerID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.SavePointCompleted, status.SavepointStatus.Status)
+	assert.Equal(t, client.FailureCause{}, status.Operation.FailureCause)
+	jobConfig, err := flinkClient.GetJobConfig(ctx, server.URL, response.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), jobConfig.ExecutionConfig.Parallelism)
+
+	// rescaling fails if the cluster does not have enough slots
+	triggerID, err = flinkClient.RescaleJob(ctx, server.URL, response.JobID, 5)
+	assert.NoError(t, err)
+	clock.Advance(10 * time.Second)
+	status, err = flinkClient.CheckRescaleStatus(ctx, server.URL, response.JobID, triggerID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, status.Operation.FailureCause.StackTrace)
+	jobConfig, err = flinkClient.GetJobConfig(ctx, server.URL, response.JobID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), jobConfig.ExecutionConfig.Parallelism)
+}
+
+func TestSavepointDisposal(t *testing.T) {
+	jm, clock, server, flinkClient := getTestServer(Config{
+		SavepointDuration: 10 * time.Second,
+	})
+	defer server.Close()
+	ctx := context.Background()
+
+	response, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{Parallelism: 1})
+	assert.NoError(t, err)
+	triggerID, err := flinkClient.CancelJobWithSavepoint(ctx, server.URL, response.JobID)
+	assert.NoError(t, err)
+	clock.Advance(10 * time.Second)
+	savepoint, err := flinkClient.CheckSavepointStatus(ctx, server.URL, response.JobID, triggerID)
+	assert.NoError(t, err)
+	location := savepoint.Operation.Location
+	assert.True(t, jm.HasSavepoint(location))
+
+	disposalID, err := flinkClient.DisposeSavepoint(ctx, server.URL, location)
+	assert.NoError(t, err)
+	disposal, err := flinkClient.CheckSavepointDisposalStatus(ctx, server.URL, disposalID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.SavePointInProgress, disposal.SavepointStatus.Status)
+
+	clock.Advance(10 * time.Second)
+	disposal, err = flinkClient.CheckSavepointDisposalStatus(ctx, server.URL, disposalID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.SavePointCompleted, disposal.SavepointStatus.Status)
+	assert.Equal(t, client.FailureCause{}, disposal.Operation.FailureCause)
+	assert.False(t, jm.HasSavepoint(location))
+
+	// disposing it again fails, as it no longer exists
+	disposalID, err = flinkClient.DisposeSavepoint(ctx, server.URL, location)
+	assert.NoError(t, err)
+	clock.Advance(10 * time.Second)
+	disposal, err = flinkClient.CheckSavepointDisposalStatus(ctx, server.URL, disposalID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.SavePointCompleted, disposal.SavepointStatus.Status)
+	assert.Equal(t, "java.io.FileNotFoundException", disposal.Operation.FailureCause.Class)
+
+	_, err = flinkClient.CheckSavepointDisposalStatus(ctx, server.URL, "unknown")
+	assert.Error(t, err)
+}
+
+func TestMetrics(t *testing.T) {
+	jm, clock, server, flinkClient := getTestServer(Config{
+		TaskManagers:        2,
+		SlotsPerTaskManager: 4,
+	})
+	defer server.Close()
+	ctx := context.Background()
+	jm.SetMetric("numRecordsIn", 100)
+
+	response, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{Parallelism: 3})
+	assert.NoError(t, err)
+	clock.Advance(time.Minute)
+
+	metrics, err := flinkClient.GetJobMetrics(ctx, server.URL, response.JobID, client.MetricsQuery{
+		Metrics: []string{"uptime", "numRestarts", "unknown"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Metric{{ID: "uptime", Value: "60000"}, {ID: "numRestarts", Value: "0"}}, metrics)
+
+	overview, err := flinkClient.GetJobOverview(ctx, server.URL, response.JobID)
+	assert.NoError(t, err)
+	metrics, err = flinkClient.GetVertexMetrics(ctx, server.URL, response.JobID, overview.Vertices[0].ID, client.MetricsQuery{
+		Metrics:      []string{"numRecordsIn"},
+		Aggregations: []client.MetricAggregation{client.MetricAggregationSum, client.MetricAggregationMax},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 300.0, *metrics[0].Sum)
+	assert.Equal(t, 100.0, *metrics[0].Max)
+	assert.Nil(t, metrics[0].Min)
+
+	metrics, err = flinkClient.GetTaskManagerMetrics(ctx, server.URL, "", client.MetricsQuery{
+		Metrics:      []string{"numRecordsIn"},
+		Aggregations: []client.MetricAggregation{client.MetricAggregationSum},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200.0, *metrics[0].Sum)
+
+	metrics, err = flinkClient.GetJobManagerMetrics(ctx, server.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Metric{{ID: "numRecordsIn"}}, metrics)
+}
+
+func TestInjectedFaults(t *testing.T) {
+	jm, clock, server, flinkClient := getTestServer(Config{})
+	defer server.Close()
+	ctx := context.Background()
+
+	response, err := flinkClient.SubmitJob(ctx, server.URL, "jar", client.SubmitJobRequest{Parallelism: 1})
+	assert.NoError(t, err)
+
+	assert.NoError(t, jm.FailJob(response.JobID))
+	jobs, err := flinkClient.GetJobs(ctx, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, client.Failing, jobs.Jobs[0].Status)
+	assert.NoError(t, jm.RecoverJob(response.JobID))
+	assert.Error(t, jm.RecoverJob(response.JobID))
+
+	jm.StopHeartbeats()
+	clock.Advance(time.Minute)
+	taskManagers, err := flinkClient.GetTaskManagers(ctx, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, toMillis(clock.Now().Add(-time.Minute)), taskManagers.TaskManagers[0].TimeSinceLastHeartbeat)
+
+	jm.SetUnavailable(true)
+	_, err = flinkClient.GetClusterOverview(ctx, server.URL)
+	assert.EqualError(t, err, "ErrorCode: [JobManagerUnavailableError] Reason: [Get cluster overview failed with status 503 Service Unavailable]")
+	jm.SetUnavailable(false)
+
+	jm.SetLatency(time.Minute)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = flinkClient.GetClusterOverview(timeoutCtx, server.URL)
+	assert.Error(t, err)
+}
+
+func TestProxiedClusters(t *testing.T) {
+	_, _, server, flinkClient := getTestServer(Config{})
+	defer server.Close()
+	ctx := context.Background()
+
+	url1 := server.URL + "/api/v1/namespaces/ns/services/app-hash1:8081/proxy"
+	url2 := server.URL + "/api/v1/namespaces/ns/services/app-hash2:8081/proxy"
+
+	_, err := flinkClient.SubmitJob(ctx, url1, "jar", client.SubmitJobRequest{Parallelism: 1})
+	assert.NoError(t, err)
+
+	jobs, err := flinkClient.GetJobs(ctx, url1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jobs.Jobs))
+
+	jobs, err = flinkClient.GetJobs(ctx, url2)
+	assert.NoError(t, err)
+	assert.Empty(t, jobs.Jobs)
+}
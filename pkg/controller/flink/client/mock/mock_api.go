@@ -7,6 +7,11 @@ import (
 )
 
 type CancelJobWithSavepointFunc func(ctx context.Context, url string, jobID string) (string, error)
+type StopJobWithSavepointFunc func(ctx context.Context, url string, jobID string, drain bool) (string, error)
+type RescaleJobFunc func(ctx context.Context, url string, jobID string, parallelism int32) (string, error)
+type CheckRescaleStatusFunc func(ctx context.Context, url string, jobID string, triggerID string) (*client.SavepointResponse, error)
+type DisposeSavepointFunc func(ctx context.Context, url string, savepointPath string) (string, error)
+type CheckSavepointDisposalStatusFunc func(ctx context.Context, url string, triggerID string) (*client.SavepointResponse, error)
 type ForceCancelJobFunc func(ctx context.Context, url string, jobID string) error
 type SubmitJobFunc func(ctx context.Context, url string, jarID string, submitJobRequest client.SubmitJobRequest) (*client.SubmitJobResponse, error)
 type CheckSavepointStatusFunc func(ctx context.Context, url string, jobID, triggerID string) (*client.SavepointResponse, error)
@@ -17,19 +22,40 @@ type GetJobConfigFunc func(ctx context.Context, url string, jobID string) (*clie
 type GetTaskManagersFunc func(ctx context.Context, url string) (*client.TaskManagersResponse, error)
 type GetCheckpointCountsFunc func(ctx context.Context, url string, jobID string) (*client.CheckpointResponse, error)
 type GetJobOverviewFunc func(ctx context.Context, url string, jobID string) (*client.FlinkJobOverview, error)
+type GetVertexBackpressureFunc func(ctx context.Context, url string, jobID string, vertexID string) (*client.VertexBackpressureResponse, error)
+type GetJobMetricsFunc func(ctx context.Context, url string, jobID string, query client.MetricsQuery) ([]client.Metric, error)
+type GetVertexMetricsFunc func(ctx context.Context, url string, jobID string, vertexID string, query client.MetricsQuery) ([]client.Metric, error)
+type GetTaskManagerMetricsFunc func(ctx context.Context, url string, taskManagerID string, query client.MetricsQuery) ([]client.Metric, error)
+type GetJobManagerMetricsFunc func(ctx context.Context, url string, metrics []string) ([]client.Metric, error)
+type WithSecurityFunc func(security client.RestSecurity) client.FlinkAPIInterface
+type WithVersionFunc func(version client.FlinkVersion) client.FlinkAPIInterface
+type ForgetJobManagerFunc func(url string)
 
 type JobManagerClient struct {
-	CancelJobWithSavepointFunc CancelJobWithSavepointFunc
-	ForceCancelJobFunc         ForceCancelJobFunc
-	SubmitJobFunc              SubmitJobFunc
-	CheckSavepointStatusFunc   CheckSavepointStatusFunc
-	GetJobsFunc                GetJobsFunc
-	GetClusterOverviewFunc     GetClusterOverviewFunc
-	GetJobConfigFunc           GetJobConfigFunc
-	GetLatestCheckpointFunc    GetLatestCheckpointFunc
-	GetTaskManagersFunc        GetTaskManagersFunc
-	GetCheckpointCountsFunc    GetCheckpointCountsFunc
-	GetJobOverviewFunc         GetJobOverviewFunc
+	CancelJobWithSavepointFunc       CancelJobWithSavepointFunc
+	StopJobWithSavepointFunc         StopJobWithSavepointFunc
+	ForceCancelJobFunc               ForceCancelJobFunc
+	RescaleJobFunc                   RescaleJobFunc
+	CheckRescaleStatusFunc           CheckRescaleStatusFunc
+	DisposeSavepointFunc             DisposeSavepointFunc
+	CheckSavepointDisposalStatusFunc CheckSavepointDisposalStatusFunc
+	SubmitJobFunc                    SubmitJobFunc
+	CheckSavepointStatusFunc         CheckSavepointStatusFunc
+	GetJobsFunc                      GetJobsFunc
+	GetClusterOverviewFunc           GetClusterOverviewFunc
+	GetJobConfigFunc                 GetJobConfigFunc
+	GetLatestCheckpointFunc          GetLatestCheckpointFunc
+	GetTaskManagersFunc              GetTaskManagersFunc
+	GetCheckpointCountsFunc          GetCheckpointCountsFunc
+	GetJobOverviewFunc               GetJobOverviewFunc
+	GetVertexBackpressureFunc        GetVertexBackpressureFunc
+	GetJobMetricsFunc                GetJobMetricsFunc
+	GetVertexMetricsFunc             GetVertexMetricsFunc
+	GetTaskManagerMetricsFunc        GetTaskManagerMetricsFunc
+	GetJobManagerMetricsFunc         GetJobManagerMetricsFunc
+	WithSecurityFunc                 WithSecurityFunc
+	WithVersionFunc                  WithVersionFunc
+	ForgetJobManagerFunc             ForgetJobManagerFunc
 }
 
 func (m *JobManagerClient) SubmitJob(ctx context.Context, url string, jarID string, submitJobRequest client.SubmitJobRequest) (*client.SubmitJobResponse, error) {
@@ -46,6 +72,41 @@ func (m *JobManagerClient) CancelJobWithSavepoint(ctx context.Context, url strin
 	return "", nil
 }
 
+func (m *JobManagerClient) StopJobWithSavepoint(ctx context.Context, url string, jobID string, drain bool) (string, error) {
+	if m.StopJobWithSavepointFunc != nil {
+		return m.StopJobWithSavepointFunc(ctx, url, jobID, drain)
+	}
+	return "", nil
+}
+
+func (m *JobManagerClient) RescaleJob(ctx context.Context, url string, jobID string, parallelism int32) (string, error) {
+	if m.RescaleJobFunc != nil {
+		return m.RescaleJobFunc(ctx, url, jobID, parallelism)
+	}
+	return "", nil
+}
+
+func (m *JobManagerClient) CheckRescaleStatus(ctx context.Context, url string, jobID string, triggerID string) (*client.SavepointResponse, error) {
+	if m.CheckRescaleStatusFunc != nil {
+		return m.CheckRescaleStatusFunc(ctx, url, jobID, triggerID)
+	}
+	return nil, nil
+}
+
+func (m *JobManagerClient) DisposeSavepoint(ctx context.Context, url string, savepointPath string) (string, error) {
+	if m.DisposeSavepointFunc != nil {
+		return m.DisposeSavepointFunc(ctx, url, savepointPath)
+	}
+	return "", nil
+}
+
+func (m *JobManagerClient) CheckSavepointDisposalStatus(ctx context.Context, url string, triggerID string) (*client.SavepointResponse, error) {
+	if m.CheckSavepointDisposalStatusFunc != nil {
+		return m.CheckSavepointDisposalStatusFunc(ctx, url, triggerID)
+	}
+	return nil, nil
+}
+
 func (m *JobManagerClient) ForceCancelJob(ctx context.Context, url string, jobID string) error {
 	if m.ForceCancelJobFunc != nil {
 		return m.ForceCancelJobFunc(ctx, url, jobID)
@@ -108,3 +169,59 @@ func (m *JobManagerClient) GetJobOverview(ctx context.Context, url string, jobID
 	}
 	return nil, nil
 }
+
+func (m *JobManagerClient) GetVertexBackpressure(ctx context.Context, url string, jobID string, vertexID string) (*client.VertexBackpressureResponse, error) {
+	if m.GetVertexBackpressureFunc != nil {
+		return m.GetVertexBackpressureFunc(ctx, url, jobID, vertexID)
+	}
+	return nil, nil
+}
+
+func (m *JobManagerClient) GetJobMetrics(ctx context.Context, url string, jobID string, query client.MetricsQuery) ([]client.Metric, error) {
+	if m.GetJobMetricsFunc != nil {
+		return m.GetJobMetricsFunc(ctx, url, jobID, query)
+	}
+	return nil, nil
+}
+
+func (m *JobManagerClient) GetVertexMetrics(ctx context.Context, url string, jobID string, vertexID string, query client.MetricsQuery) ([]client.Metric, error) {
+	if m.GetVertexMetricsFunc != nil {
+		return m.GetVertexMetricsFunc(ctx, url, jobID, vertexID, query)
+	}
+	return nil, nil
+}
+
+func (m *JobManagerClient) GetTaskManagerMetrics(ctx context.Context, url string, taskManagerID string, query client.MetricsQuery) ([]client.Metric, error) {
+	if m.GetTaskManagerMetricsFunc != nil {
+		return m.GetTaskManagerMetricsFunc(ctx, url, taskManagerID, query)
+	}
+	return nil, nil
+}
+
+func (m *JobManagerClient) GetJobManagerMetrics(ctx context.Context, url string, metrics []string) ([]client.Metric, error) {
+	if m.GetJobManagerMetricsFunc != nil {
+		return m.GetJobManagerMetricsFunc(ctx, url, metrics)
+	}
+	return nil, nil
+}
+
+// Unless overridden, the mock uses itself for secured clusters so tests can share the same stubs
+func (m *JobManagerClient) WithSecurity(security client.RestSecurity) client.FlinkAPIInterface {
+	if m.WithSecurityFunc != nil {
+		return m.WithSecurityFunc(security)
+	}
+	return m
+}
+
+func (m *JobManagerClient) WithVersion(version client.FlinkVersion) client.FlinkAPIInterface {
+	if m.WithVersionFunc != nil {
+		return m.WithVersionFunc(version)
+	}
+	return m
+}
+
+func (m *JobManagerClient) ForgetJobManager(url string) {
+	if m.ForgetJobManagerFunc != nil {
+		m.ForgetJobManagerFunc(url)
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// A Flink release, identified by its major and minor version. The zero value represents an unknown version, for which
+// the client and config rendering fall back to the behavior of the oldest supported release.
+type FlinkVersion struct {
+	Major int
+	Minor int
+}
+
+var (
+	// The oldest release managed by the operator. Only 1.x releases are supported.
+	MinSupportedVersion = FlinkVersion{Major: 1, Minor: 7}
+
+	// Releases that changed the REST API or configuration in ways the operator needs to account for
+	StopWithSavepointVersion          = FlinkVersion{Major: 1, Minor: 9}
+	RescalingDisabledVersion          = FlinkVersion{Major: 1, Minor: 9}
+	TaskManagerMemoryModelVersion     = FlinkVersion{Major: 1, Minor: 10}
+	JobManagerMemoryModelVersion      = FlinkVersion{Major: 1, Minor: 11}
+	KubernetesHighAvailabilityVersion = FlinkVersion{Major: 1, Minor: 12}
+)
+
+// Matches versions like 1.8, 1.9.2 and 1.10-SNAPSHOT
+var flinkVersionRegex = regexp.MustCompile(`^(\d+)\.(\d+)(\.\d+)?(-.*)?$`)
+
+func ParseFlinkVersion(version string) (FlinkVersion, error) {
+	matches := flinkVersionRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return FlinkVersion{}, errors.Errorf("invalid flink version %q", version)
+	}
+
+	// the regex guarantees these are integers
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return FlinkVersion{Major: major, Minor: minor}, nil
+}
+
+func (v FlinkVersion) AtLeast(other FlinkVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+func (v FlinkVersion) IsSupported() bool {
+	return v.Major == MinSupportedVersion.Major && v.AtLeast(MinSupportedVersion)
+}
+
+// The rescaling REST API was disabled in 1.9 (FLINK-12312), so jobs on later versions can only be rescaled by taking a
+// savepoint and resubmitting them
+func (v FlinkVersion) SupportsRescaling() bool {
+	return !v.AtLeast(RescalingDisabledVersion)
+}
+
+func (v FlinkVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
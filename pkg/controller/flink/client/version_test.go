@@ -0,0 +1,41 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlinkVersion(t *testing.T) {
+	for version, expected := range map[string]FlinkVersion{
+		"1.8":           {Major: 1, Minor: 8},
+		"1.9.2":         {Major: 1, Minor: 9},
+		"1.10-SNAPSHOT": {Major: 1, Minor: 10},
+	} {
+		actual, err := ParseFlinkVersion(version)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	for _, version := range []string{"", "1", "latest", "v1.8"} {
+		_, err := ParseFlinkVersion(version)
+		assert.Error(t, err)
+	}
+}
+
+func TestFlinkVersionComparison(t *testing.T) {
+	assert.True(t, FlinkVersion{Major: 1, Minor: 11}.AtLeast(TaskManagerMemoryModelVersion))
+	assert.True(t, FlinkVersion{Major: 1, Minor: 10}.AtLeast(TaskManagerMemoryModelVersion))
+	assert.False(t, FlinkVersion{Major: 1, Minor: 9}.AtLeast(TaskManagerMemoryModelVersion))
+	assert.False(t, FlinkVersion{}.AtLeast(TaskManagerMemoryModelVersion))
+
+	assert.True(t, FlinkVersion{Major: 1, Minor: 7}.IsSupported())
+	assert.True(t, FlinkVersion{Major: 1, Minor: 11}.IsSupported())
+	assert.False(t, FlinkVersion{Major: 1, Minor: 6}.IsSupported())
+	assert.False(t, FlinkVersion{Major: 2, Minor: 0}.IsSupported())
+	assert.Equal(t, "1.10", TaskManagerMemoryModelVersion.String())
+
+	assert.True(t, FlinkVersion{Major: 1, Minor: 7}.SupportsRescaling())
+	assert.True(t, FlinkVersion{Major: 1, Minor: 8}.SupportsRescaling())
+	assert.False(t, FlinkVersion{Major: 1, Minor: 9}.SupportsRescaling())
+}
@@ -1,7 +1,12 @@
 package flink
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
 	"gopkg.in/yaml.v2"
 )
 
@@ -30,6 +35,126 @@ func getValidFraction(x *float64, y float64) float64 {
 	return y
 }
 
+// Returns the Flink version of the application. Versions that cannot be parsed are treated as the oldest supported
+// release; ValidateFlinkVersion rejects them before a cluster is created.
+func getFlinkVersion(app *v1alpha1.FlinkApplication) client.FlinkVersion {
+	version, err := client.ParseFlinkVersion(app.Spec.FlinkVersion)
+	if err != nil {
+		return client.MinSupportedVersion
+	}
+	return version
+}
+
+// Returns the Flink version of the application's cluster with the given hash. While an update is in progress the
+// deployed cluster still runs the version it was created with, which is recorded in the status.
+func getClusterFlinkVersion(app *v1alpha1.FlinkApplication, hash string) client.FlinkVersion {
+	if hash != "" && hash == app.Status.DeployHash && app.Status.DeployFlinkVersion != "" {
+		if version, err := client.ParseFlinkVersion(app.Status.DeployFlinkVersion); err == nil {
+			return version
+		}
+	}
+	return getFlinkVersion(app)
+}
+
+// Returns an error if the application's Flink version cannot be managed by the operator
+func ValidateFlinkVersion(app *v1alpha1.FlinkApplication) error {
+	version, err := client.ParseFlinkVersion(app.Spec.FlinkVersion)
+	if err != nil {
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "%v", err)
+	}
+	if !version.IsSupported() {
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "unsupported flink version %s: must be a %d.x release no older than %s",
+			app.Spec.FlinkVersion, client.MinSupportedVersion.Major, client.MinSupportedVersion)
+	}
+	return nil
+}
+
+// Returns how the application's job is stopped when taking a final savepoint. Jobs are cancelled with a savepoint
+// unless another mode is set.
+func GetStopMode(app *v1alpha1.FlinkApplication) v1alpha1.StopMode {
+	if app.Spec.StopMode != "" {
+		return app.Spec.StopMode
+	}
+	return v1alpha1.StopModeCancel
+}
+
+// Returns an error if the application's stop mode is unknown or not supported by the Flink version of its cluster with
+// the given hash, which is the cluster whose job will be stopped
+func ValidateStopMode(app *v1alpha1.FlinkApplication, hash string) error {
+	switch app.Spec.StopMode {
+	case "", v1alpha1.StopModeCancel:
+		return nil
+	case v1alpha1.StopModeStop, v1alpha1.StopModeDrain:
+		version := getClusterFlinkVersion(app, hash)
+		if !version.AtLeast(client.StopWithSavepointVersion) {
+			return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "stop mode %s requires flink %s or later, but the cluster runs %s",
+				app.Spec.StopMode, client.StopWithSavepointVersion, version)
+		}
+		return nil
+	default:
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "unknown stop mode %s: must be one of %s, %s or %s", app.Spec.StopMode,
+			v1alpha1.StopModeCancel, v1alpha1.StopModeStop, v1alpha1.StopModeDrain)
+	}
+}
+
+// Returns an error if the application's config mode is unknown, or if its logging config would replace flink-conf.yaml
+func ValidateConfigMode(app *v1alpha1.FlinkApplication) error {
+	switch app.Spec.ConfigMode {
+	case "", v1alpha1.ConfigModeEnv, v1alpha1.ConfigModeConfigMap:
+	default:
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "unknown config mode %s: must be one of %s or %s",
+			app.Spec.ConfigMode, v1alpha1.ConfigModeEnv, v1alpha1.ConfigModeConfigMap)
+	}
+	if _, ok := app.Spec.LoggingConfig[FlinkConfigFileName]; ok {
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "loggingConfig must not contain %s", FlinkConfigFileName)
+	}
+	return nil
+}
+
+// Returns an error if the application's high availability config is incomplete or not supported by its Flink version
+func ValidateHighAvailability(app *v1alpha1.FlinkApplication) error {
+	ha := app.Spec.JobManagerConfig.HighAvailability
+	if ha == nil {
+		return nil
+	}
+	if ha.StorageDir == "" {
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "highAvailability.storageDir is required")
+	}
+	switch ha.Mode {
+	case v1alpha1.HighAvailabilityModeZooKeeper:
+		if ha.ZookeeperQuorum == "" {
+			return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "highAvailability.zookeeperQuorum is required in %s mode", ha.Mode)
+		}
+		return nil
+	case v1alpha1.HighAvailabilityModeKubernetes:
+		if !getFlinkVersion(app).AtLeast(client.KubernetesHighAvailabilityVersion) {
+			return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "high availability mode %s requires flink %s or later", ha.Mode, client.KubernetesHighAvailabilityVersion)
+		}
+		return nil
+	default:
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "unknown high availability mode %s: must be one of %s or %s", ha.Mode,
+			v1alpha1.HighAvailabilityModeZooKeeper, v1alpha1.HighAvailabilityModeKubernetes)
+	}
+}
+
+func ValidateIngress(app *v1alpha1.FlinkApplication) error {
+	path := getIngressConfig(app).Path
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "invalid ingress path %s: must start with /", path)
+	}
+	return nil
+}
+
+// Returns true if the application's job can be rescaled in place using the rescaling REST API
+func SupportsRescaling(app *v1alpha1.FlinkApplication) bool {
+	return getFlinkVersion(app).SupportsRescaling()
+}
+
+// Formats a size in bytes using Flink's memory size syntax
+func formatMemorySize(bytes int64) string {
+	return fmt.Sprintf("%dm", bytes/(1024*1024))
+}
+
 func getTaskmanagerSlots(app *v1alpha1.FlinkApplication) int32 {
 	return firstNonNil(app.Spec.TaskManagerConfig.TaskSlots, TaskManagerDefaultSlots)
 }
@@ -92,6 +217,44 @@ func getJobManagerHeapMemory(app *v1alpha1.FlinkApplication) float64 {
 	return heapMemoryMB
 }
 
+// Returns an error if the application's memory model is unknown or not supported by its Flink version
+func ValidateMemoryModel(app *v1alpha1.FlinkApplication) error {
+	switch app.Spec.MemoryModel {
+	case "", v1alpha1.MemoryModelHeap:
+		return nil
+	case v1alpha1.MemoryModelProcess:
+		if !getFlinkVersion(app).AtLeast(client.TaskManagerMemoryModelVersion) {
+			return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "memory model %s requires flink %s or later",
+				app.Spec.MemoryModel, client.TaskManagerMemoryModelVersion)
+		}
+		return nil
+	default:
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "unknown memory model %s: must be one of %s or %s",
+			app.Spec.MemoryModel, v1alpha1.MemoryModelHeap, v1alpha1.MemoryModelProcess)
+	}
+}
+
+// Flink 1.10 and 1.11 replaced the heap size settings with a memory model that sizes the whole process. Applications
+// that opt into it get the container memory as the process size on the versions that support it, and the off-heap
+// fraction does not apply. Other applications keep the heap size settings, which newer versions still accept, so that
+// their configuration does not change when the operator is upgraded.
+func addMemoryConfig(app *v1alpha1.FlinkApplication, config *v1alpha1.FlinkConfig) {
+	processModel := app.Spec.MemoryModel == v1alpha1.MemoryModelProcess
+	version := getFlinkVersion(app)
+
+	if processModel && version.AtLeast(client.JobManagerMemoryModelVersion) {
+		(*config)["jobmanager.memory.process.size"] = formatMemorySize(getJobManagerMemory(app))
+	} else {
+		(*config)["jobmanager.heap.size"] = getJobManagerHeapMemory(app)
+	}
+
+	if processModel && version.AtLeast(client.TaskManagerMemoryModelVersion) {
+		(*config)["taskmanager.memory.process.size"] = formatMemorySize(getTaskManagerMemory(app))
+	} else {
+		(*config)["taskmanager.heap.size"] = getTaskManagerHeapMemory(app)
+	}
+}
+
 // Renders the flink configuration overrides stored in FlinkApplication.FlinkConfig into a
 // YAML string suitable for interpolating into flink-conf.yaml.
 func renderFlinkConfig(app *v1alpha1.FlinkApplication) (string, error) {
@@ -109,8 +272,9 @@ func renderFlinkConfig(app *v1alpha1.FlinkApplication) (string, error) {
 	(*config)["query.server.port"] = getQueryPort(app)
 	(*config)["blob.server.port"] = getBlobPort(app)
 	(*config)["metrics.internal.query-service.port"] = getInternalMetricsQueryPort(app)
-	(*config)["jobmanager.heap.size"] = getJobManagerHeapMemory(app)
-	(*config)["taskmanager.heap.size"] = getTaskManagerHeapMemory(app)
+	addMemoryConfig(app, config)
+	addRestSecurityConfig(app, config)
+	addHighAvailabilityConfig(app, config)
 
 	b, err := yaml.Marshal(config)
 	if err != nil {
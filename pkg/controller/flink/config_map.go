@@ -0,0 +1,178 @@
+package flink
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ConfigMapNameFormat   = "%s-%s-config"
+	FlinkConfigVolumeName = "flink-config"
+	FlinkConfigMountPath  = "/etc/flink/conf"
+	FlinkConfigDirEnvVar  = "FLINK_CONF_DIR"
+	FlinkConfigFileName   = "flink-conf.yaml"
+	FlinkConfigChecksum   = "flink-config-checksum"
+)
+
+func isConfigMapModeEnabled(app *v1alpha1.FlinkApplication) bool {
+	return app.Spec.ConfigMode == v1alpha1.ConfigModeConfigMap
+}
+
+func getConfigMapName(app *v1alpha1.FlinkApplication, hash string) string {
+	return fmt.Sprintf(ConfigMapNameFormat, app.Name, hash)
+}
+
+// Appends the configuration that depends on the version of the cluster, which is only known once the application has
+// been hashed
+func appendHashesToConfig(flinkConfig string, app *v1alpha1.FlinkApplication, hash string) string {
+	clusterID := getHighAvailabilityClusterID(app, hash)
+	flinkConfig = fmt.Sprintf("%s\nhigh-availability.cluster-id: %s\n", flinkConfig, clusterID)
+	if isKubernetesHighAvailabilityEnabled(app) {
+		flinkConfig = fmt.Sprintf("%skubernetes.cluster-id: %s\n", flinkConfig, clusterID)
+	}
+	// with HA, each JobManager binds to the address of its pod and the TaskManagers find the leader through the HA
+	// services, so the address must not point at the service
+	if isHighAvailabilityEnabled(app) {
+		return flinkConfig
+	}
+	return fmt.Sprintf("%sjobmanager.rpc.address: %s\n", flinkConfig, VersionedJobManagerService(app, hash))
+}
+
+// Returns the arguments of a JobManager or TaskManager container. In the ConfigMap mode, the entrypoint cannot append
+// the configuration that is only known inside the pod to the read-only flink-conf.yaml, so it is passed as dynamic
+// properties instead, which Flink applies on top of flink-conf.yaml. podConfig maps each of those keys to the
+// environment variable holding its value, which Kubernetes substitutes into the arguments.
+func getContainerArgs(app *v1alpha1.FlinkApplication, command string, podConfig map[string]string) []string {
+	args := []string{command}
+	if !isConfigMapModeEnabled(app) {
+		return args
+	}
+
+	keys := make([]string, 0, len(podConfig))
+	for key := range podConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("-D%s=$(%s)", key, podConfig[key]))
+	}
+	return args
+}
+
+// Returns a checksum of the configuration rendered into the ConfigMap, excluding the parts that depend on the hash.
+// It is added to the pod annotations so that changes to the configuration change the hash of the application.
+func getFlinkConfigChecksum(app *v1alpha1.FlinkApplication) (string, error) {
+	flinkConfig, err := renderFlinkConfig(app)
+	if err != nil {
+		return "", err
+	}
+
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(flinkConfig))
+	fileNames := make([]string, 0, len(app.Spec.LoggingConfig))
+	for fileName := range app.Spec.LoggingConfig {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		_, _ = hasher.Write([]byte(fileName))
+		_, _ = hasher.Write([]byte(app.Spec.LoggingConfig[fileName]))
+	}
+	return fmt.Sprintf("%08x", hasher.Sum32()), nil
+}
+
+// Returns the annotations for the application's pods. Applications using the environment variable config mode get the
+// application's annotations as before, so that their hash is unchanged.
+func getPodAnnotations(app *v1alpha1.FlinkApplication) map[string]string {
+	if !isConfigMapModeEnabled(app) {
+		return getAppAnnotations(app)
+	}
+
+	annotations := common.DuplicateMap(getAppAnnotations(app))
+	checksum, err := getFlinkConfigChecksum(app)
+	if err == nil {
+		annotations[FlinkConfigChecksum] = checksum
+	}
+	return annotations
+}
+
+// The ConfigMap is versioned along with the cluster; InjectHashesIntoConfig points the volume at the ConfigMap for
+// the cluster's hash
+func getFlinkConfigVolumes(app *v1alpha1.FlinkApplication) []coreV1.Volume {
+	if !isConfigMapModeEnabled(app) {
+		return nil
+	}
+
+	return []coreV1.Volume{{
+		Name: FlinkConfigVolumeName,
+		VolumeSource: coreV1.VolumeSource{
+			ConfigMap: &coreV1.ConfigMapVolumeSource{
+				LocalObjectReference: coreV1.LocalObjectReference{
+					Name: getConfigMapName(app, ""),
+				},
+			},
+		},
+	}}
+}
+
+func getFlinkConfigVolumeMounts(app *v1alpha1.FlinkApplication) []coreV1.VolumeMount {
+	if !isConfigMapModeEnabled(app) {
+		return nil
+	}
+
+	return []coreV1.VolumeMount{{
+		Name:      FlinkConfigVolumeName,
+		MountPath: FlinkConfigMountPath,
+		ReadOnly:  true,
+	}}
+}
+
+func FetchConfigMapCreateObj(app *v1alpha1.FlinkApplication, hash string) (*coreV1.ConfigMap, error) {
+	flinkConfig, err := renderFlinkConfig(app)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to serialize flink configuration")
+	}
+
+	data := common.DuplicateMap(app.Spec.LoggingConfig)
+	data[FlinkConfigFileName] = appendHashesToConfig(flinkConfig, app, hash)
+
+	labels := getCommonAppLabels(app)
+	labels[FlinkAppHash] = hash
+
+	return &coreV1.ConfigMap{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       k8.ConfigMap,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      getConfigMapName(app, hash),
+			Namespace: app.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metaV1.OwnerReference{
+				*metaV1.NewControllerRef(app, app.GroupVersionKind()),
+			},
+		},
+		Data: data,
+	}, nil
+}
+
+func FetchConfigMapDeleteObj(app *v1alpha1.FlinkApplication, hash string) *coreV1.ConfigMap {
+	return &coreV1.ConfigMap{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       k8.ConfigMap,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      getConfigMapName(app, hash),
+			Namespace: app.Namespace,
+		},
+	}
+}
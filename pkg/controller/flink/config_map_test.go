@@ -0,0 +1,102 @@
+package flink
+
+import (
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func getConfigMapTestApp() v1alpha1.FlinkApplication {
+	app := getFlinkTestApp()
+	app.Spec.ConfigMode = v1alpha1.ConfigModeConfigMap
+	app.Spec.LoggingConfig = map[string]string{
+		"log4j-console.properties": "log4j.rootLogger=INFO, console",
+	}
+	return app
+}
+
+func TestFetchConfigMapCreateObj(t *testing.T) {
+	app := getConfigMapTestApp()
+	hash := HashForApplication(&app)
+
+	configMap, err := FetchConfigMapCreateObj(&app, hash)
+	assert.Nil(t, err)
+	assert.Equal(t, app.Name+"-"+hash+"-config", configMap.Name)
+	assert.Equal(t, app.Namespace, configMap.Namespace)
+	assert.Equal(t, app.Name, configMap.OwnerReferences[0].Name)
+	assert.Equal(t, hash, configMap.Labels[FlinkAppHash])
+	assert.Equal(t, "log4j.rootLogger=INFO, console", configMap.Data["log4j-console.properties"])
+	assert.Contains(t, configMap.Data[FlinkConfigFileName], "taskmanager.numberOfTaskSlots: 16\n")
+	assert.Contains(t, configMap.Data[FlinkConfigFileName],
+		"high-availability.cluster-id: "+app.Name+"-"+hash+"\njobmanager.rpc.address: "+app.Name+"-"+hash+"\n")
+}
+
+func TestConfigMapModeDeployment(t *testing.T) {
+	app := getConfigMapTestApp()
+	hash := HashForApplication(&app)
+
+	deployment := FetchJobMangerDeploymentCreateObj(&app, hash)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Nil(t, common.GetEnvVar(container.Env, OperatorFlinkConfig))
+	assert.Equal(t, FlinkConfigMountPath, common.GetEnvVar(container.Env, FlinkConfigDirEnvVar).Value)
+
+	volume := deployment.Spec.Template.Spec.Volumes[0]
+	assert.Equal(t, FlinkConfigVolumeName, volume.Name)
+	assert.Equal(t, getConfigMapName(&app, hash), volume.ConfigMap.Name)
+	assert.Equal(t, FlinkConfigMountPath, container.VolumeMounts[0].MountPath)
+	assert.NotEmpty(t, deployment.Spec.Template.Annotations[FlinkConfigChecksum])
+	assert.True(t, JobManagerDeploymentMatches(deployment, &app))
+	assert.Equal(t, []string{JobManagerArg}, container.Args)
+}
+
+func TestConfigMapModePodConfig(t *testing.T) {
+	app := getConfigMapTestApp()
+	app.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{
+		TLSEnabled:    true,
+		TLSSecretName: "flink-tls",
+	}
+	hash := HashForApplication(&app)
+
+	// the configuration that is only known inside the pods is passed as dynamic properties
+	jmContainer := FetchJobMangerDeploymentCreateObj(&app, hash).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{
+		JobManagerArg,
+		"-Dsecurity.ssl.rest.key-password=$(FLINK_REST_SSL_KEY_PASSWORD)",
+		"-Dsecurity.ssl.rest.keystore-password=$(FLINK_REST_SSL_KEYSTORE_PASSWORD)",
+		"-Dsecurity.ssl.rest.truststore-password=$(FLINK_REST_SSL_TRUSTSTORE_PASSWORD)",
+	}, jmContainer.Args)
+	assert.NotNil(t, common.GetEnvVar(jmContainer.Env, RestKeystorePasswordEnvVar))
+
+	tmContainer := FetchTaskMangerDeploymentCreateObj(&app, hash).Spec.Template.Spec.Containers[0]
+	assert.Contains(t, tmContainer.Args, "-Dtaskmanager.host=$(TASKMANAGER_HOSTNAME)")
+	assert.Equal(t, TaskManagerArg, tmContainer.Args[0])
+	assert.Equal(t, 5, len(tmContainer.Args))
+
+	// the entrypoint appends them to flink-conf.yaml in the environment variable config mode
+	app.Spec.ConfigMode = v1alpha1.ConfigModeEnv
+	tmContainer = FetchTaskMangerDeploymentCreateObj(&app, hash).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{TaskManagerArg}, tmContainer.Args)
+}
+
+func TestConfigMapModeChangeDetection(t *testing.T) {
+	app := getConfigMapTestApp()
+	hash := HashForApplication(&app)
+	deployment := FetchTaskMangerDeploymentCreateObj(&app, hash)
+
+	// changes to the logging config are only visible in the ConfigMap, but still change the hash
+	app.Spec.LoggingConfig["log4j-console.properties"] = "log4j.rootLogger=DEBUG, console"
+	assert.NotEqual(t, hash, HashForApplication(&app))
+	assert.False(t, TaskManagerDeploymentMatches(deployment, &app))
+
+	// as do changes to the Flink configuration
+	app = getConfigMapTestApp()
+	app.Spec.FlinkConfig = v1alpha1.FlinkConfig{"state.backend": "rocksdb"}
+	assert.NotEqual(t, hash, HashForApplication(&app))
+
+	// applications using the environment variable config mode hash as before
+	app = getFlinkTestApp()
+	app.Spec.LoggingConfig = map[string]string{"log4j-console.properties": "log4j.rootLogger=INFO, console"}
+	assert.Equal(t, testAppHash, HashForApplication(&app))
+}
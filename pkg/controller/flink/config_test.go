@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
 	"github.com/stretchr/testify/assert"
 	coreV1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -189,3 +190,175 @@ func TestInvalidMemoryFraction(t *testing.T) {
 	assert.Equal(t, expectedjmHeapMemoryMB, getJobManagerHeapMemory(&app))
 
 }
+
+func TestRenderFlinkConfigRestSecurity(t *testing.T) {
+	yaml, err := renderFlinkConfig(&v1alpha1.FlinkApplication{
+		Spec: v1alpha1.FlinkApplicationSpec{
+			RestSecurity: &v1alpha1.RestSecurityConfig{
+				TLSEnabled:    true,
+				MutualTLS:     true,
+				TLSSecretName: "flink-tls",
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(yaml), "\n")
+	assert.Contains(t, lines, "security.ssl.rest.enabled: true")
+	assert.Contains(t, lines, "security.ssl.rest.authentication-enabled: true")
+	assert.Contains(t, lines, "security.ssl.rest.keystore: /etc/flink/rest-tls/keystore.jks")
+	assert.Contains(t, lines, "security.ssl.rest.truststore: /etc/flink/rest-tls/truststore.jks")
+}
+
+func TestRenderFlinkConfigMemoryModel(t *testing.T) {
+	for version, expected := range map[string][]string{
+		"1.9":  {"jobmanager.heap.size: 1536", "taskmanager.heap.size: 512"},
+		"1.10": {"jobmanager.heap.size: 1536", "taskmanager.memory.process.size: 1024m"},
+		"1.11": {"jobmanager.memory.process.size: 3072m", "taskmanager.memory.process.size: 1024m"},
+	} {
+		app := v1alpha1.FlinkApplication{
+			Spec: v1alpha1.FlinkApplicationSpec{
+				FlinkVersion: version,
+			},
+		}
+
+		// applications that do not opt into the process memory model keep the heap size settings
+		yaml, err := renderFlinkConfig(&app)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(yaml), "\n")
+		assert.Contains(t, lines, "jobmanager.heap.size: 1536", version)
+		assert.Contains(t, lines, "taskmanager.heap.size: 512", version)
+		assert.Equal(t, 8, len(lines), version)
+
+		app.Spec.MemoryModel = v1alpha1.MemoryModelProcess
+		yaml, err = renderFlinkConfig(&app)
+		assert.NoError(t, err)
+		lines = strings.Split(strings.TrimSpace(yaml), "\n")
+		for _, line := range expected {
+			assert.Contains(t, lines, line, version)
+		}
+		assert.Equal(t, 8, len(lines), version)
+	}
+}
+
+func TestValidateMemoryModel(t *testing.T) {
+	app := v1alpha1.FlinkApplication{}
+	app.Spec.FlinkVersion = "1.9"
+	assert.NoError(t, ValidateMemoryModel(&app))
+
+	app.Spec.MemoryModel = v1alpha1.MemoryModelProcess
+	assert.EqualError(t, ValidateMemoryModel(&app), "ErrorCode: [BadJobSpecificationError] Reason: [memory model Process requires flink 1.10 or later]")
+
+	app.Spec.FlinkVersion = "1.10"
+	assert.NoError(t, ValidateMemoryModel(&app))
+
+	app.Spec.MemoryModel = "Managed"
+	assert.EqualError(t, ValidateMemoryModel(&app), "ErrorCode: [BadJobSpecificationError] Reason: [unknown memory model Managed: must be one of Heap or Process]")
+}
+
+func TestValidateFlinkVersion(t *testing.T) {
+	app := v1alpha1.FlinkApplication{}
+	app.Spec.FlinkVersion = "1.8"
+	assert.NoError(t, ValidateFlinkVersion(&app))
+
+	app.Spec.FlinkVersion = "latest"
+	assert.EqualError(t, ValidateFlinkVersion(&app), "ErrorCode: [BadJobSpecificationError] Reason: [invalid flink version \"latest\"]")
+
+	app.Spec.FlinkVersion = "2.0"
+	assert.EqualError(t, ValidateFlinkVersion(&app), "ErrorCode: [BadJobSpecificationError] Reason: [unsupported flink version 2.0: must be a 1.x release no older than 1.7]")
+	assert.True(t, flinkErrors.IsUserError(ValidateFlinkVersion(&app)))
+}
+
+func TestGetClusterFlinkVersion(t *testing.T) {
+	app := v1alpha1.FlinkApplication{}
+	app.Spec.FlinkVersion = "1.9"
+	app.Status.DeployHash = "old"
+	assert.Equal(t, "1.9", getClusterFlinkVersion(&app, "old").String())
+
+	// the deployed cluster keeps the version it was created with
+	app.Status.DeployFlinkVersion = "1.8"
+	assert.Equal(t, "1.8", getClusterFlinkVersion(&app, "old").String())
+	assert.Equal(t, "1.9", getClusterFlinkVersion(&app, "new").String())
+}
+
+func TestValidateStopMode(t *testing.T) {
+	app := v1alpha1.FlinkApplication{}
+	app.Spec.FlinkVersion = "1.8"
+	assert.NoError(t, ValidateStopMode(&app, ""))
+	assert.Equal(t, v1alpha1.StopModeCancel, GetStopMode(&app))
+
+	app.Spec.StopMode = v1alpha1.StopModeDrain
+	assert.EqualError(t, ValidateStopMode(&app, ""), "ErrorCode: [BadJobSpecificationError] Reason: [stop mode Drain requires flink 1.9 or later, but the cluster runs 1.8]")
+
+	app.Spec.FlinkVersion = "1.9"
+	assert.NoError(t, ValidateStopMode(&app, ""))
+	assert.Equal(t, v1alpha1.StopModeDrain, GetStopMode(&app))
+
+	// jobs keep being cancelled unless another mode is set
+	app.Spec.StopMode = ""
+	assert.Equal(t, v1alpha1.StopModeCancel, GetStopMode(&app))
+
+	// the mode is validated against the version of the cluster whose job is stopped
+	app.Spec.StopMode = v1alpha1.StopModeStop
+	app.Status.DeployHash = "old"
+	app.Status.DeployFlinkVersion = "1.8"
+	assert.EqualError(t, ValidateStopMode(&app, "old"), "ErrorCode: [BadJobSpecificationError] Reason: [stop mode Stop requires flink 1.9 or later, but the cluster runs 1.8]")
+	assert.NoError(t, ValidateStopMode(&app, "new"))
+
+	app.Spec.StopMode = "Suspend"
+	assert.EqualError(t, ValidateStopMode(&app, ""), "ErrorCode: [BadJobSpecificationError] Reason: [unknown stop mode Suspend: must be one of Cancel, Stop or Drain]")
+}
+
+func TestValidateConfigMode(t *testing.T) {
+	app := v1alpha1.FlinkApplication{}
+	assert.NoError(t, ValidateConfigMode(&app))
+
+	app.Spec.ConfigMode = v1alpha1.ConfigModeConfigMap
+	app.Spec.LoggingConfig = map[string]string{"log4j-console.properties": ""}
+	assert.NoError(t, ValidateConfigMode(&app))
+
+	app.Spec.LoggingConfig[FlinkConfigFileName] = ""
+	assert.EqualError(t, ValidateConfigMode(&app), "ErrorCode: [BadJobSpecificationError] Reason: [loggingConfig must not contain flink-conf.yaml]")
+
+	app.Spec.ConfigMode = "File"
+	assert.EqualError(t, ValidateConfigMode(&app), "ErrorCode: [BadJobSpecificationError] Reason: [unknown config mode File: must be one of Env or ConfigMap]")
+}
+
+func TestValidateHighAvailability(t *testing.T) {
+	app := v1alpha1.FlinkApplication{}
+	app.Spec.FlinkVersion = "1.11"
+	assert.NoError(t, ValidateHighAvailability(&app))
+
+	app.Spec.JobManagerConfig.HighAvailability = &v1alpha1.HighAvailabilityConfig{
+		Mode: v1alpha1.HighAvailabilityModeZooKeeper,
+	}
+	assert.EqualError(t, ValidateHighAvailability(&app), "ErrorCode: [BadJobSpecificationError] Reason: [highAvailability.storageDir is required]")
+
+	app.Spec.JobManagerConfig.HighAvailability.StorageDir = "s3://bucket/flink/ha"
+	assert.EqualError(t, ValidateHighAvailability(&app), "ErrorCode: [BadJobSpecificationError] Reason: [highAvailability.zookeeperQuorum is required in ZooKeeper mode]")
+
+	app.Spec.JobManagerConfig.HighAvailability.ZookeeperQuorum = "zk-0.zk:2181"
+	assert.NoError(t, ValidateHighAvailability(&app))
+
+	app.Spec.JobManagerConfig.HighAvailability.Mode = v1alpha1.HighAvailabilityModeKubernetes
+	err := ValidateHighAvailability(&app)
+	assert.EqualError(t, err, "ErrorCode: [BadJobSpecificationError] Reason: [high availability mode Kubernetes requires flink 1.12 or later]")
+	assert.True(t, flinkErrors.IsUserError(err))
+
+	app.Spec.FlinkVersion = "1.12"
+	assert.NoError(t, ValidateHighAvailability(&app))
+
+	app.Spec.JobManagerConfig.HighAvailability.Mode = "Etcd"
+	assert.EqualError(t, ValidateHighAvailability(&app), "ErrorCode: [BadJobSpecificationError] Reason: [unknown high availability mode Etcd: must be one of ZooKeeper or Kubernetes]")
+}
+
+func TestValidateIngress(t *testing.T) {
+	app := getFlinkTestApp()
+	assert.Nil(t, ValidateIngress(&app))
+
+	app.Spec.Ingress = &v1alpha1.IngressConfig{Path: "/flink"}
+	assert.Nil(t, ValidateIngress(&app))
+
+	app.Spec.Ingress.Path = "flink"
+	assert.EqualError(t, ValidateIngress(&app), "ErrorCode: [BadJobSpecificationError] Reason: [invalid ingress path flink: must start with /]")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
 	"github.com/lyft/flinkk8soperator/pkg/controller/config"
 	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/lyft/flinkk8soperator/pkg/controller/sharding"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -45,8 +46,19 @@ func getCommonAppLabels(app *v1alpha1.FlinkApplication) map[string]string {
 	return k8.GetAppLabel(app.Name)
 }
 
-func getCommonAnnotations(app *v1alpha1.FlinkApplication) map[string]string {
+// Returns the annotations of the application that are copied to its clusters. Annotations that the operator sets on
+// the application are left out, so that they do not change the hash of the application.
+func getAppAnnotations(app *v1alpha1.FlinkApplication) map[string]string {
+	if _, ok := app.Annotations[sharding.ShardAnnotation]; !ok {
+		return app.Annotations
+	}
 	annotations := common.DuplicateMap(app.Annotations)
+	delete(annotations, sharding.ShardAnnotation)
+	return annotations
+}
+
+func getCommonAnnotations(app *v1alpha1.FlinkApplication) map[string]string {
+	annotations := common.DuplicateMap(getAppAnnotations(app))
 	annotations[FlinkJobProperties] = fmt.Sprintf(
 		"jarName: %s\nparallelism: %d\nentryClass:%s\nprogramArgs:\"%s\"",
 		app.Spec.JarName, app.Spec.Parallelism, app.Spec.EntryClass, app.Spec.ProgramArgs)
@@ -73,6 +85,20 @@ func getFlinkEnv(app *v1alpha1.FlinkApplication) ([]v1.EnvVar, error) {
 	env := []v1.EnvVar{}
 	appName := app.Name
 
+	// the configuration is read from the mounted ConfigMap instead
+	if isConfigMapModeEnabled(app) {
+		return append(env, []v1.EnvVar{
+			{
+				Name:  AppName,
+				Value: appName,
+			},
+			{
+				Name:  FlinkConfigDirEnvVar,
+				Value: FlinkConfigMountPath,
+			},
+		}...), nil
+	}
+
 	flinkConfig, err := renderFlinkConfig(app)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to serialize flink configuration")
@@ -98,6 +124,7 @@ func GetFlinkContainerEnv(app *v1alpha1.FlinkApplication) []v1.EnvVar {
 	if err == nil {
 		env = append(env, flinkEnv...)
 	}
+	env = append(env, getRestSecurityEnv(app)...)
 	return env
 }
 
@@ -134,19 +161,36 @@ func HashForApplication(app *v1alpha1.FlinkApplication) string {
 		panic("failed to unmarshal deployment")
 	}
 
-	tmDeployment := taskmanagerTemplate(app)
-	tmDeployment.OwnerReferences = make([]metav1.OwnerReference, 0)
-	tm, err := json.Marshal(tmDeployment)
-	if err != nil {
-		panic("failed to marshal deployment")
-	}
-	err = json.Unmarshal(tm, &tmDeployment)
-	if err != nil {
-		panic("failed to unmarshal deployment")
+	var tmObject interface{}
+	if isStatefulSetEnabled(app) {
+		// TaskManagers running as a StatefulSet are hashed as such, so that the hash of other applications is unchanged
+		tmStatefulSet := taskmanagerStatefulSetTemplate(app)
+		tmStatefulSet.OwnerReferences = make([]metav1.OwnerReference, 0)
+		tm, err := json.Marshal(tmStatefulSet)
+		if err != nil {
+			panic("failed to marshal stateful set")
+		}
+		err = json.Unmarshal(tm, &tmStatefulSet)
+		if err != nil {
+			panic("failed to unmarshal stateful set")
+		}
+		tmObject = tmStatefulSet
+	} else {
+		tmDeployment := taskmanagerTemplate(app)
+		tmDeployment.OwnerReferences = make([]metav1.OwnerReference, 0)
+		tm, err := json.Marshal(tmDeployment)
+		if err != nil {
+			panic("failed to marshal deployment")
+		}
+		err = json.Unmarshal(tm, &tmDeployment)
+		if err != nil {
+			panic("failed to unmarshal deployment")
+		}
+		tmObject = tmDeployment
 	}
 
 	hasher := fnv.New32a()
-	_, err = printer.Fprintf(hasher, "%#v%#v", jmDeployment, tmDeployment)
+	_, err = printer.Fprintf(hasher, "%#v%#v", jmDeployment, tmObject)
 	if err != nil {
 		// the hasher cannot actually throw an error on write
 		panic(fmt.Sprintf("got error trying when writing to hash %v", err))
@@ -155,6 +199,19 @@ func HashForApplication(app *v1alpha1.FlinkApplication) string {
 	return fmt.Sprintf("%08x", hasher.Sum32())
 }
 
+// Returns true if the application differs from its running job only in its parallelism, i.e., if the application
+// with the job's current parallelism hashes to the deployed cluster (or to the application as last rescaled in place)
+func IsParallelismOnlyChange(app *v1alpha1.FlinkApplication) bool {
+	if app.Spec.Parallelism == app.Status.JobStatus.Parallelism || app.Status.DeployHash == "" {
+		return false
+	}
+
+	running := app.DeepCopy()
+	running.Spec.Parallelism = app.Status.JobStatus.Parallelism
+	hash := HashForApplication(running)
+	return hash == app.Status.DeployHash || hash == app.Status.RescaledHash
+}
+
 func GetAppHashSelector(app *v1alpha1.FlinkApplication) map[string]string {
 	return GetAppHashSelectorWithHash(HashForApplication(app))
 }
@@ -166,20 +223,32 @@ func GetAppHashSelectorWithHash(hash string) map[string]string {
 }
 
 func InjectHashesIntoConfig(deployment *appsv1.Deployment, app *v1alpha1.FlinkApplication, hash string) {
+	injectHashesIntoPodSpec(&deployment.Spec.Template.Spec, app, hash)
+}
+
+func injectHashesIntoPodSpec(podSpec *v1.PodSpec, app *v1alpha1.FlinkApplication, hash string) {
 	var newContainers []v1.Container
-	for _, container := range deployment.Spec.Template.Spec.Containers {
+	for _, container := range podSpec.Containers {
 		var newEnv []v1.EnvVar
 		for _, env := range container.Env {
 			if env.Name == OperatorFlinkConfig {
-				env.Value = fmt.Sprintf("%s\nhigh-availability.cluster-id: %s-%s\n", env.Value, app.Name, hash)
-				env.Value = fmt.Sprintf("%sjobmanager.rpc.address: %s\n", env.Value, VersionedJobManagerService(app, hash))
+				env.Value = appendHashesToConfig(env.Value, app, hash)
 			}
 			newEnv = append(newEnv, env)
 		}
 		container.Env = newEnv
 		newContainers = append(newContainers, container)
 	}
-	deployment.Spec.Template.Spec.Containers = newContainers
+	podSpec.Containers = newContainers
+
+	if !isConfigMapModeEnabled(app) {
+		return
+	}
+	for i, volume := range podSpec.Volumes {
+		if volume.Name == FlinkConfigVolumeName && volume.ConfigMap != nil {
+			podSpec.Volumes[i].ConfigMap.Name = getConfigMapName(app, hash)
+		}
+	}
 }
 
 func envsEqual(a []v1.EnvVar, b []v1.EnvVar) bool {
@@ -240,5 +309,8 @@ func DeploymentsEqual(a *appsv1.Deployment, b *appsv1.Deployment) bool {
 	if a.Annotations[RestartNonce] != b.Annotations[RestartNonce] {
 		return false
 	}
+	if a.Spec.Template.Annotations[FlinkConfigChecksum] != b.Spec.Template.Annotations[FlinkConfigChecksum] {
+		return false
+	}
 	return true
 }
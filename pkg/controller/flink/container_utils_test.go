@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/sharding"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -46,6 +47,36 @@ func TestHashForApplication(t *testing.T) {
 	app.Spec.Parallelism = 7
 	h6 := HashForApplication(&app)
 	assert.NotEqual(t, h5, h6)
+
+	// the shard that the application is claimed by is not part of it
+	app.Annotations[sharding.ShardAnnotation] = "1/4"
+	h7 := HashForApplication(&app)
+	assert.Equal(t, h6, h7)
+}
+
+func TestIsParallelismOnlyChange(t *testing.T) {
+	app := v1alpha1.FlinkApplication{}
+	app.Name = "app-name"
+	app.Spec.Image = "abcdef"
+	app.Spec.Parallelism = 4
+	app.Status.DeployHash = HashForApplication(&app)
+	app.Status.JobStatus.Parallelism = 4
+	assert.False(t, IsParallelismOnlyChange(&app))
+
+	app.Spec.Parallelism = 6
+	assert.True(t, IsParallelismOnlyChange(&app))
+
+	app.Spec.Image = "zxy"
+	assert.False(t, IsParallelismOnlyChange(&app))
+
+	// after rescaling in place, changes are relative to the rescaled application
+	app.Spec.Image = "abcdef"
+	app.Status.RescaledHash = HashForApplication(&app)
+	app.Status.JobStatus.Parallelism = 6
+	app.Spec.Parallelism = 4
+	assert.True(t, IsParallelismOnlyChange(&app))
+	app.Spec.Parallelism = 8
+	assert.True(t, IsParallelismOnlyChange(&app))
 }
 
 func TestHashForDifferentResourceScales(t *testing.T) {
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
 
 	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
 	"github.com/lyft/flytestdlib/logger"
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
@@ -20,7 +22,10 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	k8_err "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const proxyURL = "http://localhost:%d/api/v1/namespaces/%s/services/%s:8081/proxy"
@@ -40,15 +45,34 @@ const maxCheckpointTime = 10 * time.Minute
 // the JobStatus.Health to be "Red"
 const failingIntervalThreshold = 1 * time.Minute
 
+const oomKilledReason = "OOMKilled"
+
+// Container waiting reasons that indicate a pod failure, mapped to whether the failure is unrecoverable. Image pull
+// errors are initially reported as ErrImagePull, which may be transient; once the kubelet backs off we consider them
+// permanent.
+var containerWaitingFailures = map[string]bool{
+	"ErrImagePull":               false,
+	"CreateContainerConfigError": false,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+}
+
 // Interface to manage Flink Application in Kubernetes
 type ControllerInterface interface {
 	// Creates a Flink cluster with necessary Job Manager, Task Managers and services for UI
 	CreateCluster(ctx context.Context, application *v1alpha1.FlinkApplication) error
 
-	// Deletes a Flink cluster based on the hash
+	// Deletes a Flink cluster based on the hash. Objects of the cluster that do not exist are ignored, so that partial
+	// clusters can be deleted as well.
 	DeleteCluster(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) error
 
-	// Cancels the running/active jobs in the Cluster for the Application after savepoint is created
+	// Returns the hashes of clusters of the application that are neither deployed nor being deployed, and were
+	// created before the given time
+	GetOrphanedClusters(ctx context.Context, application *v1alpha1.FlinkApplication, createdBefore time.Time) ([]string, error)
+
+	// Stops the running/active jobs in the Cluster for the Application after savepoint is created. Depending on the
+	// application's stop mode the job is cancelled, stopped or drained.
 	CancelWithSavepoint(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (string, error)
 
 	// Force cancels the running/active job without taking a savepoint
@@ -62,10 +86,28 @@ type ControllerInterface interface {
 	// Polls the status of the Savepoint, using the triggerID
 	GetSavepointStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (*client.SavepointResponse, error)
 
+	// Savepoint disposal is asynchronous.
+	// Triggers the disposal of a savepoint, returning a trigger ID
+	DisposeSavepoint(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, savepointPath string) (string, error)
+
+	// Polls the status of a savepoint disposal, using the triggerID
+	GetSavepointDisposalStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error)
+
+	// Rescaling is asynchronous.
+	// Triggers rescaling of the running job to the given parallelism, returning a trigger ID
+	RescaleJob(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, parallelism int32) (string, error)
+
+	// Polls the status of rescaling the job, using the triggerID
+	GetRescaleStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error)
+
 	// Check if the Flink Kubernetes Cluster is Ready.
 	// Checks if all the pods of task and job managers are ready.
 	IsClusterReady(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error)
 
+	// Inspects the pods of the cluster for the current version of the application, and returns any failures that are
+	// preventing the cluster from starting (image pull errors, crash loops, unschedulable pods, etc.)
+	GetPodFailures(ctx context.Context, application *v1alpha1.FlinkApplication) ([]common.PodFailure, error)
+
 	// Checks to see if the Flink Cluster is ready to handle API requests
 	IsServiceReady(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error)
 
@@ -89,6 +131,13 @@ type ControllerInterface interface {
 	// Compares and updates new job status with current job status
 	// Returns true if there is a change in JobStatus
 	CompareAndUpdateJobStatus(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) (bool, error)
+
+	// Releases the REST clients held for an application that has been deleted
+	RemoveApp(application types.NamespacedName)
+
+	// Refreshes the values of the metrics selected in the application's spec
+	// Returns true if the metrics status was refreshed
+	UpdateMetricsStatus(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) (bool, error)
 }
 
 func NewController(k8sCluster k8.ClusterInterface, config config.RuntimeConfig) ControllerInterface {
@@ -125,15 +174,87 @@ type Controller struct {
 	taskManager TaskManagerControllerInterface
 	flinkClient client.FlinkAPIInterface
 	metrics     *controllerMetrics
+
+	// REST clients for applications with RestSecurity configured
+	secureClients     map[types.NamespacedName]secureClient
+	secureClientsLock sync.Mutex
+}
+
+type secureClient struct {
+	version string
+	client  client.FlinkAPIInterface
 }
 
 func getURLFromApp(application *v1alpha1.FlinkApplication, hash string) string {
 	service := VersionedJobManagerService(application, hash)
 	cfg := config.GetConfig()
 	if cfg.UseProxy {
+		if isRestTLSEnabled(application) {
+			// the API server proxy selects the scheme from the service name prefix
+			service = "https:" + service
+		}
 		return fmt.Sprintf(proxyURL, cfg.ProxyPort.Port, application.Namespace, service)
 	}
-	return fmt.Sprintf("http://%s.%s:%d", service, application.Namespace, port)
+	return fmt.Sprintf("%s://%s.%s:%d", getRestScheme(application), service, application.Namespace, port)
+}
+
+// Returns the REST client to use for the cluster of the application with the given hash, speaking the REST API of the
+// Flink version that cluster runs
+func (f *Controller) getFlinkClient(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (client.FlinkAPIInterface, error) {
+	flinkClient, err := f.getConnectionClient(ctx, application)
+	if err != nil {
+		return nil, err
+	}
+	return flinkClient.WithVersion(getClusterFlinkVersion(application, hash)), nil
+}
+
+// Applications without RestSecurity share the default client; otherwise a client is built from the referenced secrets
+// and reused until the secrets or the config change. The secrets are read at most once per reconcile.
+func (f *Controller) getConnectionClient(ctx context.Context, application *v1alpha1.FlinkApplication) (client.FlinkAPIInterface, error) {
+	if application.Spec.RestSecurity == nil {
+		f.RemoveApp(types.NamespacedName{Namespace: application.Namespace, Name: application.Name})
+		return f.flinkClient, nil
+	}
+
+	flinkClient, err := resolveOnce(ctx, "restClient", application, func() (interface{}, error) {
+		return f.getSecureClient(ctx, application)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flinkClient.(client.FlinkAPIInterface), nil
+}
+
+func (f *Controller) getSecureClient(ctx context.Context, application *v1alpha1.FlinkApplication) (client.FlinkAPIInterface, error) {
+	key := types.NamespacedName{Namespace: application.Namespace, Name: application.Name}
+	security, version, err := f.getRestSecurity(ctx, application)
+	if err != nil {
+		// the client is rebuilt once the secrets are available again
+		f.RemoveApp(key)
+		return nil, err
+	}
+
+	f.secureClientsLock.Lock()
+	defer f.secureClientsLock.Unlock()
+	if cached, ok := f.secureClients[key]; ok && cached.version == version {
+		return cached.client, nil
+	}
+
+	if f.secureClients == nil {
+		f.secureClients = map[types.NamespacedName]secureClient{}
+	}
+	flinkClient := f.flinkClient.WithSecurity(*security)
+	f.secureClients[key] = secureClient{
+		version: version,
+		client:  flinkClient,
+	}
+	return flinkClient, nil
+}
+
+func (f *Controller) RemoveApp(application types.NamespacedName) {
+	f.secureClientsLock.Lock()
+	defer f.secureClientsLock.Unlock()
+	delete(f.secureClients, application)
 }
 
 func GetActiveFlinkJob(jobs []client.FlinkJob) *client.FlinkJob {
@@ -164,7 +285,11 @@ func (f *Controller) deploymentMatches(ctx context.Context, deployment *v1.Deplo
 }
 
 func (f *Controller) GetJobsForApplication(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) ([]client.FlinkJob, error) {
-	jobResponse, err := f.flinkClient.GetJobs(ctx, getURLFromApp(application, hash))
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return nil, err
+	}
+	jobResponse, err := flinkClient.GetJobs(ctx, f.getJobManagerURL(ctx, application, hash))
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +304,7 @@ func (f *Controller) getJobIDForApplication(application *v1alpha1.FlinkApplicati
 		return application.Status.JobStatus.JobID, nil
 	}
 
-	return "", errors.New("active job id not available")
+	return "", flinkErrors.Errorf(flinkErrors.IllegalStateError, "active job id not available")
 }
 
 func (f *Controller) CancelWithSavepoint(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (string, error) {
@@ -187,7 +312,19 @@ func (f *Controller) CancelWithSavepoint(ctx context.Context, application *v1alp
 	if err != nil {
 		return "", err
 	}
-	return f.flinkClient.CancelJobWithSavepoint(ctx, getURLFromApp(application, hash), jobID)
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return "", err
+	}
+	url := f.getJobManagerURL(ctx, application, hash)
+	switch GetStopMode(application) {
+	case v1alpha1.StopModeStop:
+		return flinkClient.StopJobWithSavepoint(ctx, url, jobID, false)
+	case v1alpha1.StopModeDrain:
+		return flinkClient.StopJobWithSavepoint(ctx, url, jobID, true)
+	default:
+		return flinkClient.CancelJobWithSavepoint(ctx, url, jobID)
+	}
 }
 
 func (f *Controller) ForceCancel(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) error {
@@ -195,10 +332,31 @@ func (f *Controller) ForceCancel(ctx context.Context, application *v1alpha1.Flin
 	if err != nil {
 		return err
 	}
-	return f.flinkClient.ForceCancelJob(ctx, getURLFromApp(application, hash), jobID)
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return err
+	}
+	return flinkClient.ForceCancelJob(ctx, f.getJobManagerURL(ctx, application, hash), jobID)
 }
 
 func (f *Controller) CreateCluster(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+	// the pods cannot start until the ConfigMap they mount exists
+	newlyCreatedConfigMap, err := f.createConfigMapIfNotExist(ctx, application)
+	if err != nil {
+		logger.Errorf(ctx, "Config map creation did not succeed %v", err)
+		f.LogEvent(ctx, application, "", corev1.EventTypeWarning,
+			fmt.Sprintf("Failed to create config map: %v", err))
+		return err
+	}
+
+	newlyCreatedServiceAccount, err := f.createHighAvailabilityServiceAccountIfNotExist(ctx, application)
+	if err != nil {
+		logger.Errorf(ctx, "High availability service account creation did not succeed %v", err)
+		f.LogEvent(ctx, application, "", corev1.EventTypeWarning,
+			fmt.Sprintf("Failed to create high availability service account: %v", err))
+		return err
+	}
+
 	newlyCreatedJm, err := f.jobManager.CreateIfNotExist(ctx, application)
 	if err != nil {
 		logger.Errorf(ctx, "Job manager cluster creation did not succeed %v", err)
@@ -215,17 +373,97 @@ func (f *Controller) CreateCluster(ctx context.Context, application *v1alpha1.Fl
 		return err
 	}
 
-	if newlyCreatedJm || newlyCreatedTm {
+	newlyCreatedNetworkPolicy, err := f.createNetworkPolicyIfNotExist(ctx, application)
+	if err != nil {
+		logger.Errorf(ctx, "Network policy creation did not succeed %v", err)
+		f.LogEvent(ctx, application, "", corev1.EventTypeWarning,
+			fmt.Sprintf("Failed to create network policy: %v", err))
+		return err
+	}
+
+	if newlyCreatedConfigMap || newlyCreatedServiceAccount || newlyCreatedJm || newlyCreatedTm || newlyCreatedNetworkPolicy {
 		f.LogEvent(ctx, application, "", corev1.EventTypeNormal, "Flink cluster created")
 	}
 	return nil
 }
 
+func (f *Controller) createConfigMapIfNotExist(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+	if !isConfigMapModeEnabled(application) {
+		return false, nil
+	}
+
+	configMap, err := FetchConfigMapCreateObj(application, HashForApplication(application))
+	if err != nil {
+		return false, err
+	}
+	err = f.k8Cluster.CreateK8Object(ctx, configMap)
+	if err != nil {
+		if !k8_err.IsAlreadyExists(err) {
+			return false, err
+		}
+		logger.Infof(ctx, "Config map already exists")
+		return false, nil
+	}
+	return true, nil
+}
+
+// The pods need a service account allowed to manage the leader election ConfigMaps before they can start
+func (f *Controller) createHighAvailabilityServiceAccountIfNotExist(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+	if !needsHighAvailabilityServiceAccount(application) {
+		return false, nil
+	}
+
+	newlyCreated := false
+	objects := []runtime.Object{
+		FetchHighAvailabilityServiceAccountCreateObj(application),
+		FetchHighAvailabilityRoleCreateObj(application),
+		FetchHighAvailabilityRoleBindingCreateObj(application),
+	}
+	for _, object := range objects {
+		err := f.k8Cluster.CreateK8Object(ctx, object)
+		if err != nil {
+			if !k8_err.IsAlreadyExists(err) {
+				return false, err
+			}
+			continue
+		}
+		newlyCreated = true
+	}
+	if !newlyCreated {
+		logger.Infof(ctx, "High availability service account already exists")
+	}
+	return newlyCreated, nil
+}
+
+func (f *Controller) createNetworkPolicyIfNotExist(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+	if !isNetworkPolicyEnabled(application) {
+		return false, nil
+	}
+
+	networkPolicy, err := FetchNetworkPolicyCreateObj(application, HashForApplication(application))
+	if err != nil {
+		return false, err
+	}
+	err = f.k8Cluster.CreateK8Object(ctx, networkPolicy)
+	if err != nil {
+		if !k8_err.IsAlreadyExists(err) {
+			return false, err
+		}
+		logger.Infof(ctx, "Network policy already exists")
+		return false, nil
+	}
+	return true, nil
+}
+
 func (f *Controller) StartFlinkJob(ctx context.Context, application *v1alpha1.FlinkApplication, hash string,
 	jarName string, parallelism int32, entryClass string, programArgs string) (string, error) {
-	response, err := f.flinkClient.SubmitJob(
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return "", err
+	}
+	response, err := flinkClient.SubmitJob(
 		ctx,
-		getURLFromApp(application, hash),
+		f.getJobManagerURL(ctx, application, hash),
 		jarName,
 		client.SubmitJobRequest{
 			Parallelism:   parallelism,
@@ -238,7 +476,7 @@ func (f *Controller) StartFlinkJob(ctx context.Context, application *v1alpha1.Fl
 	}
 	if response.JobID == "" {
 		logger.Errorf(ctx, "Job id in the submit job response was empty")
-		return "", errors.New("unable to submit job: invalid job id")
+		return "", flinkErrors.Errorf(flinkErrors.InvalidResponseError, "unable to submit job: invalid job id")
 	}
 	return response.JobID, nil
 }
@@ -248,25 +486,70 @@ func (f *Controller) GetSavepointStatus(ctx context.Context, application *v1alph
 	if err != nil {
 		return nil, err
 	}
-	return f.flinkClient.CheckSavepointStatus(ctx, getURLFromApp(application, hash), jobID, application.Spec.SavepointInfo.TriggerID)
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return nil, err
+	}
+	return flinkClient.CheckSavepointStatus(ctx, f.getJobManagerURL(ctx, application, hash), jobID, application.Spec.SavepointInfo.TriggerID)
+}
+
+func (f *Controller) DisposeSavepoint(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, savepointPath string) (string, error) {
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return "", err
+	}
+	return flinkClient.DisposeSavepoint(ctx, f.getJobManagerURL(ctx, application, hash), savepointPath)
+}
+
+func (f *Controller) GetSavepointDisposalStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return nil, err
+	}
+	return flinkClient.CheckSavepointDisposalStatus(ctx, f.getJobManagerURL(ctx, application, hash), triggerID)
+}
+
+func (f *Controller) RescaleJob(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, parallelism int32) (string, error) {
+	jobID, err := f.getJobIDForApplication(application)
+	if err != nil {
+		return "", err
+	}
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return "", err
+	}
+	return flinkClient.RescaleJob(ctx, f.getJobManagerURL(ctx, application, hash), jobID, parallelism)
+}
+
+func (f *Controller) GetRescaleStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+	jobID, err := f.getJobIDForApplication(application)
+	if err != nil {
+		return nil, err
+	}
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return nil, err
+	}
+	return flinkClient.CheckRescaleStatus(ctx, f.getJobManagerURL(ctx, application, hash), jobID, triggerID)
 }
 
 func (f *Controller) DeleteCluster(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) error {
 	if hash == "" {
-		return errors.New("invalid hash: must not be empty")
+		return flinkErrors.Errorf(flinkErrors.IllegalStateError, "invalid hash: must not be empty")
 	}
 
 	jmDeployment := FetchJobMangerDeploymentDeleteObj(application, hash)
 	err := f.k8Cluster.DeleteK8Object(ctx, jmDeployment)
-	if err != nil {
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
 		f.metrics.deleteClusterFailedCounter.Inc(ctx)
 		logger.Warnf(ctx, "Failed to delete jobmanager deployment")
 		return err
 	}
 
+	// the taskmanagers run as a stateful set instead of a deployment if it is enabled
 	tmDeployment := FetchTaskMangerDeploymentDeleteObj(application, hash)
 	err = f.k8Cluster.DeleteK8Object(ctx, tmDeployment)
-	if err != nil {
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
 		f.metrics.deleteClusterFailedCounter.Inc(ctx)
 		logger.Warnf(ctx, "Failed to delete taskmanager deployment")
 		return err
@@ -274,12 +557,90 @@ func (f *Controller) DeleteCluster(ctx context.Context, application *v1alpha1.Fl
 
 	versionedJobService := FetchVersionedJobManagerServiceDeleteObj(application, hash)
 	err = f.k8Cluster.DeleteK8Object(ctx, versionedJobService)
-	if err != nil {
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
 		f.metrics.deleteClusterFailedCounter.Inc(ctx)
 		logger.Warnf(ctx, "Failed to delete versioned service")
 		return err
 	}
 
+	// the pod disruption budgets do not exist if they are disabled, or if the cluster was created before they were
+	// introduced
+	jmPDB := FetchJobManagerPodDisruptionBudgetDeleteObj(application, hash)
+	err = f.k8Cluster.DeleteK8Object(ctx, jmPDB)
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		logger.Warnf(ctx, "Failed to delete jobmanager pod disruption budget")
+		return err
+	}
+
+	tmPDB := FetchTaskManagerPodDisruptionBudgetDeleteObj(application, hash)
+	err = f.k8Cluster.DeleteK8Object(ctx, tmPDB)
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		logger.Warnf(ctx, "Failed to delete taskmanager pod disruption budget")
+		return err
+	}
+
+	// the network policy may not exist either, as it is opt-in and may have been enabled after the cluster was created
+	networkPolicy := FetchNetworkPolicyDeleteObj(application, hash)
+	err = f.k8Cluster.DeleteK8Object(ctx, networkPolicy)
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		logger.Warnf(ctx, "Failed to delete network policy")
+		return err
+	}
+
+	configMap := FetchConfigMapDeleteObj(application, hash)
+	err = f.k8Cluster.DeleteK8Object(ctx, configMap)
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		logger.Warnf(ctx, "Failed to delete config map")
+		return err
+	}
+
+	tmStatefulSet := FetchTaskManagerStatefulSetDeleteObj(application, hash)
+	err = f.k8Cluster.DeleteK8Object(ctx, tmStatefulSet)
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		logger.Warnf(ctx, "Failed to delete taskmanager stateful set")
+		return err
+	}
+
+	tmService := FetchTaskManagerServiceDeleteObj(application, hash)
+	err = f.k8Cluster.DeleteK8Object(ctx, tmService)
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		logger.Warnf(ctx, "Failed to delete taskmanager service")
+		return err
+	}
+
+	// the claims created from the volume claim templates are not deleted along with the stateful set
+	claimLabels := getCommonAppLabels(application)
+	claimLabels[FlinkAppHash] = hash
+	claims, err := f.k8Cluster.GetPersistentVolumeClaimsWithLabel(ctx, application.Namespace, claimLabels)
+	if err != nil {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		logger.Warnf(ctx, "Failed to get persistent volume claims for label map %v", claimLabels)
+		return err
+	}
+	if claims != nil {
+		for i := range claims.Items {
+			err = f.k8Cluster.DeleteK8Object(ctx, &claims.Items[i])
+			if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+				f.metrics.deleteClusterFailedCounter.Inc(ctx)
+				logger.Warnf(ctx, "Failed to delete persistent volume claim %s", claims.Items[i].Name)
+				return err
+			}
+		}
+	}
+
+	err = f.deleteHighAvailabilityConfigMaps(ctx, application, hash)
+	if err != nil {
+		f.metrics.deleteClusterFailedCounter.Inc(ctx)
+		return err
+	}
+
+	f.flinkClient.ForgetJobManager(getURLFromApp(application, hash))
 	f.metrics.deleteClusterSuccessCounter.Inc(ctx)
 	return nil
 }
@@ -297,10 +658,26 @@ func (f *Controller) IsClusterReady(ctx context.Context, application *v1alpha1.F
 		return false, nil
 	}
 
-	// TODO: Find if any events can be populated, that are useful to users
+	if isStatefulSetEnabled(application) {
+		statefulSetList, err := f.k8Cluster.GetStatefulSetsWithLabel(ctx, application.Namespace, labelMap)
+		if err != nil {
+			logger.Warnf(ctx, "Failed to get stateful sets for label map %v", labelMap)
+			return false, err
+		}
+		if statefulSetList == nil || len(statefulSetList.Items) == 0 {
+			logger.Infof(ctx, "No stateful sets present for label map %v", labelMap)
+			return false, nil
+		}
+		for i := range statefulSetList.Items {
+			if !isStatefulSetReady(&statefulSetList.Items[i]) {
+				return false, nil
+			}
+		}
+	}
+
 	for _, deployment := range deploymentList.Items {
-		// For Jobmanager we only need on replica to be available
-		if deployment.Labels[FlinkDeploymentType] == FlinkDeploymentTypeJobmanager {
+		// For Jobmanager we only need on replica to be available, unless the standby replicas are needed for HA
+		if deployment.Labels[FlinkDeploymentType] == FlinkDeploymentTypeJobmanager && !isHighAvailabilityEnabled(application) {
 			if deployment.Status.AvailableReplicas == 0 {
 				return false, nil
 			}
@@ -314,8 +691,91 @@ func (f *Controller) IsClusterReady(ctx context.Context, application *v1alpha1.F
 	return true, nil
 }
 
+func (f *Controller) GetPodFailures(ctx context.Context, application *v1alpha1.FlinkApplication) ([]common.PodFailure, error) {
+	labelMap := GetAppHashSelector(application)
+
+	podList, err := f.k8Cluster.GetPodsWithLabel(ctx, application.Namespace, labelMap)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get pods for label map %v", labelMap)
+		return nil, err
+	}
+	if podList == nil {
+		return nil, nil
+	}
+
+	var failures []common.PodFailure
+	for i := range podList.Items {
+		failures = append(failures, getPodFailures(&podList.Items[i])...)
+	}
+	return failures, nil
+}
+
+func getPodFailures(pod *corev1.Pod) []common.PodFailure {
+	var failures []common.PodFailure
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			failures = append(failures, common.PodFailure{
+				PodName: pod.Name,
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+		}
+	}
+
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			if unrecoverable, ok := containerWaitingFailures[waiting.Reason]; ok {
+				failures = append(failures, common.PodFailure{
+					PodName:       pod.Name,
+					Reason:        waiting.Reason,
+					Message:       fmt.Sprintf("container %s: %s", status.Name, waiting.Message),
+					Unrecoverable: unrecoverable,
+				})
+			}
+		}
+
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.Reason == oomKilledReason {
+			failures = append(failures, common.PodFailure{
+				PodName: pod.Name,
+				Reason:  oomKilledReason,
+				Message: fmt.Sprintf("container %s was killed after exceeding its memory limit", status.Name),
+			})
+		}
+	}
+
+	return failures
+}
+
 func (f *Controller) IsServiceReady(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
-	_, err := f.flinkClient.GetClusterOverview(ctx, getURLFromApp(application, hash))
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return false, err
+	}
+
+	// with Kubernetes HA, the cluster is not ready until a leader has been elected. Otherwise the JobManagers can only
+	// answer the request once they know the leader to forward it to.
+	if isKubernetesHighAvailabilityEnabled(application) {
+		leaderURL, err := f.getLeaderURL(ctx, application, hash)
+		if err != nil {
+			return false, err
+		}
+		if leaderURL == "" {
+			logger.Infof(ctx, "No job manager has been elected leader yet")
+			return false, nil
+		}
+	}
+	if isZooKeeperHighAvailabilityEnabled(application) {
+		found, err := f.haveJobManagersFoundLeader(ctx, flinkClient, application, hash)
+		if err != nil || !found {
+			return false, err
+		}
+	}
+
+	_, err = flinkClient.GetClusterOverview(ctx, f.getJobManagerURL(ctx, application, hash))
 	if err != nil {
 		logger.Infof(ctx, "Error response indicating flink API is not ready to handle request %v", err)
 		return false, err
@@ -323,8 +783,10 @@ func (f *Controller) IsServiceReady(ctx context.Context, application *v1alpha1.F
 	return true, nil
 }
 
-func listToFlinkDeployment(ds []v1.Deployment, hash string) *common.FlinkDeployment {
-	if len(ds) != 2 {
+// Returns the cluster made up of the given deployments and stateful sets, which must be a JobManager deployment along
+// with either a TaskManager deployment or a TaskManager stateful set
+func listToFlinkDeployment(ds []v1.Deployment, ss []v1.StatefulSet, hash string) *common.FlinkDeployment {
+	if len(ds)+len(ss) != 2 {
 		return nil
 	}
 
@@ -332,25 +794,37 @@ func listToFlinkDeployment(ds []v1.Deployment, hash string) *common.FlinkDeploym
 		Hash: hash,
 	}
 
-	l0 := ds[0].Labels[FlinkDeploymentType]
-	l1 := ds[1].Labels[FlinkDeploymentType]
+	for i := range ds {
+		switch ds[i].Labels[FlinkDeploymentType] {
+		case FlinkDeploymentTypeJobmanager:
+			fd.Jobmanager = &ds[i]
+		case FlinkDeploymentTypeTaskmanager:
+			fd.Taskmanager = &ds[i]
+		}
+	}
+	for i := range ss {
+		if ss[i].Labels[FlinkDeploymentType] == FlinkDeploymentTypeTaskmanager {
+			fd.TaskmanagerStatefulSet = &ss[i]
+		}
+	}
 
-	if l0 == FlinkDeploymentTypeJobmanager && l1 == FlinkDeploymentTypeTaskmanager {
-		fd.Jobmanager = &ds[0]
-		fd.Taskmanager = &ds[1]
-	} else if l0 == FlinkDeploymentTypeTaskmanager && l1 == FlinkDeploymentTypeJobmanager {
-		fd.Jobmanager = &ds[1]
-		fd.Taskmanager = &ds[0]
-	} else {
+	if fd.Jobmanager == nil || (fd.Taskmanager == nil && fd.TaskmanagerStatefulSet == nil) {
 		return nil
 	}
-
 	return &fd
 }
 
+// returns true iff the TaskManagers of the cluster exactly match the flink application
+func (f *Controller) taskManagersMatch(ctx context.Context, fd *common.FlinkDeployment, application *v1alpha1.FlinkApplication) bool {
+	if fd.TaskmanagerStatefulSet != nil {
+		return TaskManagerStatefulSetMatches(fd.TaskmanagerStatefulSet, application)
+	}
+	return f.deploymentMatches(ctx, fd.Taskmanager, application)
+}
+
 // Gets the current deployment and any other deployments for the application. The current deployment will be the one
 // that matches the FlinkApplication, unless the FailedDeployHash is set, in which case it will be the one with that
-// hash.
+// hash, or the application's job has been rescaled in place, in which case it is the deployed cluster.
 func (f *Controller) GetCurrentAndOldDeploymentsForApp(ctx context.Context,
 	application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error) {
 	appLabels := k8.GetAppLabel(application.Name)
@@ -359,36 +833,53 @@ func (f *Controller) GetCurrentAndOldDeploymentsForApp(ctx context.Context,
 		return nil, nil, err
 	}
 
+	statefulSets, err := f.k8Cluster.GetStatefulSetsWithLabel(ctx, application.Namespace, appLabels)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	byHash := map[string][]v1.Deployment{}
 	for _, deployment := range deployments.Items {
 		byHash[deployment.Labels[FlinkAppHash]] = append(byHash[deployment.Labels[FlinkAppHash]], deployment)
 	}
+	statefulSetsByHash := map[string][]v1.StatefulSet{}
+	if statefulSets != nil {
+		for _, statefulSet := range statefulSets.Items {
+			hash := statefulSet.Labels[FlinkAppHash]
+			statefulSetsByHash[hash] = append(statefulSetsByHash[hash], statefulSet)
+			// clusters may consist of stateful sets only while their deployments are being created or deleted
+			if _, ok := byHash[hash]; !ok {
+				byHash[hash] = nil
+			}
+		}
+	}
 
 	appHash := HashForApplication(application)
 	var curHash string
 
-	if appHash == application.Status.FailedDeployHash {
+	if appHash == application.Status.FailedDeployHash || appHash == application.Status.RescaledHash {
 		curHash = application.Status.DeployHash
 	} else {
 		curHash = appHash
 	}
 
-	cur := listToFlinkDeployment(byHash[curHash], curHash)
-	if cur != nil && application.Status.FailedDeployHash == "" &&
-		(!f.deploymentMatches(ctx, cur.Jobmanager, application) || !f.deploymentMatches(ctx, cur.Taskmanager, application)) {
+	cur := listToFlinkDeployment(byHash[curHash], statefulSetsByHash[curHash], curHash)
+	if cur != nil && curHash == appHash && application.Status.FailedDeployHash == "" &&
+		(!f.deploymentMatches(ctx, cur.Jobmanager, application) || !f.taskManagersMatch(ctx, cur, application)) {
 		// we had a hash collision (i.e., the previous application has the same hash as the new one)
 		// this is *very* unlikely to occur (1/2^32)
-		return nil, nil, errors.New("found hash collision for deployment, you must do a clean deploy")
+		return nil, nil, flinkErrors.Errorf(flinkErrors.IllegalStateError,
+			"found hash collision for deployment, you must do a clean deploy")
 	}
 
 	old := make([]common.FlinkDeployment, 0)
 	for hash, ds := range byHash {
 		if hash != curHash {
-			fd := listToFlinkDeployment(ds, hash)
+			fd := listToFlinkDeployment(ds, statefulSetsByHash[hash], hash)
 			if fd != nil {
 				old = append(old, *fd)
 			} else {
-				logger.Warn(ctx, "Found deployments that do not have one JM and TM: %v", ds)
+				logger.Warnf(ctx, "Found deployments that do not have one JM and TM: %v %v", ds, statefulSetsByHash[hash])
 			}
 		}
 	}
@@ -397,7 +888,11 @@ func (f *Controller) GetCurrentAndOldDeploymentsForApp(ctx context.Context,
 }
 
 func (f *Controller) FindExternalizedCheckpoint(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (string, error) {
-	checkpoint, err := f.flinkClient.GetLatestCheckpoint(ctx, getURLFromApp(application, hash), application.Status.JobStatus.JobID)
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return "", err
+	}
+	checkpoint, err := flinkClient.GetLatestCheckpoint(ctx, f.getJobManagerURL(ctx, application, hash), application.Status.JobStatus.JobID)
 	if err != nil {
 		return "", err
 	}
@@ -437,8 +932,14 @@ func (f *Controller) LogEvent(ctx context.Context, app *v1alpha1.FlinkApplicatio
 func (f *Controller) CompareAndUpdateClusterStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
 	oldClusterStatus := application.Status.ClusterStatus
 	clusterErrors := ""
+	flinkClient, err := f.getFlinkClient(ctx, application, hash)
+	if err != nil {
+		return false, err
+	}
+
 	// Get Cluster overview
-	response, err := f.flinkClient.GetClusterOverview(ctx, getURLFromApp(application, hash))
+	url := f.getJobManagerURL(ctx, application, hash)
+	response, err := flinkClient.GetClusterOverview(ctx, url)
 
 	if err != nil {
 		clusterErrors = err.Error()
@@ -450,7 +951,7 @@ func (f *Controller) CompareAndUpdateClusterStatus(ctx context.Context, applicat
 	}
 
 	// Get Healthy Taskmanagers
-	tmResponse, tmErr := f.flinkClient.GetTaskManagers(ctx, getURLFromApp(application, hash))
+	tmResponse, tmErr := flinkClient.GetTaskManagers(ctx, url)
 	if tmErr != nil {
 		clusterErrors += tmErr.Error()
 	} else {
@@ -496,11 +997,16 @@ func (f *Controller) CompareAndUpdateJobStatus(ctx context.Context, app *v1alpha
 	oldJobStatus := app.Status.JobStatus
 
 	app.Status.JobStatus.JobID = oldJobStatus.JobID
-	jobResponse, err := f.flinkClient.GetJobOverview(ctx, getURLFromApp(app, hash), app.Status.JobStatus.JobID)
+	flinkClient, err := f.getFlinkClient(ctx, app, hash)
+	if err != nil {
+		return false, err
+	}
+	url := f.getJobManagerURL(ctx, app, hash)
+	jobResponse, err := flinkClient.GetJobOverview(ctx, url, app.Status.JobStatus.JobID)
 	if err != nil {
 		return false, err
 	}
-	checkpoints, err := f.flinkClient.GetCheckpointCounts(ctx, getURLFromApp(app, hash), app.Status.JobStatus.JobID)
+	checkpoints, err := flinkClient.GetCheckpointCounts(ctx, url, app.Status.JobStatus.JobID)
 	if err != nil {
 		return false, err
 	}
@@ -509,6 +1015,7 @@ func (f *Controller) CompareAndUpdateJobStatus(ctx context.Context, app *v1alpha
 	app.Status.JobStatus.State = v1alpha1.JobState(jobResponse.State)
 	jobStartTime := metav1.NewTime(time.Unix(jobResponse.StartTime/1000, 0))
 	app.Status.JobStatus.StartTime = &jobStartTime
+	app.Status.JobStatus.Vertices = getVertexStatuses(ctx, flinkClient, app, url, jobResponse.Vertices)
 
 	// Checkpoints status
 	app.Status.JobStatus.FailedCheckpointCount = checkpoints.Counts["failed"]
@@ -533,12 +1040,14 @@ func (f *Controller) CompareAndUpdateJobStatus(ctx context.Context, app *v1alpha
 	// Health Status for job
 	// Job is in FAILING state --> RED
 	// Time since last successful checkpoint > maxCheckpointTime --> YELLOW
+	// A vertex has failed subtasks or is under high backpressure --> YELLOW
 	// Else --> Green
 
 	if app.Status.JobStatus.State == v1alpha1.Failing || time.Since(app.Status.JobStatus.LastFailingTime.Time) <
 		failingIntervalThreshold {
 		app.Status.JobStatus.Health = v1alpha1.Red
-	} else if time.Since(time.Unix(int64(lastCheckpointAgeSeconds), 0)) < maxCheckpointTime {
+	} else if time.Since(time.Unix(int64(lastCheckpointAgeSeconds), 0)) < maxCheckpointTime ||
+		hasDegradedVertex(app.Status.JobStatus.Vertices) {
 		app.Status.JobStatus.Health = v1alpha1.Yellow
 	} else {
 		app.Status.JobStatus.Health = v1alpha1.Green
@@ -551,3 +1060,55 @@ func (f *Controller) CompareAndUpdateJobStatus(ctx context.Context, app *v1alpha
 
 	return !apiequality.Semantic.DeepEqual(oldJobStatus, app.Status.JobStatus), err
 }
+
+// Summarizes the vertices of the job. When backpressure sampling is enabled, each running vertex is also sampled. Flink
+// computes backpressure asynchronously, so the level from the previous status is retained until a sample is available.
+func getVertexStatuses(ctx context.Context, flinkClient client.FlinkAPIInterface, app *v1alpha1.FlinkApplication, url string,
+	vertices []client.FlinkJobVertex) []v1alpha1.FlinkJobVertexStatus {
+	if len(vertices) == 0 {
+		return nil
+	}
+
+	previousLevels := make(map[string]v1alpha1.BackpressureLevel, len(app.Status.JobStatus.Vertices))
+	for _, vertex := range app.Status.JobStatus.Vertices {
+		previousLevels[vertex.Name] = vertex.BackpressureLevel
+	}
+
+	statuses := make([]v1alpha1.FlinkJobVertexStatus, 0, len(vertices))
+	for _, vertex := range vertices {
+		status := v1alpha1.FlinkJobVertexStatus{
+			Name:         vertex.Name,
+			Parallelism:  vertex.Parallelism,
+			Status:       v1alpha1.JobState(vertex.Status),
+			RunningTasks: vertex.Tasks[string(client.Running)],
+			FailedTasks:  vertex.Tasks[string(client.Failed)],
+		}
+
+		if config.GetConfig().SampleBackpressure && vertex.Status == client.Running {
+			status.BackpressureLevel = previousLevels[vertex.Name]
+			backpressure, err := flinkClient.GetVertexBackpressure(ctx, url, app.Status.JobStatus.JobID, vertex.ID)
+			if err != nil {
+				logger.Warnf(ctx, "Failed to sample backpressure for vertex %s: %v", vertex.Name, err)
+			} else if backpressure != nil && backpressure.BackpressureLevel != "" {
+				status.BackpressureLevel = v1alpha1.BackpressureLevel(backpressure.BackpressureLevel)
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// Returns true if any vertex of the job is failing, has failed subtasks or is under high backpressure
+func hasDegradedVertex(vertices []v1alpha1.FlinkJobVertexStatus) bool {
+	for _, vertex := range vertices {
+		if vertex.FailedTasks > 0 ||
+			vertex.Status == v1alpha1.Failing ||
+			vertex.Status == v1alpha1.Failed ||
+			vertex.BackpressureLevel == v1alpha1.BackpressureHigh {
+			return true
+		}
+	}
+	return false
+}
@@ -2,24 +2,35 @@ package flink
 
 import (
 	"context"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 
 	"time"
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client/fake"
 	clientMock "github.com/lyft/flinkk8soperator/pkg/controller/flink/client/mock"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink/mock"
 	k8mock "github.com/lyft/flinkk8soperator/pkg/controller/k8/mock"
+	flyteConfig "github.com/lyft/flytestdlib/config"
 	mockScope "github.com/lyft/flytestdlib/promutils"
 	"github.com/lyft/flytestdlib/promutils/labeled"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	networkingV1 "k8s.io/api/networking/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const testImage = "123.xyz.com/xx:11ae1218924428faabd9b64423fa0c332efba6b2"
@@ -254,6 +265,33 @@ func TestFlinkApplicationNeedsUpdate(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestFlinkApplicationRescaledInPlace(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetDeploymentsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error) {
+		app := getFlinkTestApp()
+		return &v1.DeploymentList{
+			Items: []v1.Deployment{
+				*FetchJobMangerDeploymentCreateObj(&app, testAppHash),
+				*FetchTaskMangerDeploymentCreateObj(&app, testAppHash),
+			},
+		}, nil
+	}
+
+	// the job in the cluster has been rescaled to the new parallelism, so the cluster is still current
+	flinkApp.Spec.Parallelism = 10
+	flinkApp.Status.DeployHash = testAppHash
+	flinkApp.Status.RescaledHash = HashForApplication(&flinkApp)
+	cur, old, err := flinkControllerForTest.GetCurrentAndOldDeploymentsForApp(
+		context.Background(), &flinkApp,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, testAppHash, cur.Hash)
+	assert.Empty(t, old)
+}
+
 func TestFlinkIsServiceReady(t *testing.T) {
 	flinkControllerForTest := getTestFlinkController()
 	flinkApp := getFlinkTestApp()
@@ -374,6 +412,12 @@ func TestDeleteCluster(t *testing.T) {
 	jmDeployment := FetchJobMangerDeploymentDeleteObj(&flinkApp, "hash")
 	tmDeployment := FetchTaskMangerDeploymentDeleteObj(&flinkApp, "hash")
 	service := FetchVersionedJobManagerServiceDeleteObj(&flinkApp, "hash")
+	jmPDB := FetchJobManagerPodDisruptionBudgetDeleteObj(&flinkApp, "hash")
+	tmPDB := FetchTaskManagerPodDisruptionBudgetDeleteObj(&flinkApp, "hash")
+	networkPolicy := FetchNetworkPolicyDeleteObj(&flinkApp, "hash")
+	configMap := FetchConfigMapDeleteObj(&flinkApp, "hash")
+	tmStatefulSet := FetchTaskManagerStatefulSetDeleteObj(&flinkApp, "hash")
+	tmService := FetchTaskManagerServiceDeleteObj(&flinkApp, "hash")
 
 	ctr := 0
 	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
@@ -386,12 +430,38 @@ func TestDeleteCluster(t *testing.T) {
 			assert.Equal(t, object, tmDeployment)
 		case 3:
 			assert.Equal(t, object, service)
+		case 4:
+			assert.Equal(t, object, jmPDB)
+		case 5:
+			assert.Equal(t, object, tmPDB)
+			// pod disruption budgets that do not exist are ignored
+			return k8sErrors.NewNotFound(schema.GroupResource{}, "")
+		case 6:
+			assert.Equal(t, object, networkPolicy)
+			return k8sErrors.NewNotFound(schema.GroupResource{}, "")
+		case 7:
+			assert.Equal(t, object, configMap)
+			return k8sErrors.NewNotFound(schema.GroupResource{}, "")
+		case 8:
+			assert.Equal(t, object, tmStatefulSet)
+			return k8sErrors.NewNotFound(schema.GroupResource{}, "")
+		case 9:
+			assert.Equal(t, object, tmService)
+			return k8sErrors.NewNotFound(schema.GroupResource{}, "")
 		}
 		return nil
 	}
 
+	var forgotten []string
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.ForgetJobManagerFunc = func(url string) {
+		forgotten = append(forgotten, url)
+	}
+
 	err := flinkControllerForTest.DeleteCluster(context.Background(), &flinkApp, "hash")
 	assert.Nil(t, err)
+	assert.Equal(t, 9, ctr)
+	assert.Contains(t, forgotten, getURLFromApp(&flinkApp, "hash"))
 }
 
 func TestCreateCluster(t *testing.T) {
@@ -410,6 +480,33 @@ func TestCreateCluster(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestCreateClusterWithNetworkPolicy(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+	flinkApp.Spec.NetworkPolicy = &v1alpha1.NetworkPolicyConfig{Enabled: true}
+	mockJobManager := flinkControllerForTest.jobManager.(*mock.JobManagerController)
+	mockTaskManager := flinkControllerForTest.taskManager.(*mock.TaskManagerController)
+
+	mockJobManager.CreateIfNotExistFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+		return false, nil
+	}
+	mockTaskManager.CreateIfNotExistFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+		return false, nil
+	}
+	created := false
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.CreateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		if networkPolicy, ok := object.(*networkingV1.NetworkPolicy); ok {
+			assert.Equal(t, testAppName+"-"+HashForApplication(&flinkApp), networkPolicy.Name)
+			created = true
+		}
+		return nil
+	}
+	err := flinkControllerForTest.CreateCluster(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.True(t, created)
+}
+
 func TestCreateClusterJmErr(t *testing.T) {
 	flinkControllerForTest := getTestFlinkController()
 	flinkApp := getFlinkTestApp()
@@ -483,7 +580,7 @@ func TestStartFlinkJobEmptyJobID(t *testing.T) {
 	}
 	jobID, err := flinkControllerForTest.StartFlinkJob(context.Background(), &flinkApp, "hash",
 		flinkApp.Spec.JarName, flinkApp.Spec.Parallelism, flinkApp.Spec.EntryClass, flinkApp.Spec.ProgramArgs)
-	assert.EqualError(t, err, "unable to submit job: invalid job id")
+	assert.EqualError(t, err, "ErrorCode: [InvalidResponseError] Reason: [unable to submit job: invalid job id]")
 	assert.Empty(t, jobID)
 }
 
@@ -529,6 +626,107 @@ func TestCancelWithSavepointErr(t *testing.T) {
 	assert.Empty(t, triggerID)
 }
 
+func TestRescaleJob(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.RescaleJobFunc = func(ctx context.Context, url string, jobID string, parallelism int32) (string, error) {
+		assert.Equal(t, url, "http://app-name-hash.ns:8081")
+		assert.Equal(t, jobID, testJobID)
+		assert.Equal(t, int32(12), parallelism)
+		return "t1", nil
+	}
+	mockJmClient.CheckRescaleStatusFunc = func(ctx context.Context, url string, jobID string, triggerID string) (*client.SavepointResponse, error) {
+		assert.Equal(t, jobID, testJobID)
+		assert.Equal(t, triggerID, "t1")
+		return &client.SavepointResponse{
+			SavepointStatus: client.SavepointStatusResponse{
+				Status: client.SavePointCompleted,
+			},
+		}, nil
+	}
+
+	triggerID, err := flinkControllerForTest.RescaleJob(context.Background(), &flinkApp, "hash", 12)
+	assert.Nil(t, err)
+	assert.Equal(t, triggerID, "t1")
+
+	status, err := flinkControllerForTest.GetRescaleStatus(context.Background(), &flinkApp, "hash", triggerID)
+	assert.Nil(t, err)
+	assert.Equal(t, client.SavePointCompleted, status.SavepointStatus.Status)
+}
+
+func TestDisposeSavepoint(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.DisposeSavepointFunc = func(ctx context.Context, url string, savepointPath string) (string, error) {
+		assert.Equal(t, url, "http://app-name-hash.ns:8081")
+		assert.Equal(t, savepointPath, "s3://savepoints/sp-1")
+		return "t1", nil
+	}
+	mockJmClient.CheckSavepointDisposalStatusFunc = func(ctx context.Context, url string, triggerID string) (*client.SavepointResponse, error) {
+		assert.Equal(t, url, "http://app-name-hash.ns:8081")
+		assert.Equal(t, triggerID, "t1")
+		return &client.SavepointResponse{
+			SavepointStatus: client.SavepointStatusResponse{
+				Status: client.SavePointCompleted,
+			},
+		}, nil
+	}
+
+	triggerID, err := flinkControllerForTest.DisposeSavepoint(context.Background(), &flinkApp, "hash", "s3://savepoints/sp-1")
+	assert.Nil(t, err)
+	assert.Equal(t, triggerID, "t1")
+
+	status, err := flinkControllerForTest.GetSavepointDisposalStatus(context.Background(), &flinkApp, "hash", triggerID)
+	assert.Nil(t, err)
+	assert.Equal(t, client.SavePointCompleted, status.SavepointStatus.Status)
+}
+
+func TestCancelWithSavepointStopModes(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+	flinkApp.Spec.FlinkVersion = "1.9"
+
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	var stopped, drained bool
+	mockJmClient.StopJobWithSavepointFunc = func(ctx context.Context, url string, jobID string, drain bool) (string, error) {
+		assert.Equal(t, url, "http://app-name-hash.ns:8081")
+		assert.Equal(t, jobID, testJobID)
+		stopped = true
+		drained = drain
+		return "t1", nil
+	}
+
+	flinkApp.Spec.StopMode = v1alpha1.StopModeStop
+	triggerID, err := flinkControllerForTest.CancelWithSavepoint(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, "t1", triggerID)
+	assert.True(t, stopped)
+	assert.False(t, drained)
+
+	flinkApp.Spec.StopMode = v1alpha1.StopModeDrain
+	_, err = flinkControllerForTest.CancelWithSavepoint(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, drained)
+
+	stopped = false
+	cancelled := false
+	mockJmClient.CancelJobWithSavepointFunc = func(ctx context.Context, url string, jobID string) (string, error) {
+		cancelled = true
+		return "t2", nil
+	}
+	// jobs are cancelled by default, even on versions that can stop them
+	flinkApp.Spec.StopMode = ""
+	triggerID, err = flinkControllerForTest.CancelWithSavepoint(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, "t2", triggerID)
+	assert.True(t, cancelled)
+	assert.False(t, stopped)
+}
+
 func TestGetJobsForApplication(t *testing.T) {
 	flinkControllerForTest := getTestFlinkController()
 	flinkApp := getFlinkTestApp()
@@ -833,3 +1031,425 @@ func TestGetAndUpdateJobStatusHealth(t *testing.T) {
 	assert.Equal(t, app1.Status.JobStatus.Health, v1alpha1.Red)
 
 }
+
+func TestJobStatusVertices(t *testing.T) {
+	err := config.ConfigSection.SetConfig(&config.Config{
+		SampleBackpressure: true,
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, config.ConfigSection.SetConfig(&config.Config{}))
+	}()
+
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.GetJobOverviewFunc = func(ctx context.Context, url string, jobID string) (*client.FlinkJobOverview, error) {
+		return &client.FlinkJobOverview{
+			JobID: testJobID,
+			State: client.Running,
+			Vertices: []client.FlinkJobVertex{
+				{
+					ID:          "v1",
+					Name:        "Source",
+					Parallelism: 2,
+					Status:      client.Running,
+					Tasks: map[string]int32{
+						"RUNNING": 2,
+					},
+				},
+				{
+					ID:          "v2",
+					Name:        "Sink",
+					Parallelism: 2,
+					Status:      client.Running,
+					Tasks: map[string]int32{
+						"RUNNING": 2,
+					},
+				},
+			},
+		}, nil
+	}
+	mockJmClient.GetCheckpointCountsFunc = func(ctx context.Context, url string, jobID string) (*client.CheckpointResponse, error) {
+		return &client.CheckpointResponse{}, nil
+	}
+	mockJmClient.GetVertexBackpressureFunc = func(ctx context.Context, url string, jobID string, vertexID string) (*client.VertexBackpressureResponse, error) {
+		assert.Equal(t, testJobID, jobID)
+		if vertexID == "v1" {
+			return &client.VertexBackpressureResponse{
+				Status:            client.BackpressureStatusOk,
+				BackpressureLevel: client.BackpressureLevelHigh,
+			}, nil
+		}
+		return &client.VertexBackpressureResponse{
+			Status:            client.BackpressureStatusOk,
+			BackpressureLevel: client.BackpressureLevelOk,
+		}, nil
+	}
+
+	changed, err := flinkControllerForTest.CompareAndUpdateJobStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []v1alpha1.FlinkJobVertexStatus{
+		{
+			Name:              "Source",
+			Parallelism:       2,
+			Status:            v1alpha1.Running,
+			RunningTasks:      2,
+			BackpressureLevel: v1alpha1.BackpressureHigh,
+		},
+		{
+			Name:              "Sink",
+			Parallelism:       2,
+			Status:            v1alpha1.Running,
+			RunningTasks:      2,
+			BackpressureLevel: v1alpha1.BackpressureOk,
+		},
+	}, flinkApp.Status.JobStatus.Vertices)
+	assert.Equal(t, v1alpha1.Yellow, flinkApp.Status.JobStatus.Health)
+}
+
+func TestJobStatusVerticesWithoutBackpressureSample(t *testing.T) {
+	err := config.ConfigSection.SetConfig(&config.Config{
+		SampleBackpressure: true,
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, config.ConfigSection.SetConfig(&config.Config{}))
+	}()
+
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+	flinkApp.Status.JobStatus.Vertices = []v1alpha1.FlinkJobVertexStatus{
+		{
+			Name:              "Source",
+			BackpressureLevel: v1alpha1.BackpressureLow,
+		},
+	}
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.GetJobOverviewFunc = func(ctx context.Context, url string, jobID string) (*client.FlinkJobOverview, error) {
+		return &client.FlinkJobOverview{
+			JobID: testJobID,
+			State: client.Running,
+			Vertices: []client.FlinkJobVertex{
+				{
+					ID:          "v1",
+					Name:        "Source",
+					Parallelism: 2,
+					Status:      client.Running,
+					Tasks: map[string]int32{
+						"RUNNING": 2,
+					},
+				},
+			},
+		}, nil
+	}
+	mockJmClient.GetCheckpointCountsFunc = func(ctx context.Context, url string, jobID string) (*client.CheckpointResponse, error) {
+		return &client.CheckpointResponse{}, nil
+	}
+	// without a sample the level from the previous status is retained
+	mockJmClient.GetVertexBackpressureFunc = func(ctx context.Context, url string, jobID string, vertexID string) (*client.VertexBackpressureResponse, error) {
+		return nil, nil
+	}
+
+	_, err = flinkControllerForTest.CompareAndUpdateJobStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha1.BackpressureLow, flinkApp.Status.JobStatus.Vertices[0].BackpressureLevel)
+}
+
+func TestJobStatusPartiallyFailedVertex(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.GetJobOverviewFunc = func(ctx context.Context, url string, jobID string) (*client.FlinkJobOverview, error) {
+		return &client.FlinkJobOverview{
+			JobID: testJobID,
+			State: client.Running,
+			Vertices: []client.FlinkJobVertex{
+				{
+					ID:          "v1",
+					Name:        "Source",
+					Parallelism: 2,
+					Status:      client.Running,
+					Tasks: map[string]int32{
+						"RUNNING": 1,
+						"FAILED":  1,
+					},
+				},
+			},
+		}, nil
+	}
+	mockJmClient.GetCheckpointCountsFunc = func(ctx context.Context, url string, jobID string) (*client.CheckpointResponse, error) {
+		return &client.CheckpointResponse{}, nil
+	}
+	mockJmClient.GetVertexBackpressureFunc = func(ctx context.Context, url string, jobID string, vertexID string) (*client.VertexBackpressureResponse, error) {
+		// sampling is disabled by default
+		assert.False(t, true)
+		return nil, nil
+	}
+
+	_, err := flinkControllerForTest.CompareAndUpdateJobStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), flinkApp.Status.JobStatus.Vertices[0].FailedTasks)
+	assert.Empty(t, flinkApp.Status.JobStatus.Vertices[0].BackpressureLevel)
+	assert.Equal(t, v1alpha1.Yellow, flinkApp.Status.JobStatus.Health)
+}
+
+func TestGetPodFailures(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetPodsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.PodList, error) {
+		assert.Equal(t, testNamespace, namespace)
+		assert.Equal(t, map[string]string{"flink-app-hash": testAppHash}, labelMap)
+		return &coreV1.PodList{
+			Items: []coreV1.Pod{
+				{
+					ObjectMeta: metaV1.ObjectMeta{Name: "jm-pod"},
+					Status: coreV1.PodStatus{
+						ContainerStatuses: []coreV1.ContainerStatus{
+							{
+								Name: "jobmanager",
+								State: coreV1.ContainerState{
+									Waiting: &coreV1.ContainerStateWaiting{
+										Reason:  "ImagePullBackOff",
+										Message: "Back-off pulling image",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metaV1.ObjectMeta{Name: "tm-pod"},
+					Status: coreV1.PodStatus{
+						Conditions: []coreV1.PodCondition{
+							{
+								Type:    coreV1.PodScheduled,
+								Status:  coreV1.ConditionFalse,
+								Reason:  coreV1.PodReasonUnschedulable,
+								Message: "0/3 nodes are available: 3 Insufficient cpu.",
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metaV1.ObjectMeta{Name: "healthy-pod"},
+					Status: coreV1.PodStatus{
+						ContainerStatuses: []coreV1.ContainerStatus{
+							{
+								Name: "taskmanager",
+								LastTerminationState: coreV1.ContainerState{
+									Terminated: &coreV1.ContainerStateTerminated{
+										Reason: "OOMKilled",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}, nil
+	}
+
+	failures, err := flinkControllerForTest.GetPodFailures(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.Equal(t, []common.PodFailure{
+		{
+			PodName:       "jm-pod",
+			Reason:        "ImagePullBackOff",
+			Message:       "container jobmanager: Back-off pulling image",
+			Unrecoverable: true,
+		},
+		{
+			PodName: "tm-pod",
+			Reason:  "Unschedulable",
+			Message: "0/3 nodes are available: 3 Insufficient cpu.",
+		},
+		{
+			PodName: "healthy-pod",
+			Reason:  "OOMKilled",
+			Message: "container taskmanager was killed after exceeding its memory limit",
+		},
+	}, failures)
+}
+
+func TestGetPodFailuresErr(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetPodsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.PodList, error) {
+		return nil, errors.New("list failed")
+	}
+
+	failures, err := flinkControllerForTest.GetPodFailures(context.Background(), &flinkApp)
+	assert.Nil(t, failures)
+	assert.EqualError(t, err, "list failed")
+}
+
+func TestGetURLFromAppRestTLS(t *testing.T) {
+	flinkApp := getFlinkTestApp()
+	assert.Equal(t, "http://app-name-"+testAppHash+".ns:8081", getURLFromApp(&flinkApp, testAppHash))
+
+	flinkApp.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{
+		TLSEnabled:    true,
+		TLSSecretName: "flink-tls",
+	}
+	assert.Equal(t, "https://app-name-"+testAppHash+".ns:8081", getURLFromApp(&flinkApp, testAppHash))
+}
+
+func TestGetFlinkClientWithRestSecurity(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+	flinkApp.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{
+		AuthSecretName: "flink-auth",
+	}
+
+	secretVersion := "1"
+	secretReads := 0
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetSecretFunc = func(ctx context.Context, namespace string, name string) (*coreV1.Secret, error) {
+		secretReads++
+		assert.Equal(t, testNamespace, namespace)
+		assert.Equal(t, "flink-auth", name)
+		return &coreV1.Secret{
+			ObjectMeta: metaV1.ObjectMeta{
+				ResourceVersion: secretVersion,
+			},
+			Data: map[string][]byte{
+				RestAuthTokenKey: []byte("token"),
+			},
+		}, nil
+	}
+
+	secureClients := 0
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.WithSecurityFunc = func(security client.RestSecurity) client.FlinkAPIInterface {
+		secureClients++
+		assert.Nil(t, security.TLSConfig)
+		assert.Equal(t, "token", security.BearerToken)
+		return &clientMock.JobManagerClient{}
+	}
+
+	first, err := flinkControllerForTest.getFlinkClient(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	second, err := flinkControllerForTest.getFlinkClient(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, first == second)
+	assert.Equal(t, 1, secureClients)
+
+	// the client is rebuilt once the secret changes
+	secretVersion = "2"
+	third, err := flinkControllerForTest.getFlinkClient(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.False(t, first == third)
+	assert.Equal(t, 2, secureClients)
+	assert.Equal(t, 3, secretReads)
+
+	// the secret is read once per reconcile
+	ctx := WithReconcileCache(context.Background())
+	fourth, err := flinkControllerForTest.getFlinkClient(ctx, &flinkApp, "hash")
+	assert.Nil(t, err)
+	fifth, err := flinkControllerForTest.getFlinkClient(ctx, &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, third == fourth && fourth == fifth)
+	assert.Equal(t, 4, secretReads)
+
+	// and the client is released once the application is deleted
+	flinkControllerForTest.RemoveApp(types.NamespacedName{Namespace: flinkApp.Namespace, Name: flinkApp.Name})
+	assert.Empty(t, flinkControllerForTest.secureClients)
+	_, err = flinkControllerForTest.getFlinkClient(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, secureClients)
+}
+
+func TestGetFlinkClientInvalidRestSecurity(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetSecretFunc = func(ctx context.Context, namespace string, name string) (*coreV1.Secret, error) {
+		return &coreV1.Secret{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name: name,
+			},
+			Data: map[string][]byte{
+				RestCACertKey: []byte("not a certificate"),
+			},
+		}, nil
+	}
+
+	flinkApp.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{
+		TLSEnabled: true,
+	}
+	_, err := flinkControllerForTest.getFlinkClient(context.Background(), &flinkApp, "hash")
+	assert.EqualError(t, err, "ErrorCode: [BadJobSpecificationError] Reason: [tlsSecretName must be set when TLS is enabled]")
+
+	flinkApp.Spec.RestSecurity.TLSSecretName = "flink-tls"
+	_, err = flinkControllerForTest.getFlinkClient(context.Background(), &flinkApp, "hash")
+	assert.EqualError(t, err, "ErrorCode: [BadJobSpecificationError] Reason: [failed to parse ca.crt from secret flink-tls]")
+
+	flinkApp.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{
+		AuthSecretName: "flink-auth",
+	}
+	_, err = flinkControllerForTest.getFlinkClient(context.Background(), &flinkApp, "hash")
+	assert.EqualError(t, err, "ErrorCode: [BadJobSpecificationError] Reason: [auth secret flink-auth must contain either token or username]")
+}
+
+func TestControllerWithFakeJobManager(t *testing.T) {
+	jm := fake.NewJobManager(fake.Config{
+		SlotsPerTaskManager: 8,
+	})
+	server := httptest.NewServer(jm)
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	serverPort, _ := strconv.Atoi(serverURL.Port())
+	err := config.ConfigSection.SetConfig(&config.Config{
+		UseProxy:  true,
+		ProxyPort: flyteConfig.Port{Port: serverPort},
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, config.ConfigSection.SetConfig(&config.Config{}))
+	}()
+
+	ctx := context.Background()
+	flinkControllerForTest := getTestFlinkController()
+	flinkControllerForTest.flinkClient = client.NewFlinkJobManagerClient(config.RuntimeConfig{
+		MetricsScope: mockScope.NewTestScope(),
+	})
+	flinkApp := getFlinkTestApp()
+
+	jobID, err := flinkControllerForTest.StartFlinkJob(ctx, &flinkApp, "hash",
+		flinkApp.Spec.JarName, flinkApp.Spec.Parallelism, flinkApp.Spec.EntryClass, flinkApp.Spec.ProgramArgs)
+	assert.Nil(t, err)
+	flinkApp.Status.JobStatus.JobID = jobID
+
+	_, err = flinkControllerForTest.CompareAndUpdateJobStatus(ctx, &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha1.JobState(client.Running), flinkApp.Status.JobStatus.State)
+
+	triggerID, err := flinkControllerForTest.CancelWithSavepoint(ctx, &flinkApp, "hash")
+	assert.Nil(t, err)
+	flinkApp.Spec.SavepointInfo.TriggerID = triggerID
+
+	savepoint, err := flinkControllerForTest.GetSavepointStatus(ctx, &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, client.SavePointCompleted, savepoint.SavepointStatus.Status)
+	assert.NotEmpty(t, savepoint.Operation.Location)
+
+	jobs, err := flinkControllerForTest.GetJobsForApplication(ctx, &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Equal(t, client.Canceled, jobs[0].Status)
+
+	disposalID, err := flinkControllerForTest.DisposeSavepoint(ctx, &flinkApp, "hash", savepoint.Operation.Location)
+	assert.Nil(t, err)
+	disposal, err := flinkControllerForTest.GetSavepointDisposalStatus(ctx, &flinkApp, "hash", disposalID)
+	assert.Nil(t, err)
+	assert.Equal(t, client.SavePointCompleted, disposal.SavepointStatus.Status)
+	assert.Equal(t, client.FailureCause{}, disposal.Operation.FailureCause)
+	assert.False(t, jm.HasSavepoint(savepoint.Operation.Location))
+}
@@ -0,0 +1,98 @@
+package flink
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Returns the hashes of the clusters of the application that are neither deployed nor being deployed, and whose
+// objects were all created before the given time. These are left behind by deploys that failed or were interrupted
+// part way, and unlike the old clusters returned by GetCurrentAndOldDeploymentsForApp may not have a complete set of
+// deployments, or any deployments at all.
+func (f *Controller) GetOrphanedClusters(ctx context.Context, application *v1alpha1.FlinkApplication,
+	createdBefore time.Time) ([]string, error) {
+	keep := map[string]bool{
+		"":                              true,
+		application.Status.DeployHash:   true,
+		HashForApplication(application): true,
+	}
+
+	// the creation time of the most recently created object of each cluster
+	lastCreated := map[string]time.Time{}
+	add := func(hash string, object metaV1.Object) {
+		// objects that are already being deleted are left to Kubernetes
+		if keep[hash] || object.GetDeletionTimestamp() != nil {
+			return
+		}
+		created := object.GetCreationTimestamp().Time
+		if last, ok := lastCreated[hash]; !ok || created.After(last) {
+			lastCreated[hash] = created
+		}
+	}
+
+	appLabels := k8.GetAppLabel(application.Name)
+	deployments, err := f.k8Cluster.GetDeploymentsWithLabel(ctx, application.Namespace, appLabels)
+	if err != nil {
+		return nil, err
+	}
+	if deployments != nil {
+		for i := range deployments.Items {
+			add(deployments.Items[i].Labels[FlinkAppHash], &deployments.Items[i])
+		}
+	}
+
+	statefulSets, err := f.k8Cluster.GetStatefulSetsWithLabel(ctx, application.Namespace, appLabels)
+	if err != nil {
+		return nil, err
+	}
+	if statefulSets != nil {
+		for i := range statefulSets.Items {
+			add(statefulSets.Items[i].Labels[FlinkAppHash], &statefulSets.Items[i])
+		}
+	}
+
+	// the generic service shared by all clusters has no hash label, so it is always kept
+	services, err := f.k8Cluster.GetServicesWithLabel(ctx, application.Namespace, appLabels)
+	if err != nil {
+		return nil, err
+	}
+	if services != nil {
+		for i := range services.Items {
+			add(services.Items[i].Labels[FlinkAppHash], &services.Items[i])
+		}
+	}
+
+	configMaps, err := f.k8Cluster.GetConfigMapsWithLabel(ctx, application.Namespace, appLabels)
+	if err != nil {
+		return nil, err
+	}
+	if configMaps != nil {
+		for i := range configMaps.Items {
+			add(configMaps.Items[i].Labels[FlinkAppHash], &configMaps.Items[i])
+		}
+	}
+
+	pdbs, err := f.k8Cluster.GetPodDisruptionBudgetsWithLabel(ctx, application.Namespace, appLabels)
+	if err != nil {
+		return nil, err
+	}
+	if pdbs != nil {
+		for i := range pdbs.Items {
+			add(pdbs.Items[i].Labels[FlinkAppHash], &pdbs.Items[i])
+		}
+	}
+
+	var orphaned []string
+	for hash, created := range lastCreated {
+		if created.Before(createdBefore) {
+			orphaned = append(orphaned, hash)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned, nil
+}
@@ -0,0 +1,74 @@
+package flink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	k8mock "github.com/lyft/flinkk8soperator/pkg/controller/k8/mock"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetOrphanedClusters(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	app := getFlinkTestApp()
+	app.Status.DeployHash = "deployed"
+	now := time.Now()
+	hourAgo := metaV1.NewTime(now.Add(-time.Hour))
+	minuteAgo := metaV1.NewTime(now.Add(-time.Minute))
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetDeploymentsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error) {
+		assert.Equal(t, testNamespace, namespace)
+		assert.Equal(t, testAppName, labelMap["flink-app"])
+		deployed := FetchJobMangerDeploymentCreateObj(&app, "deployed")
+		deployed.CreationTimestamp = hourAgo
+		inFlight := FetchTaskMangerDeploymentCreateObj(&app, testAppHash)
+		inFlight.CreationTimestamp = hourAgo
+		old := FetchJobMangerDeploymentCreateObj(&app, "old")
+		old.CreationTimestamp = hourAgo
+		return &v1.DeploymentList{Items: []v1.Deployment{*deployed, *inFlight, *old}}, nil
+	}
+
+	mockK8Cluster.GetServicesWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.ServiceList, error) {
+		assert.Equal(t, testNamespace, namespace)
+		assert.Equal(t, testAppName, labelMap["flink-app"])
+		// the cluster of a deploy that failed after creating its versioned service
+		partial := FetchVersionedJobManagerServiceCreateObj(&app, "partial")
+		partial.CreationTimestamp = hourAgo
+
+		// the generic service is shared by all clusters
+		generic := FetchJobManagerServiceCreateObj(&app, "partial")
+		generic.Labels = getCommonAppLabels(&app)
+		generic.CreationTimestamp = hourAgo
+		return &coreV1.ServiceList{Items: []coreV1.Service{*partial, *generic}}, nil
+	}
+
+	mockK8Cluster.GetConfigMapsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.ConfigMapList, error) {
+		// created within the grace period
+		recent, err := FetchConfigMapCreateObj(&app, "recent")
+		assert.Nil(t, err)
+		recent.CreationTimestamp = minuteAgo
+		return &coreV1.ConfigMapList{Items: []coreV1.ConfigMap{*recent}}, nil
+	}
+
+	mockK8Cluster.GetPodDisruptionBudgetsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*policyV1beta1.PodDisruptionBudgetList, error) {
+		deleting := FetchJobManagerPodDisruptionBudgetCreateObj(&app, "deleting")
+		deleting.CreationTimestamp = hourAgo
+		deleting.DeletionTimestamp = &minuteAgo
+		return &policyV1beta1.PodDisruptionBudgetList{Items: []policyV1beta1.PodDisruptionBudget{*deleting}}, nil
+	}
+
+	orphaned, err := flinkControllerForTest.GetOrphanedClusters(context.Background(), &app, now.Add(-10*time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"old", "partial"}, orphaned)
+
+	// nothing is orphaned until the grace period has passed
+	orphaned, err = flinkControllerForTest.GetOrphanedClusters(context.Background(), &app, now.Add(-2*time.Hour))
+	assert.Nil(t, err)
+	assert.Empty(t, orphaned)
+}
@@ -0,0 +1,286 @@
+package flink
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/lyft/flytestdlib/logger"
+	coreV1 "k8s.io/api/core/v1"
+	rbacV1 "k8s.io/api/rbac/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	HighAvailabilityServiceAccountNameFormat = "%s-flink-ha"
+	KubernetesHaServicesFactory              = "org.apache.flink.kubernetes.highavailability.KubernetesHaServicesFactory"
+	// The ConfigMap in which the leading JobManager publishes the address of its REST endpoint
+	RestServerLeaderConfigMapFormat = "%s-restserver-leader"
+	LeaderAddressKey                = "address"
+	// The labels Flink sets on the ConfigMaps it creates for Kubernetes HA, where the app label is the cluster id
+	HighAvailabilityConfigMapAppLabel  = "app"
+	HighAvailabilityConfigMapTypeLabel = "configmap-type"
+	HighAvailabilityConfigMapType      = "high-availability"
+)
+
+func isHighAvailabilityEnabled(app *v1alpha1.FlinkApplication) bool {
+	return app.Spec.JobManagerConfig.HighAvailability != nil
+}
+
+func isKubernetesHighAvailabilityEnabled(app *v1alpha1.FlinkApplication) bool {
+	return isHighAvailabilityEnabled(app) &&
+		app.Spec.JobManagerConfig.HighAvailability.Mode == v1alpha1.HighAvailabilityModeKubernetes
+}
+
+func isZooKeeperHighAvailabilityEnabled(app *v1alpha1.FlinkApplication) bool {
+	return isHighAvailabilityEnabled(app) &&
+		app.Spec.JobManagerConfig.HighAvailability.Mode == v1alpha1.HighAvailabilityModeZooKeeper
+}
+
+// Each version of the application is a separate Flink cluster, so the clusters must not share their HA data
+func getHighAvailabilityClusterID(app *v1alpha1.FlinkApplication, hash string) string {
+	return fmt.Sprintf("%s-%s", app.Name, hash)
+}
+
+func addHighAvailabilityConfig(app *v1alpha1.FlinkApplication, config *v1alpha1.FlinkConfig) {
+	if !isHighAvailabilityEnabled(app) {
+		return
+	}
+
+	ha := app.Spec.JobManagerConfig.HighAvailability
+	(*config)["high-availability.storageDir"] = ha.StorageDir
+	switch ha.Mode {
+	case v1alpha1.HighAvailabilityModeZooKeeper:
+		(*config)["high-availability"] = "zookeeper"
+		(*config)["high-availability.zookeeper.quorum"] = ha.ZookeeperQuorum
+		if ha.ZookeeperPathRoot != "" {
+			(*config)["high-availability.zookeeper.path.root"] = ha.ZookeeperPathRoot
+		}
+	case v1alpha1.HighAvailabilityModeKubernetes:
+		(*config)["high-availability"] = KubernetesHaServicesFactory
+		(*config)["kubernetes.namespace"] = app.Namespace
+	}
+}
+
+// Returns the service account the pods of the application run as, which is only set with Kubernetes HA so that the
+// hash of other applications is unchanged
+func getServiceAccountName(app *v1alpha1.FlinkApplication) string {
+	if !isKubernetesHighAvailabilityEnabled(app) {
+		return ""
+	}
+	if app.Spec.JobManagerConfig.HighAvailability.ServiceAccountName != "" {
+		return app.Spec.JobManagerConfig.HighAvailability.ServiceAccountName
+	}
+	return fmt.Sprintf(HighAvailabilityServiceAccountNameFormat, app.Name)
+}
+
+// Returns true if the operator has to create the service account used for Kubernetes HA, along with the role and
+// binding allowing it to manage the leader election ConfigMaps
+func needsHighAvailabilityServiceAccount(app *v1alpha1.FlinkApplication) bool {
+	return isKubernetesHighAvailabilityEnabled(app) && app.Spec.JobManagerConfig.HighAvailability.ServiceAccountName == ""
+}
+
+func getHighAvailabilityObjectMeta(app *v1alpha1.FlinkApplication) metaV1.ObjectMeta {
+	return metaV1.ObjectMeta{
+		Name:      getServiceAccountName(app),
+		Namespace: app.Namespace,
+		Labels:    getCommonAppLabels(app),
+		OwnerReferences: []metaV1.OwnerReference{
+			*metaV1.NewControllerRef(app, app.GroupVersionKind()),
+		},
+	}
+}
+
+// Like the generic JobManager service, the service account, role and binding are shared by all versions of the
+// application
+func FetchHighAvailabilityServiceAccountCreateObj(app *v1alpha1.FlinkApplication) *coreV1.ServiceAccount {
+	return &coreV1.ServiceAccount{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       k8.ServiceAccount,
+		},
+		ObjectMeta: getHighAvailabilityObjectMeta(app),
+	}
+}
+
+func FetchHighAvailabilityRoleCreateObj(app *v1alpha1.FlinkApplication) *rbacV1.Role {
+	return &rbacV1.Role{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: rbacV1.SchemeGroupVersion.String(),
+			Kind:       k8.Role,
+		},
+		ObjectMeta: getHighAvailabilityObjectMeta(app),
+		Rules: []rbacV1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+		}},
+	}
+}
+
+func FetchHighAvailabilityRoleBindingCreateObj(app *v1alpha1.FlinkApplication) *rbacV1.RoleBinding {
+	return &rbacV1.RoleBinding{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: rbacV1.SchemeGroupVersion.String(),
+			Kind:       k8.RoleBinding,
+		},
+		ObjectMeta: getHighAvailabilityObjectMeta(app),
+		Subjects: []rbacV1.Subject{{
+			Kind:      rbacV1.ServiceAccountKind,
+			Name:      getServiceAccountName(app),
+			Namespace: app.Namespace,
+		}},
+		RoleRef: rbacV1.RoleRef{
+			APIGroup: rbacV1.GroupName,
+			Kind:     k8.Role,
+			Name:     getServiceAccountName(app),
+		},
+	}
+}
+
+// Returns the URL of the REST endpoint of the leading JobManager, or an empty string if no leader has been elected
+// yet. The leader is only known to the operator with Kubernetes HA, where it is published in a ConfigMap. As the URL is
+// needed for every request to the cluster, the ConfigMap is read at most once per reconcile.
+func (f *Controller) getLeaderURL(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) (string, error) {
+	leaderURL, err := resolveOnce(ctx, "leaderURL/"+hash, app, func() (interface{}, error) {
+		return f.readLeaderURL(ctx, app, hash)
+	})
+	if err != nil {
+		return "", err
+	}
+	return leaderURL.(string), nil
+}
+
+func (f *Controller) readLeaderURL(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) (string, error) {
+	name := fmt.Sprintf(RestServerLeaderConfigMapFormat, getHighAvailabilityClusterID(app, hash))
+	configMap, err := f.k8Cluster.GetConfigMap(ctx, app.Namespace, name)
+	if err != nil {
+		if k8.IsK8sObjectDoesNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if configMap == nil {
+		return "", nil
+	}
+	return getLeaderURLFromAddress(app, configMap.Data[LeaderAddressKey])
+}
+
+// Flink publishes the address of the leader's REST endpoint along with the scheme it is served with. The scheme is
+// added if it is missing, and an address whose scheme does not match the REST security of the application is rejected,
+// as the operator could not connect to it.
+func getLeaderURLFromAddress(app *v1alpha1.FlinkApplication, address string) (string, error) {
+	if address == "" {
+		return "", nil
+	}
+	scheme := getRestScheme(app)
+	if !strings.Contains(address, "://") {
+		address = fmt.Sprintf("%s://%s", scheme, address)
+	}
+	leaderURL, err := url.Parse(address)
+	if err != nil || leaderURL.Hostname() == "" {
+		return "", flinkErrors.Errorf(flinkErrors.IllegalStateError, "invalid leader address %s", address)
+	}
+	if leaderURL.Scheme != scheme {
+		return "", flinkErrors.Errorf(flinkErrors.IllegalStateError,
+			"the leader address %s does not use the scheme %s of the application's REST endpoint", address, scheme)
+	}
+	host := leaderURL.Host
+	if leaderURL.Port() == "" {
+		host = net.JoinHostPort(leaderURL.Hostname(), strconv.Itoa(port))
+	}
+	return fmt.Sprintf("%s://%s", scheme, host), nil
+}
+
+// Returns the URL of the REST endpoint of the cluster. With Kubernetes HA, requests are sent directly to the leading
+// JobManager, while otherwise they go through the versioned service, whose JobManagers forward them to the leader.
+func (f *Controller) getJobManagerURL(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) string {
+	// pods cannot be addressed directly through the API server proxy, and the certificates of a REST endpoint served
+	// over TLS are issued for the service rather than the addresses of the pods
+	if !isKubernetesHighAvailabilityEnabled(app) || config.GetConfig().UseProxy || isRestTLSEnabled(app) {
+		return getURLFromApp(app, hash)
+	}
+
+	leaderURL, err := f.getLeaderURL(ctx, app, hash)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to find the leading job manager, falling back to the service: %v", err)
+	}
+	if leaderURL == "" {
+		return getURLFromApp(app, hash)
+	}
+	return leaderURL
+}
+
+// With ZooKeeper HA the leader is not known to the operator, and requests go through the versioned service to any of
+// the JobManagers. A JobManager only answers once it has found the leader to forward the request to, so the cluster is
+// ready once each of them answers. Returns true without asking the JobManagers if they cannot be addressed directly.
+func (f *Controller) haveJobManagersFoundLeader(ctx context.Context, flinkClient client.FlinkAPIInterface,
+	app *v1alpha1.FlinkApplication, hash string) (bool, error) {
+	if config.GetConfig().UseProxy || isRestTLSEnabled(app) {
+		return true, nil
+	}
+
+	labelMap := getCommonAppLabels(app)
+	labelMap[FlinkAppHash] = hash
+	labelMap[FlinkDeploymentType] = FlinkDeploymentTypeJobmanager
+	podList, err := f.k8Cluster.GetPodsWithLabel(ctx, app.Namespace, labelMap)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get pods for label map %v", labelMap)
+		return false, err
+	}
+	if podList == nil || len(podList.Items) == 0 {
+		return false, nil
+	}
+
+	for _, pod := range podList.Items {
+		if pod.Status.PodIP == "" {
+			return false, nil
+		}
+		podURL := fmt.Sprintf("%s://%s", getRestScheme(app), net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)))
+		_, err := flinkClient.GetClusterOverview(ctx, podURL)
+		// the pods are only addressed directly here, so they are not tracked by the circuit breaker
+		flinkClient.ForgetJobManager(podURL)
+		if err != nil {
+			logger.Infof(ctx, "Job manager %s has not found the leader yet: %v", pod.Name, err)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Flink does not delete the ConfigMaps it creates for Kubernetes HA when the cluster is deleted. They are labeled with
+// the cluster id, which is unique to each version of the application.
+func (f *Controller) deleteHighAvailabilityConfigMaps(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) error {
+	if !isKubernetesHighAvailabilityEnabled(app) {
+		return nil
+	}
+
+	labelMap := map[string]string{
+		HighAvailabilityConfigMapAppLabel:  getHighAvailabilityClusterID(app, hash),
+		HighAvailabilityConfigMapTypeLabel: HighAvailabilityConfigMapType,
+	}
+	configMaps, err := f.k8Cluster.GetConfigMapsWithLabel(ctx, app.Namespace, labelMap)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get config maps for label map %v", labelMap)
+		return err
+	}
+	if configMaps == nil {
+		return nil
+	}
+	for i := range configMaps.Items {
+		err = f.k8Cluster.DeleteK8Object(ctx, &configMaps.Items[i])
+		if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+			logger.Warnf(ctx, "Failed to delete high availability config map %s", configMaps.Items[i].Name)
+			return err
+		}
+	}
+	return nil
+}
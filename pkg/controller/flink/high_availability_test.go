@@ -0,0 +1,329 @@
+package flink
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	clientMock "github.com/lyft/flinkk8soperator/pkg/controller/flink/client/mock"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/mock"
+	k8mock "github.com/lyft/flinkk8soperator/pkg/controller/k8/mock"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	rbacV1 "k8s.io/api/rbac/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func getHighAvailabilityTestApp(mode v1alpha1.HighAvailabilityMode) v1alpha1.FlinkApplication {
+	app := getFlinkTestApp()
+	app.Spec.FlinkVersion = "1.12"
+	replicas := int32(2)
+	app.Spec.JobManagerConfig.Replicas = &replicas
+	app.Spec.JobManagerConfig.HighAvailability = &v1alpha1.HighAvailabilityConfig{
+		Mode:            mode,
+		StorageDir:      "s3://bucket/flink/ha",
+		ZookeeperQuorum: "zk-0.zk:2181",
+	}
+	return app
+}
+
+func TestZooKeeperHighAvailabilityDeployment(t *testing.T) {
+	app := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeZooKeeper)
+	hash := HashForApplication(&app)
+
+	deployment := FetchJobMangerDeploymentCreateObj(&app, hash)
+	assert.Equal(t, v1.RollingUpdateDeploymentStrategyType, deployment.Spec.Strategy.Type)
+	assert.Empty(t, deployment.Spec.Template.Spec.ServiceAccountName)
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "status.podIP", common.GetEnvVar(container.Env, JobManagerHostnameEnvVar).ValueFrom.FieldRef.FieldPath)
+	assert.Equal(t, []string{JobManagerArg}, container.Args)
+
+	flinkConfig := common.GetEnvVar(container.Env, OperatorFlinkConfig).Value
+	assert.Contains(t, flinkConfig, "high-availability: zookeeper\n")
+	assert.Contains(t, flinkConfig, "high-availability.storageDir: s3://bucket/flink/ha\n")
+	assert.Contains(t, flinkConfig, "high-availability.zookeeper.quorum: zk-0.zk:2181\n")
+	assert.Contains(t, flinkConfig, "high-availability.cluster-id: "+app.Name+"-"+hash+"\n")
+	// the JobManagers advertise their own addresses instead of the service
+	assert.NotContains(t, flinkConfig, "jobmanager.rpc.address")
+	assert.NotContains(t, flinkConfig, "kubernetes.cluster-id")
+
+	// which are passed as dynamic properties in the ConfigMap config mode
+	app.Spec.ConfigMode = v1alpha1.ConfigModeConfigMap
+	container = FetchJobMangerDeploymentCreateObj(&app, hash).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{JobManagerArg, "-Djobmanager.rpc.address=$(JOBMANAGER_HOSTNAME)"}, container.Args)
+
+	// applications without HA are unchanged
+	app = getFlinkTestApp()
+	assert.Equal(t, testAppHash, HashForApplication(&app))
+	deployment = FetchJobMangerDeploymentCreateObj(&app, testAppHash)
+	assert.Equal(t, v1.RecreateDeploymentStrategyType, deployment.Spec.Strategy.Type)
+	assert.Nil(t, common.GetEnvVar(deployment.Spec.Template.Spec.Containers[0].Env, JobManagerHostnameEnvVar))
+}
+
+func TestKubernetesHighAvailabilityDeployment(t *testing.T) {
+	app := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeKubernetes)
+	hash := HashForApplication(&app)
+
+	jmDeployment := FetchJobMangerDeploymentCreateObj(&app, hash)
+	assert.Equal(t, app.Name+"-flink-ha", jmDeployment.Spec.Template.Spec.ServiceAccountName)
+	tmDeployment := FetchTaskMangerDeploymentCreateObj(&app, hash)
+	assert.Equal(t, app.Name+"-flink-ha", tmDeployment.Spec.Template.Spec.ServiceAccountName)
+
+	flinkConfig := common.GetEnvVar(jmDeployment.Spec.Template.Spec.Containers[0].Env, OperatorFlinkConfig).Value
+	assert.Contains(t, flinkConfig, "high-availability: "+KubernetesHaServicesFactory+"\n")
+	assert.Contains(t, flinkConfig, "kubernetes.namespace: "+app.Namespace+"\n")
+	assert.Contains(t, flinkConfig, "kubernetes.cluster-id: "+app.Name+"-"+hash+"\n")
+	assert.NotContains(t, flinkConfig, "zookeeper")
+
+	role := FetchHighAvailabilityRoleCreateObj(&app)
+	assert.Equal(t, []string{"configmaps"}, role.Rules[0].Resources)
+	binding := FetchHighAvailabilityRoleBindingCreateObj(&app)
+	assert.Equal(t, app.Name+"-flink-ha", binding.Subjects[0].Name)
+	assert.Equal(t, role.Name, binding.RoleRef.Name)
+
+	// a service account provided by the user is used as is
+	app.Spec.JobManagerConfig.HighAvailability.ServiceAccountName = "flink"
+	assert.False(t, needsHighAvailabilityServiceAccount(&app))
+	jmDeployment = FetchJobMangerDeploymentCreateObj(&app, HashForApplication(&app))
+	assert.Equal(t, "flink", jmDeployment.Spec.Template.Spec.ServiceAccountName)
+}
+
+func TestCreateClusterWithKubernetesHighAvailability(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeKubernetes)
+	mockJobManager := flinkControllerForTest.jobManager.(*mock.JobManagerController)
+	mockTaskManager := flinkControllerForTest.taskManager.(*mock.TaskManagerController)
+
+	mockJobManager.CreateIfNotExistFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+		return false, nil
+	}
+	mockTaskManager.CreateIfNotExistFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+		return false, nil
+	}
+	var kinds []string
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.CreateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		switch object.(type) {
+		case *coreV1.ServiceAccount, *rbacV1.Role, *rbacV1.RoleBinding:
+			kinds = append(kinds, object.GetObjectKind().GroupVersionKind().Kind)
+		case *coreV1.Event:
+		default:
+			assert.Fail(t, "unexpected object created")
+		}
+		return nil
+	}
+	err := flinkControllerForTest.CreateCluster(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ServiceAccount", "Role", "RoleBinding"}, kinds)
+}
+
+func TestFlinkIsClusterReadyHighAvailability(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeZooKeeper)
+	hash := HashForApplication(&flinkApp)
+
+	jmAvailable := int32(1)
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetDeploymentsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error) {
+		jmDeployment := FetchJobMangerDeploymentCreateObj(&flinkApp, hash)
+		jmDeployment.Status.AvailableReplicas = jmAvailable
+		tmDeployment := FetchTaskMangerDeploymentCreateObj(&flinkApp, hash)
+		tmDeployment.Status.AvailableReplicas = *tmDeployment.Spec.Replicas
+		return &v1.DeploymentList{
+			Items: []v1.Deployment{*jmDeployment, *tmDeployment},
+		}, nil
+	}
+
+	// the standby JobManager is not available yet
+	result, err := flinkControllerForTest.IsClusterReady(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.False(t, result)
+
+	jmAvailable = 2
+	result, err = flinkControllerForTest.IsClusterReady(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.True(t, result)
+}
+
+func TestFlinkIsServiceReadyKubernetesHighAvailability(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeKubernetes)
+
+	leaderAddress := ""
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetConfigMapFunc = func(ctx context.Context, namespace string, name string) (*coreV1.ConfigMap, error) {
+		assert.Equal(t, testNamespace, namespace)
+		assert.Equal(t, "app-name-hash-restserver-leader", name)
+		if leaderAddress == "" {
+			return nil, k8sErrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+		}
+		return &coreV1.ConfigMap{
+			Data: map[string]string{LeaderAddressKey: leaderAddress},
+		}, nil
+	}
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.GetClusterOverviewFunc = func(ctx context.Context, url string) (*client.ClusterOverviewResponse, error) {
+		assert.Equal(t, "http://10.0.0.5:8081", url)
+		return &client.ClusterOverviewResponse{}, nil
+	}
+
+	// no leader has been elected yet
+	isReady, err := flinkControllerForTest.IsServiceReady(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.False(t, isReady)
+
+	// requests are sent to the leader
+	leaderAddress = "http://10.0.0.5:8081"
+	isReady, err = flinkControllerForTest.IsServiceReady(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, isReady)
+}
+
+func TestGetLeaderURLOncePerReconcile(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeKubernetes)
+
+	configMapReads := 0
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetConfigMapFunc = func(ctx context.Context, namespace string, name string) (*coreV1.ConfigMap, error) {
+		configMapReads++
+		return &coreV1.ConfigMap{
+			Data: map[string]string{LeaderAddressKey: "http://10.0.0.5:8081"},
+		}, nil
+	}
+
+	ctx := WithReconcileCache(context.Background())
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "http://10.0.0.5:8081", flinkControllerForTest.getJobManagerURL(ctx, &flinkApp, "hash"))
+	}
+	assert.Equal(t, 1, configMapReads)
+
+	// each version of the cluster has its own leader
+	assert.Equal(t, "http://10.0.0.5:8081", flinkControllerForTest.getJobManagerURL(ctx, &flinkApp, "old-hash"))
+	assert.Equal(t, 2, configMapReads)
+
+	// and the next reconcile reads it again
+	flinkControllerForTest.getJobManagerURL(WithReconcileCache(context.Background()), &flinkApp, "hash")
+	assert.Equal(t, 3, configMapReads)
+}
+
+func TestGetLeaderURLFromAddress(t *testing.T) {
+	app := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeKubernetes)
+
+	leaderURL, err := getLeaderURLFromAddress(&app, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "", leaderURL)
+
+	leaderURL, err = getLeaderURLFromAddress(&app, "http://10.0.0.5:8081")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://10.0.0.5:8081", leaderURL)
+
+	leaderURL, err = getLeaderURLFromAddress(&app, "10.0.0.5:8081")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://10.0.0.5:8081", leaderURL)
+
+	leaderURL, err = getLeaderURLFromAddress(&app, "http://10.0.0.5")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://10.0.0.5:8081", leaderURL)
+
+	_, err = getLeaderURLFromAddress(&app, "https://10.0.0.5:8081")
+	assert.EqualError(t, err, "ErrorCode: [IllegalStateError] Reason: [the leader address https://10.0.0.5:8081 does not use the scheme http of the application's REST endpoint]")
+
+	app.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{TLSEnabled: true}
+	leaderURL, err = getLeaderURLFromAddress(&app, "10.0.0.5:8081")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://10.0.0.5:8081", leaderURL)
+}
+
+func TestGetJobManagerURLKubernetesHighAvailabilityWithTLS(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeKubernetes)
+	flinkApp.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{TLSEnabled: true}
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetConfigMapFunc = func(ctx context.Context, namespace string, name string) (*coreV1.ConfigMap, error) {
+		return &coreV1.ConfigMap{
+			Data: map[string]string{LeaderAddressKey: "https://10.0.0.5:8081"},
+		}, nil
+	}
+
+	// the certificate is issued for the service, so requests are not sent to the address of the leader's pod
+	assert.Equal(t, getURLFromApp(&flinkApp, "hash"), flinkControllerForTest.getJobManagerURL(context.Background(), &flinkApp, "hash"))
+}
+
+func TestFlinkIsServiceReadyZooKeeperHighAvailability(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeZooKeeper)
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetPodsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.PodList, error) {
+		assert.Equal(t, "hash", labelMap[FlinkAppHash])
+		assert.Equal(t, FlinkDeploymentTypeJobmanager, labelMap[FlinkDeploymentType])
+		return &coreV1.PodList{
+			Items: []coreV1.Pod{
+				{Status: coreV1.PodStatus{PodIP: "10.0.0.5"}},
+				{Status: coreV1.PodStatus{PodIP: "10.0.0.6"}},
+			},
+		}, nil
+	}
+
+	standbyReady := false
+	var forgotten []string
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.GetClusterOverviewFunc = func(ctx context.Context, url string) (*client.ClusterOverviewResponse, error) {
+		if url == "http://10.0.0.6:8081" && !standbyReady {
+			return nil, errors.New("service temporarily unavailable due to an ongoing leader election")
+		}
+		return &client.ClusterOverviewResponse{}, nil
+	}
+	mockJmClient.ForgetJobManagerFunc = func(url string) {
+		forgotten = append(forgotten, url)
+	}
+
+	// the standby JobManager has not found the leader yet
+	isReady, err := flinkControllerForTest.IsServiceReady(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.False(t, isReady)
+
+	standbyReady = true
+	isReady, err = flinkControllerForTest.IsServiceReady(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, isReady)
+	assert.Contains(t, forgotten, "http://10.0.0.6:8081")
+}
+
+func TestDeleteClusterKubernetesHighAvailability(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getHighAvailabilityTestApp(v1alpha1.HighAvailabilityModeKubernetes)
+
+	leaderConfigMap := coreV1.ConfigMap{}
+	leaderConfigMap.Name = "app-name-hash-restserver-leader"
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetConfigMapFunc = func(ctx context.Context, namespace string, name string) (*coreV1.ConfigMap, error) {
+		return nil, k8sErrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+	}
+	mockK8Cluster.GetConfigMapsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.ConfigMapList, error) {
+		assert.Equal(t, testNamespace, namespace)
+		assert.Equal(t, map[string]string{
+			"app":            "app-name-hash",
+			"configmap-type": "high-availability",
+		}, labelMap)
+		return &coreV1.ConfigMapList{Items: []coreV1.ConfigMap{leaderConfigMap}}, nil
+	}
+	var deleted []runtime.Object
+	mockK8Cluster.DeleteK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		deleted = append(deleted, object)
+		return nil
+	}
+
+	err := flinkControllerForTest.DeleteCluster(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.Contains(t, deleted, &leaderConfigMap)
+}
@@ -7,9 +7,21 @@ import (
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
 	"github.com/lyft/flinkk8soperator/pkg/controller/config"
 	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
-	"k8s.io/api/extensions/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Newer clusters only serve the Ingress in the networking.k8s.io/v1 API, while older ones only serve it in the
+// extensions/v1beta1 API. As the vendored k8s.io/api does not contain the networking.k8s.io/v1 types yet, the Ingress is
+// built as an unstructured object in either API.
+var IngressV1GroupVersionKind = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: k8.Ingress}
+
+const (
+	IngressPathType = "Prefix"
+	// The extensions/v1beta1 Ingress has no ingressClassName field, so the class is set through the annotation, which
+	// ingress controllers still honor
+	IngressClassAnnotation = "kubernetes.io/ingress.class"
 )
 
 var inputRegex = regexp.MustCompile(`{{[$]jobCluster}}`)
@@ -22,46 +34,124 @@ func GetFlinkUIIngressURL(jobName string) string {
 	return ReplaceJobURL(config.GetConfig().FlinkIngressURLFormat, jobName)
 }
 
-func FetchJobManagerIngressCreateObj(app *v1alpha1.FlinkApplication) *v1beta1.Ingress {
+func isIngressEnabled(app *v1alpha1.FlinkApplication) bool {
+	return app.Spec.Ingress == nil || !app.Spec.Ingress.Disabled
+}
+
+func getIngressConfig(app *v1alpha1.FlinkApplication) v1alpha1.IngressConfig {
+	if app.Spec.Ingress == nil {
+		return v1alpha1.IngressConfig{}
+	}
+	return *app.Spec.Ingress
+}
+
+func getIngressHost(app *v1alpha1.FlinkApplication) string {
+	if host := getIngressConfig(app).Host; host != "" {
+		return host
+	}
+	return GetFlinkUIIngressURL(app.Name)
+}
+
+// Returns the URL of the UI served by the Ingress, or an empty string if there is none or its host is unknown
+func GetFlinkUIURL(app *v1alpha1.FlinkApplication) string {
+	host := getIngressHost(app)
+	if !isIngressEnabled(app) || host == "" {
+		return ""
+	}
+
+	ingressConfig := getIngressConfig(app)
+	scheme := "http"
+	if ingressConfig.TLSSecretName != "" {
+		scheme = "https"
+	}
+	path := ingressConfig.Path
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+// Returns whether the Ingress is built in the networking.k8s.io/v1 API, rather than in the extensions/v1beta1 API
+func isIngressV1(apiVersion string) bool {
+	return apiVersion == IngressV1GroupVersionKind.GroupVersion().String()
+}
+
+func FetchJobManagerIngressCreateObj(app *v1alpha1.FlinkApplication, apiVersion string) *unstructured.Unstructured {
 	podLabels := common.DuplicateMap(app.Labels)
 	podLabels = common.CopyMap(podLabels, k8.GetAppLabel(app.Name))
 
-	ingressMeta := v1.ObjectMeta{
-		Name:      app.Name,
-		Labels:    podLabels,
-		Namespace: app.Namespace,
-		OwnerReferences: []v1.OwnerReference{
-			*v1.NewControllerRef(app, app.GroupVersionKind()),
-		},
+	ingressConfig := getIngressConfig(app)
+	annotations := common.DuplicateMap(ingressConfig.Annotations)
+	path := map[string]interface{}{}
+	if isIngressV1(apiVersion) {
+		path["path"] = ingressConfig.Path
+		if ingressConfig.Path == "" {
+			path["path"] = "/"
+		}
+		path["pathType"] = IngressPathType
+		path["backend"] = map[string]interface{}{
+			"service": map[string]interface{}{
+				"name": app.Name,
+				"port": map[string]interface{}{
+					"number": int64(getUIPort(app)),
+				},
+			},
+		}
+	} else {
+		if ingressConfig.Path != "" {
+			path["path"] = ingressConfig.Path
+		}
+		path["backend"] = map[string]interface{}{
+			"serviceName": app.Name,
+			"servicePort": int64(getUIPort(app)),
+		}
+		if ingressConfig.IngressClassName != "" {
+			annotations[IngressClassAnnotation] = ingressConfig.IngressClassName
+		}
 	}
 
-	backend := v1beta1.IngressBackend{
-		ServiceName: app.Name,
-		ServicePort: intstr.IntOrString{
-			Type:   intstr.Int,
-			IntVal: getUIPort(app),
+	host := getIngressHost(app)
+	rule := map[string]interface{}{
+		"http": map[string]interface{}{
+			"paths": []interface{}{path},
 		},
 	}
+	if host != "" {
+		rule["host"] = host
+	}
 
-	ingressSpec := v1beta1.IngressSpec{
-		Rules: []v1beta1.IngressRule{{
-			Host: GetFlinkUIIngressURL(app.Name),
-			IngressRuleValue: v1beta1.IngressRuleValue{
-				HTTP: &v1beta1.HTTPIngressRuleValue{
-					Paths: []v1beta1.HTTPIngressPath{{
-						Backend: backend,
-					}},
-				},
+	spec := map[string]interface{}{
+		"rules": []interface{}{rule},
+	}
+	if ingressConfig.IngressClassName != "" && isIngressV1(apiVersion) {
+		spec["ingressClassName"] = ingressConfig.IngressClassName
+	}
+	if ingressConfig.TLSSecretName != "" {
+		spec["tls"] = []interface{}{
+			map[string]interface{}{
+				"hosts":      []interface{}{host},
+				"secretName": ingressConfig.TLSSecretName,
 			},
-		}},
+		}
 	}
-	return &v1beta1.Ingress{
-		ObjectMeta: ingressMeta,
-		TypeMeta: v1.TypeMeta{
-			APIVersion: v1beta1.SchemeGroupVersion.String(),
-			Kind:       k8.Ingress,
-		},
-		Spec: ingressSpec,
+
+	ingress := FetchJobManagerIngressDeleteObj(app, apiVersion)
+	ingress.SetLabels(podLabels)
+	if len(annotations) > 0 {
+		ingress.SetAnnotations(annotations)
 	}
+	ingress.SetOwnerReferences([]v1.OwnerReference{
+		*v1.NewControllerRef(app, app.GroupVersionKind()),
+	})
+	ingress.Object["spec"] = spec
+	return ingress
+}
 
+func FetchJobManagerIngressDeleteObj(app *v1alpha1.FlinkApplication, apiVersion string) *unstructured.Unstructured {
+	ingress := &unstructured.Unstructured{}
+	ingress.SetAPIVersion(apiVersion)
+	ingress.SetKind(k8.Ingress)
+	ingress.SetName(app.Name)
+	ingress.SetNamespace(app.Namespace)
+	return ingress
 }
@@ -3,8 +3,10 @@ package flink
 import (
 	"testing"
 
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
 	config2 "github.com/lyft/flinkk8soperator/pkg/controller/config"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestReplaceJobUrl(t *testing.T) {
@@ -25,3 +27,121 @@ func TestGetFlinkUIIngressURL(t *testing.T) {
 		"ABC.lyft.xyz",
 		GetFlinkUIIngressURL("ABC"))
 }
+
+func TestFetchJobManagerIngressCreateObj(t *testing.T) {
+	err := initTestConfig()
+	assert.Nil(t, err)
+	app := getFlinkTestApp()
+
+	ingress := FetchJobManagerIngressCreateObj(&app, "networking.k8s.io/v1")
+	assert.Equal(t, "networking.k8s.io/v1", ingress.GetAPIVersion())
+	assert.Equal(t, "Ingress", ingress.GetKind())
+	assert.Equal(t, app.Name, ingress.GetName())
+	assert.Equal(t, map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": "app-name.lyft.xyz",
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{
+								"service": map[string]interface{}{
+									"name": app.Name,
+									"port": map[string]interface{}{"number": int64(8081)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, ingress.Object["spec"])
+	assert.Nil(t, ingress.GetAnnotations())
+	assert.Equal(t, "http://app-name.lyft.xyz/", GetFlinkUIURL(&app))
+
+	app.Spec.Ingress = &v1alpha1.IngressConfig{
+		Host:             "flink.example.com",
+		Path:             "/app-name",
+		TLSSecretName:    "flink-tls",
+		IngressClassName: "nginx",
+		Annotations: map[string]string{
+			"nginx.ingress.kubernetes.io/rewrite-target": "/",
+		},
+	}
+	ingress = FetchJobManagerIngressCreateObj(&app, "networking.k8s.io/v1")
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	host, _, _ := unstructured.NestedString(rules[0].(map[string]interface{}), "host")
+	assert.Equal(t, "flink.example.com", host)
+	paths, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "http", "paths")
+	assert.Equal(t, "/app-name", paths[0].(map[string]interface{})["path"])
+	className, _, _ := unstructured.NestedString(ingress.Object, "spec", "ingressClassName")
+	assert.Equal(t, "nginx", className)
+	tls, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "tls")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"hosts":      []interface{}{"flink.example.com"},
+			"secretName": "flink-tls",
+		},
+	}, tls)
+	assert.Equal(t, map[string]string{
+		"nginx.ingress.kubernetes.io/rewrite-target": "/",
+	}, ingress.GetAnnotations())
+	assert.Equal(t, "https://flink.example.com/app-name", GetFlinkUIURL(&app))
+
+	// the object can be copied, which requires all of its values to be JSON compatible
+	assert.Equal(t, ingress, ingress.DeepCopy())
+
+	app.Spec.Ingress.Disabled = true
+	assert.Empty(t, GetFlinkUIURL(&app))
+}
+
+func TestFetchJobManagerIngressCreateObjV1Beta1(t *testing.T) {
+	err := initTestConfig()
+	assert.Nil(t, err)
+	app := getFlinkTestApp()
+
+	ingress := FetchJobManagerIngressCreateObj(&app, "extensions/v1beta1")
+	assert.Equal(t, "extensions/v1beta1", ingress.GetAPIVersion())
+	assert.Equal(t, "Ingress", ingress.GetKind())
+	assert.Equal(t, map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": "app-name.lyft.xyz",
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"backend": map[string]interface{}{
+								"serviceName": app.Name,
+								"servicePort": int64(8081),
+							},
+						},
+					},
+				},
+			},
+		},
+	}, ingress.Object["spec"])
+	assert.Nil(t, ingress.GetAnnotations())
+
+	// the class is set through the annotation, as the API has no ingressClassName field
+	app.Spec.Ingress = &v1alpha1.IngressConfig{
+		Path:             "/app-name",
+		IngressClassName: "nginx",
+		Annotations: map[string]string{
+			"nginx.ingress.kubernetes.io/rewrite-target": "/",
+		},
+	}
+	ingress = FetchJobManagerIngressCreateObj(&app, "extensions/v1beta1")
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	paths, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "http", "paths")
+	assert.Equal(t, "/app-name", paths[0].(map[string]interface{})["path"])
+	_, found, _ := unstructured.NestedString(ingress.Object, "spec", "ingressClassName")
+	assert.False(t, found)
+	assert.Equal(t, map[string]string{
+		"nginx.ingress.kubernetes.io/rewrite-target": "/",
+		IngressClassAnnotation:                       "nginx",
+	}, ingress.GetAnnotations())
+	assert.Equal(t, 1, len(app.Spec.Ingress.Annotations))
+	assert.Equal(t, ingress, ingress.DeepCopy())
+}
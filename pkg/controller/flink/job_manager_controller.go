@@ -13,6 +13,7 @@ import (
 	"github.com/lyft/flytestdlib/promutils/labeled"
 	v1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
 	k8_err "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,6 +31,7 @@ const (
 	JobManagerReadinessSuccessThreshold = 1
 	JobManagerReadinessFailureThreshold = 2
 	JobManagerReadinessPeriodSec        = 5
+	JobManagerHostnameEnvVar            = "JOBMANAGER_HOSTNAME"
 )
 
 const (
@@ -71,6 +73,8 @@ func newJobManagerMetrics(scope promutils.Scope) *jobManagerMetrics {
 		serviceCreationFailure:    labeled.NewCounter("service_create_failure", "Job manager service creation failed", jobManagerControllerScope),
 		ingressCreationSuccess:    labeled.NewCounter("ingress_create_success", "Job manager ingress created successfully", jobManagerControllerScope),
 		ingressCreationFailure:    labeled.NewCounter("ingress_create_failure", "Job manager ingress creation failed", jobManagerControllerScope),
+		pdbCreationSuccess:        labeled.NewCounter("pdb_create_success", "Job manager pod disruption budget created successfully", jobManagerControllerScope),
+		pdbCreationFailure:        labeled.NewCounter("pdb_create_failure", "Job manager pod disruption budget creation failed", jobManagerControllerScope),
 	}
 }
 
@@ -82,6 +86,8 @@ type jobManagerMetrics struct {
 	serviceCreationFailure    labeled.Counter
 	ingressCreationSuccess    labeled.Counter
 	ingressCreationFailure    labeled.Counter
+	pdbCreationSuccess        labeled.Counter
+	pdbCreationFailure        labeled.Counter
 }
 
 func (j *JobManagerController) CreateIfNotExist(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
@@ -104,7 +110,7 @@ func (j *JobManagerController) CreateIfNotExist(ctx context.Context, application
 
 	// create the generic job manager service, used by the ingress to provide UI access
 	// there will only be one of these across the lifetime of the application
-	genericService := FetchJobManagerServiceCreateObj(application, hash)
+	genericService := FetchJobManagerGenericServiceCreateObj(application, hash)
 	err = j.k8Cluster.CreateK8Object(ctx, genericService)
 	if err != nil {
 		if !k8_err.IsAlreadyExists(err) {
@@ -120,8 +126,7 @@ func (j *JobManagerController) CreateIfNotExist(ctx context.Context, application
 
 	// create the service for _this_ version of the flink application
 	// this gives us a stable and reliable way to target a particular cluster during upgrades
-	versionedJobManagerService := FetchJobManagerServiceCreateObj(application, hash)
-	versionedJobManagerService.Name = VersionedJobManagerService(application, hash)
+	versionedJobManagerService := FetchVersionedJobManagerServiceCreateObj(application, hash)
 
 	err = j.k8Cluster.CreateK8Object(ctx, versionedJobManagerService)
 	if err != nil {
@@ -136,18 +141,26 @@ func (j *JobManagerController) CreateIfNotExist(ctx context.Context, application
 		j.metrics.serviceCreationSuccess.Inc(ctx)
 	}
 
-	jobManagerIngress := FetchJobManagerIngressCreateObj(application)
-	err = j.k8Cluster.CreateK8Object(ctx, jobManagerIngress)
+	ingressCreated, err := j.reconcileIngress(ctx, application)
 	if err != nil {
-		if !k8_err.IsAlreadyExists(err) {
-			j.metrics.ingressCreationFailure.Inc(ctx)
-			logger.Errorf(ctx, "Jobmanager ingress creation failed %v", err)
-			return false, err
+		return false, err
+	}
+	newlyCreated = newlyCreated || ingressCreated
+
+	if isPodDisruptionBudgetEnabled(application.Spec.JobManagerConfig.PodDisruptionBudget) {
+		pdb := FetchJobManagerPodDisruptionBudgetCreateObj(application, hash)
+		err = j.k8Cluster.CreateK8Object(ctx, pdb)
+		if err != nil {
+			if !k8_err.IsAlreadyExists(err) {
+				j.metrics.pdbCreationFailure.Inc(ctx)
+				logger.Errorf(ctx, "Jobmanager pod disruption budget creation failed %v", err)
+				return false, err
+			}
+			logger.Infof(ctx, "Jobmanager pod disruption budget already exists")
+		} else {
+			newlyCreated = true
+			j.metrics.pdbCreationSuccess.Inc(ctx)
 		}
-		logger.Infof(ctx, "Jobmanager ingress already exists")
-	} else {
-		newlyCreated = true
-		j.metrics.ingressCreationSuccess.Inc(ctx)
 	}
 
 	return newlyCreated, nil
@@ -164,6 +177,67 @@ var JobManagerDefaultResources = coreV1.ResourceRequirements{
 	},
 }
 
+// Like the generic service, the ingress is shared by all versions of the application. It is updated when it already
+// exists, so that changes to the ingress config take effect with the next deploy, and deleted if it has been disabled.
+func (j *JobManagerController) reconcileIngress(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+	apiVersion, err := j.getIngressAPIVersion()
+	if err != nil {
+		logger.Errorf(ctx, "Failed to discover the ingress API %v", err)
+		return false, err
+	}
+
+	if !isIngressEnabled(application) {
+		err = j.k8Cluster.DeleteK8Object(ctx, FetchJobManagerIngressDeleteObj(application, apiVersion))
+		if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+			logger.Errorf(ctx, "Jobmanager ingress deletion failed %v", err)
+			return false, err
+		}
+		return false, nil
+	}
+
+	jobManagerIngress := FetchJobManagerIngressCreateObj(application, apiVersion)
+	err = j.k8Cluster.CreateK8Object(ctx, jobManagerIngress)
+	if err != nil {
+		if !k8_err.IsAlreadyExists(err) {
+			j.metrics.ingressCreationFailure.Inc(ctx)
+			logger.Errorf(ctx, "Jobmanager ingress creation failed %v", err)
+			return false, err
+		}
+		logger.Infof(ctx, "Jobmanager ingress already exists, updating it")
+		existing := FetchJobManagerIngressDeleteObj(application, apiVersion)
+		err = j.k8Cluster.GetK8Object(ctx, existing)
+		if err != nil {
+			logger.Errorf(ctx, "Jobmanager ingress read failed %v", err)
+			return false, err
+		}
+		desired := existing.DeepCopy()
+		desired.SetLabels(jobManagerIngress.GetLabels())
+		desired.SetAnnotations(jobManagerIngress.GetAnnotations())
+		desired.Object["spec"] = jobManagerIngress.Object["spec"]
+		err = j.k8Cluster.PatchK8Object(ctx, existing, desired)
+		if err != nil {
+			logger.Errorf(ctx, "Jobmanager ingress update failed %v", err)
+			return false, err
+		}
+		return false, nil
+	}
+	j.metrics.ingressCreationSuccess.Inc(ctx)
+	return true, nil
+}
+
+// Returns the API version in which the ingress is created, which is networking.k8s.io/v1 if the cluster serves it and
+// extensions/v1beta1 otherwise
+func (j *JobManagerController) getIngressAPIVersion() (string, error) {
+	servesV1, err := j.k8Cluster.IsKindServed(IngressV1GroupVersionKind)
+	if err != nil {
+		return "", err
+	}
+	if servesV1 {
+		return IngressV1GroupVersionKind.GroupVersion().String(), nil
+	}
+	return v1beta1.SchemeGroupVersion.String(), nil
+}
+
 func getJobManagerPodName(application *v1alpha1.FlinkApplication, hash string) string {
 	applicationName := application.Name
 	return fmt.Sprintf(JobManagerPodNameFormat, applicationName, hash)
@@ -174,6 +248,17 @@ func getJobManagerName(application *v1alpha1.FlinkApplication, hash string) stri
 	return fmt.Sprintf(JobManagerNameFormat, applicationName, hash)
 }
 
+// Unlike the generic service, the versioned service is labelled with the hash, so that it can be found along with the
+// other objects of the cluster
+func FetchVersionedJobManagerServiceCreateObj(app *v1alpha1.FlinkApplication, hash string) *coreV1.Service {
+	service := FetchJobManagerServiceCreateObj(app, hash)
+	service.Name = VersionedJobManagerService(app, hash)
+	service.Labels = common.CopyMap(getCommonAppLabels(app), map[string]string{
+		FlinkAppHash: hash,
+	})
+	return service
+}
+
 func FetchVersionedJobManagerServiceDeleteObj(app *v1alpha1.FlinkApplication, hash string) *coreV1.Service {
 	return &coreV1.Service{
 		TypeMeta: metaV1.TypeMeta{
@@ -258,6 +343,21 @@ func FetchJobManagerContainerObj(application *v1alpha1.FlinkApplication) *coreV1
 
 	ports := getJobManagerPorts(application)
 	operatorEnv := GetFlinkContainerEnv(application)
+
+	podConfig := getRestSecurityPodConfig(application)
+	// with HA, each JobManager has to advertise an address of its own to be reachable as the leader
+	if isHighAvailabilityEnabled(application) {
+		podConfig["jobmanager.rpc.address"] = JobManagerHostnameEnvVar
+		operatorEnv = append(operatorEnv, coreV1.EnvVar{
+			Name: JobManagerHostnameEnvVar,
+			ValueFrom: &coreV1.EnvVarSource{
+				FieldRef: &coreV1.ObjectFieldSelector{
+					FieldPath: "status.podIP",
+				},
+			},
+		})
+	}
+
 	operatorEnv = append(operatorEnv, jmConfig.Environment.Env...)
 
 	return &coreV1.Container{
@@ -265,18 +365,13 @@ func FetchJobManagerContainerObj(application *v1alpha1.FlinkApplication) *coreV1
 		Image:           application.Spec.Image,
 		ImagePullPolicy: ImagePullPolicy(application),
 		Resources:       *resources,
-		Args:            []string{JobManagerArg},
+		Args:            getContainerArgs(application, JobManagerArg, podConfig),
 		Ports:           ports,
 		Env:             operatorEnv,
 		EnvFrom:         jmConfig.Environment.EnvFrom,
-		VolumeMounts:    application.Spec.VolumeMounts,
+		VolumeMounts:    getVolumeMounts(application),
 		ReadinessProbe: &coreV1.Probe{
-			Handler: coreV1.Handler{
-				HTTPGet: &coreV1.HTTPGetAction{
-					Path: JobManagerReadinessPath,
-					Port: intstr.FromInt(int(getUIPort(application))),
-				},
-			},
+			Handler:             getJobManagerReadinessHandler(application),
 			InitialDelaySeconds: JobManagerReadinessInitialDelaySec,
 			TimeoutSeconds:      JobManagerReadinessTimeoutSec,
 			SuccessThreshold:    JobManagerReadinessSuccessThreshold,
@@ -286,6 +381,31 @@ func FetchJobManagerContainerObj(application *v1alpha1.FlinkApplication) *coreV1
 	}
 }
 
+// The kubelet cannot present a client certificate, so with mutual TLS we fall back to checking that the REST port is
+// accepting connections
+func getJobManagerReadinessHandler(app *v1alpha1.FlinkApplication) coreV1.Handler {
+	uiPort := intstr.FromInt(int(getUIPort(app)))
+	if isRestMutualTLSEnabled(app) {
+		return coreV1.Handler{
+			TCPSocket: &coreV1.TCPSocketAction{
+				Port: uiPort,
+			},
+		}
+	}
+
+	handler := coreV1.Handler{
+		HTTPGet: &coreV1.HTTPGetAction{
+			Path: JobManagerReadinessPath,
+			Port: uiPort,
+		},
+	}
+	// the scheme is left unset for plain HTTP so that existing deployments hash identically
+	if isRestTLSEnabled(app) {
+		handler.HTTPGet.Scheme = coreV1.URISchemeHTTPS
+	}
+	return handler
+}
+
 func DeploymentIsJobmanager(deployment *v1.Deployment) bool {
 	return deployment.Labels[FlinkDeploymentType] == FlinkDeploymentTypeJobmanager
 }
@@ -334,28 +454,47 @@ func jobmanagerTemplate(app *v1alpha1.FlinkApplication) *v1.Deployment {
 		},
 		Spec: v1.DeploymentSpec{
 			Selector: podSelector,
-			Strategy: v1.DeploymentStrategy{
-				Type: v1.RecreateDeploymentStrategyType,
-			},
+			Strategy: getJobManagerDeploymentStrategy(app),
 			Replicas: &replicas,
 			Template: coreV1.PodTemplateSpec{
 				ObjectMeta: metaV1.ObjectMeta{
 					Namespace:   app.Namespace,
 					Labels:      labels,
-					Annotations: app.Annotations,
+					Annotations: getPodAnnotations(app),
 				},
 				Spec: coreV1.PodSpec{
 					Containers: []coreV1.Container{
 						*jobManagerContainer,
 					},
-					Volumes:          app.Spec.Volumes,
-					ImagePullSecrets: app.Spec.ImagePullSecrets,
+					Volumes:            getVolumes(app),
+					ImagePullSecrets:   app.Spec.ImagePullSecrets,
+					ServiceAccountName: getServiceAccountName(app),
 				},
 			},
 		},
 	}
 }
 
+// Without HA a second JobManager would run a separate cluster, so the old pod must be gone before a new one starts.
+// With HA the standby JobManagers take over while pods are replaced one at a time.
+func getJobManagerDeploymentStrategy(app *v1alpha1.FlinkApplication) v1.DeploymentStrategy {
+	if !isHighAvailabilityEnabled(app) {
+		return v1.DeploymentStrategy{
+			Type: v1.RecreateDeploymentStrategyType,
+		}
+	}
+
+	maxUnavailable := intstr.FromInt(1)
+	maxSurge := intstr.FromInt(0)
+	return v1.DeploymentStrategy{
+		Type: v1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &v1.RollingUpdateDeployment{
+			MaxUnavailable: &maxUnavailable,
+			MaxSurge:       &maxSurge,
+		},
+	}
+}
+
 func FetchJobMangerDeploymentCreateObj(app *v1alpha1.FlinkApplication, hash string) *v1.Deployment {
 	template := jobmanagerTemplate(app.DeepCopy())
 
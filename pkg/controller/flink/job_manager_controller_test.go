@@ -8,14 +8,16 @@ import (
 
 	"context"
 
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
 	"github.com/lyft/flytestdlib/promutils/labeled"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
-	"k8s.io/api/extensions/v1beta1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -122,16 +124,24 @@ func TestJobManagerCreateSuccess(t *testing.T) {
 			labels := map[string]string{
 				"flink-app": "app-name",
 			}
-			ingress := object.(*v1beta1.Ingress)
-			assert.Equal(t, app.Name, ingress.Name)
-			assert.Equal(t, app.Namespace, ingress.Namespace)
-			assert.Equal(t, labels, ingress.Labels)
+			ingress := object.(*unstructured.Unstructured)
+			assert.Equal(t, app.Name, ingress.GetName())
+			assert.Equal(t, app.Namespace, ingress.GetNamespace())
+			assert.Equal(t, labels, ingress.GetLabels())
+		case 5:
+			pdb := object.(*policyV1beta1.PodDisruptionBudget)
+			assert.Equal(t, getJobManagerName(&app, hash), pdb.Name)
+			assert.Equal(t, app.Namespace, pdb.Namespace)
+			assert.Equal(t, "app-name", pdb.OwnerReferences[0].Name)
+			assert.Equal(t, expectedLabels, pdb.Spec.Selector.MatchLabels)
+			assert.Equal(t, int32(1), pdb.Spec.MaxUnavailable.IntVal)
 		}
 		return nil
 	}
 	newlyCreated, err := testController.CreateIfNotExist(context.Background(), &app)
 	assert.Nil(t, err)
 	assert.True(t, newlyCreated)
+	assert.Equal(t, 5, ctr)
 }
 
 func TestJobManagerCreateErr(t *testing.T) {
@@ -155,8 +165,85 @@ func TestJobManagerCreateAlreadyExists(t *testing.T) {
 		ctr++
 		return k8sErrors.NewAlreadyExists(schema.GroupResource{}, "")
 	}
+	// the existing ingress is updated to the current config
+	updated := false
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		assert.Equal(t, app.Name, object.(*unstructured.Unstructured).GetName())
+		updated = true
+		return nil
+	}
 	newlyCreated, err := testController.CreateIfNotExist(context.Background(), &app)
-	assert.Equal(t, ctr, 4)
+	assert.Equal(t, ctr, 5)
 	assert.Nil(t, err)
 	assert.False(t, newlyCreated)
+	assert.True(t, updated)
+}
+
+func TestJobManagerIngressDisabled(t *testing.T) {
+	testController := getJMControllerForTest()
+	app := getFlinkTestApp()
+	app.Spec.Ingress = &v1alpha1.IngressConfig{Disabled: true}
+	mockK8Cluster := testController.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.CreateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		_, isIngress := object.(*unstructured.Unstructured)
+		assert.False(t, isIngress)
+		return nil
+	}
+	deleted := false
+	mockK8Cluster.DeleteK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		assert.Equal(t, app.Name, object.(*unstructured.Unstructured).GetName())
+		deleted = true
+		return k8sErrors.NewNotFound(schema.GroupResource{}, "")
+	}
+	newlyCreated, err := testController.CreateIfNotExist(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, newlyCreated)
+	assert.True(t, deleted)
+}
+
+func TestJobManagerIngressV1Beta1(t *testing.T) {
+	testController := getJMControllerForTest()
+	app := getFlinkTestApp()
+	mockK8Cluster := testController.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.IsKindServedFunc = func(gvk schema.GroupVersionKind) (bool, error) {
+		assert.Equal(t, IngressV1GroupVersionKind, gvk)
+		return false, nil
+	}
+	created := false
+	mockK8Cluster.CreateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		if ingress, ok := object.(*unstructured.Unstructured); ok {
+			assert.Equal(t, "extensions/v1beta1", ingress.GetAPIVersion())
+			created = true
+		}
+		return nil
+	}
+	_, err := testController.CreateIfNotExist(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, created)
+}
+
+func TestJobManagerRestSecurity(t *testing.T) {
+	app := getFlinkTestApp()
+	app.Spec.RestSecurity = &v1alpha1.RestSecurityConfig{
+		TLSEnabled:    true,
+		TLSSecretName: "flink-tls",
+	}
+
+	deployment := FetchJobMangerDeploymentCreateObj(&app, testAppHash)
+	podSpec := deployment.Spec.Template.Spec
+	assert.Equal(t, 1, len(podSpec.Volumes))
+	assert.Equal(t, RestTLSVolumeName, podSpec.Volumes[0].Name)
+	assert.Equal(t, "flink-tls", podSpec.Volumes[0].Secret.SecretName)
+
+	container := podSpec.Containers[0]
+	assert.Equal(t, RestTLSMountPath, container.VolumeMounts[0].MountPath)
+	assert.Equal(t, coreV1.URISchemeHTTPS, container.ReadinessProbe.HTTPGet.Scheme)
+	assert.Equal(t, RestKeystorePasswordEnvVar, container.Env[len(container.Env)-3].Name)
+	assert.Equal(t, "flink-tls", container.Env[len(container.Env)-3].ValueFrom.SecretKeyRef.Name)
+	assert.Nil(t, app.Spec.Volumes)
+
+	app.Spec.RestSecurity.MutualTLS = true
+	container = FetchJobMangerDeploymentCreateObj(&app, testAppHash).Spec.Template.Spec.Containers[0]
+	assert.Nil(t, container.ReadinessProbe.HTTPGet)
+	assert.Equal(t, int(UIDefaultPort), container.ReadinessProbe.TCPSocket.Port.IntValue())
 }
@@ -0,0 +1,212 @@
+package flink
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Metrics with the same scope, vertex and aggregation are fetched with a single request
+type metricQueryKey struct {
+	scope       v1alpha1.MetricScope
+	vertex      string
+	aggregation v1alpha1.MetricAggregation
+}
+
+// Refreshes the values of the metrics selected in the application's spec. As metric values change continuously, they
+// are only refreshed once per resync period (or when the selection changes), so that publishing them does not cause
+// a status update on every reconciliation. Returns true if the metrics status was refreshed.
+func (f *Controller) UpdateMetricsStatus(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) (bool, error) {
+	selectors := getMetricSelectors(app)
+	if len(selectors) == 0 {
+		changed := app.Status.Metrics != nil || app.Status.MetricsUpdatedAt != nil
+		app.Status.Metrics = nil
+		app.Status.MetricsUpdatedAt = nil
+		return changed, nil
+	}
+
+	if app.Status.MetricsUpdatedAt != nil && metricSelectionMatches(selectors, app.Status.Metrics) &&
+		time.Since(app.Status.MetricsUpdatedAt.Time) < config.GetConfig().ResyncPeriod.Duration {
+		return false, nil
+	}
+
+	flinkClient, err := f.getFlinkClient(ctx, app, hash)
+	if err != nil {
+		return false, err
+	}
+
+	var keys []metricQueryKey
+	queries := map[metricQueryKey][]string{}
+	for _, selector := range selectors {
+		key := metricQueryKey{
+			scope:       selector.Scope,
+			vertex:      selector.Vertex,
+			aggregation: selector.Aggregation,
+		}
+		if _, ok := queries[key]; !ok {
+			keys = append(keys, key)
+		}
+		queries[key] = append(queries[key], selector.Name)
+	}
+
+	url := f.getJobManagerURL(ctx, app, hash)
+	jobID := app.Status.JobStatus.JobID
+	var vertexIDs map[string]string
+	var fetchErrors []string
+	values := map[metricQueryKey]map[string]string{}
+	for _, key := range keys {
+		if key.scope == v1alpha1.MetricScopeVertex && vertexIDs == nil {
+			vertexIDs, err = getVertexIDs(ctx, flinkClient, url, jobID)
+			if err != nil {
+				fetchErrors = append(fetchErrors, err.Error())
+				vertexIDs = map[string]string{}
+			}
+		}
+
+		metrics, err := fetchMetrics(ctx, flinkClient, url, jobID, vertexIDs, key, queries[key])
+		if err != nil {
+			fetchErrors = append(fetchErrors, err.Error())
+			continue
+		}
+		values[key] = metrics
+	}
+
+	// metrics that could not be fetched keep their previous value
+	previous := map[v1alpha1.FlinkMetricStatus]string{}
+	for _, metric := range app.Status.Metrics {
+		value := metric.Value
+		metric.Value = ""
+		previous[metric] = value
+	}
+
+	statuses := make([]v1alpha1.FlinkMetricStatus, 0, len(selectors))
+	for _, selector := range selectors {
+		status := v1alpha1.FlinkMetricStatus{
+			Name:        selector.Name,
+			Scope:       selector.Scope,
+			Vertex:      selector.Vertex,
+			Aggregation: selector.Aggregation,
+		}
+		key := metricQueryKey{
+			scope:       selector.Scope,
+			vertex:      selector.Vertex,
+			aggregation: selector.Aggregation,
+		}
+		if metrics, ok := values[key]; ok {
+			status.Value = metrics[selector.Name]
+		} else {
+			status.Value = previous[status]
+		}
+		statuses = append(statuses, status)
+	}
+
+	now := metav1.Now()
+	app.Status.Metrics = statuses
+	app.Status.MetricsUpdatedAt = &now
+
+	if len(fetchErrors) > 0 {
+		return true, errors.New(strings.Join(fetchErrors, "; "))
+	}
+	return true, nil
+}
+
+// Returns the metric selectors of the application with defaults applied. The aggregation only applies to the Vertex
+// and TaskManager scopes, where it defaults to sum.
+func getMetricSelectors(app *v1alpha1.FlinkApplication) []v1alpha1.FlinkMetricSelector {
+	selectors := make([]v1alpha1.FlinkMetricSelector, 0, len(app.Spec.Metrics))
+	for _, selector := range app.Spec.Metrics {
+		if selector.Scope == "" {
+			selector.Scope = v1alpha1.MetricScopeJob
+		}
+		if selector.Scope != v1alpha1.MetricScopeVertex {
+			selector.Vertex = ""
+		}
+		switch selector.Scope {
+		case v1alpha1.MetricScopeVertex, v1alpha1.MetricScopeTaskManager:
+			if selector.Aggregation == "" {
+				selector.Aggregation = v1alpha1.MetricAggregationSum
+			}
+		default:
+			selector.Aggregation = ""
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors
+}
+
+func metricSelectionMatches(selectors []v1alpha1.FlinkMetricSelector, metrics []v1alpha1.FlinkMetricStatus) bool {
+	if len(selectors) != len(metrics) {
+		return false
+	}
+	for i, selector := range selectors {
+		metric := metrics[i]
+		if selector.Name != metric.Name || selector.Scope != metric.Scope || selector.Vertex != metric.Vertex ||
+			selector.Aggregation != metric.Aggregation {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the IDs of the job's vertices by name
+func getVertexIDs(ctx context.Context, flinkClient client.FlinkAPIInterface, url string, jobID string) (map[string]string, error) {
+	overview, err := flinkClient.GetJobOverview(ctx, url, jobID)
+	if err != nil {
+		return nil, err
+	}
+	vertexIDs := make(map[string]string, len(overview.Vertices))
+	for _, vertex := range overview.Vertices {
+		vertexIDs[vertex.Name] = vertex.ID
+	}
+	return vertexIDs, nil
+}
+
+// Fetches the named metrics for a scope, returning their values by name
+func fetchMetrics(ctx context.Context, flinkClient client.FlinkAPIInterface, url string, jobID string,
+	vertexIDs map[string]string, key metricQueryKey, names []string) (map[string]string, error) {
+	query := client.MetricsQuery{
+		Metrics: names,
+	}
+	if key.aggregation != "" {
+		query.Aggregations = []client.MetricAggregation{client.MetricAggregation(key.aggregation)}
+	}
+
+	var metrics []client.Metric
+	var err error
+	switch key.scope {
+	case v1alpha1.MetricScopeJob:
+		metrics, err = flinkClient.GetJobMetrics(ctx, url, jobID, query)
+	case v1alpha1.MetricScopeVertex:
+		vertexID, ok := vertexIDs[key.vertex]
+		if !ok {
+			return nil, errors.Errorf("job has no vertex named %s", key.vertex)
+		}
+		metrics, err = flinkClient.GetVertexMetrics(ctx, url, jobID, vertexID, query)
+	case v1alpha1.MetricScopeTaskManager:
+		metrics, err = flinkClient.GetTaskManagerMetrics(ctx, url, "", query)
+	case v1alpha1.MetricScopeJobManager:
+		metrics, err = flinkClient.GetJobManagerMetrics(ctx, url, names)
+	default:
+		return nil, errors.Errorf("unknown metric scope %s", key.scope)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(metrics))
+	for _, metric := range metrics {
+		if query.Aggregations == nil {
+			values[metric.ID] = metric.Value
+		} else if value, ok := metric.GetAggregate(query.Aggregations[0]); ok {
+			values[metric.ID] = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,150 @@
+package flink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	clientMock "github.com/lyft/flinkk8soperator/pkg/controller/flink/client/mock"
+	flyteConfig "github.com/lyft/flytestdlib/config"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getMetricsTestApp() v1alpha1.FlinkApplication {
+	app := getFlinkTestApp()
+	app.Spec.Metrics = []v1alpha1.FlinkMetricSelector{
+		{Name: "uptime"},
+		{Name: "numRestarts"},
+		{Name: "numRecordsIn", Scope: v1alpha1.MetricScopeVertex, Vertex: "Source"},
+		{Name: "Status.JVM.CPU.Load", Scope: v1alpha1.MetricScopeTaskManager, Aggregation: v1alpha1.MetricAggregationMax},
+		{Name: "numRegisteredTaskManagers", Scope: v1alpha1.MetricScopeJobManager},
+	}
+	return app
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestUpdateMetricsStatus(t *testing.T) {
+	err := config.ConfigSection.SetConfig(&config.Config{
+		ResyncPeriod: flyteConfig.Duration{Duration: time.Hour},
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, config.ConfigSection.SetConfig(&config.Config{}))
+	}()
+
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getMetricsTestApp()
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	jobMetricsCalls := 0
+	mockJmClient.GetJobMetricsFunc = func(ctx context.Context, url string, jobID string, query client.MetricsQuery) ([]client.Metric, error) {
+		jobMetricsCalls++
+		assert.Equal(t, testJobID, jobID)
+		assert.Equal(t, []string{"uptime", "numRestarts"}, query.Metrics)
+		assert.Empty(t, query.Aggregations)
+		return []client.Metric{{ID: "uptime", Value: "1000"}, {ID: "numRestarts", Value: "2"}}, nil
+	}
+	mockJmClient.GetJobOverviewFunc = func(ctx context.Context, url string, jobID string) (*client.FlinkJobOverview, error) {
+		return &client.FlinkJobOverview{
+			Vertices: []client.FlinkJobVertex{{ID: "v1", Name: "Source"}},
+		}, nil
+	}
+	mockJmClient.GetVertexMetricsFunc = func(ctx context.Context, url string, jobID string, vertexID string, query client.MetricsQuery) ([]client.Metric, error) {
+		assert.Equal(t, "v1", vertexID)
+		assert.Equal(t, []client.MetricAggregation{client.MetricAggregationSum}, query.Aggregations)
+		return []client.Metric{{ID: "numRecordsIn", Sum: floatPtr(300)}}, nil
+	}
+	mockJmClient.GetTaskManagerMetricsFunc = func(ctx context.Context, url string, taskManagerID string, query client.MetricsQuery) ([]client.Metric, error) {
+		assert.Empty(t, taskManagerID)
+		assert.Equal(t, []client.MetricAggregation{client.MetricAggregationMax}, query.Aggregations)
+		return []client.Metric{{ID: "Status.JVM.CPU.Load", Max: floatPtr(0.75)}}, nil
+	}
+	mockJmClient.GetJobManagerMetricsFunc = func(ctx context.Context, url string, metrics []string) ([]client.Metric, error) {
+		assert.Equal(t, []string{"numRegisteredTaskManagers"}, metrics)
+		return []client.Metric{{ID: "numRegisteredTaskManagers", Value: "2"}}, nil
+	}
+
+	changed, err := flinkControllerForTest.UpdateMetricsStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.NotNil(t, flinkApp.Status.MetricsUpdatedAt)
+	assert.Equal(t, []v1alpha1.FlinkMetricStatus{
+		{Name: "uptime", Scope: v1alpha1.MetricScopeJob, Value: "1000"},
+		{Name: "numRestarts", Scope: v1alpha1.MetricScopeJob, Value: "2"},
+		{Name: "numRecordsIn", Scope: v1alpha1.MetricScopeVertex, Vertex: "Source",
+			Aggregation: v1alpha1.MetricAggregationSum, Value: "300"},
+		{Name: "Status.JVM.CPU.Load", Scope: v1alpha1.MetricScopeTaskManager,
+			Aggregation: v1alpha1.MetricAggregationMax, Value: "0.75"},
+		{Name: "numRegisteredTaskManagers", Scope: v1alpha1.MetricScopeJobManager, Value: "2"},
+	}, flinkApp.Status.Metrics)
+
+	// metrics are not refreshed again within the resync period
+	changed, err = flinkControllerForTest.UpdateMetricsStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 1, jobMetricsCalls)
+
+	// unless the selected metrics change
+	flinkApp.Spec.Metrics = flinkApp.Spec.Metrics[:2]
+	changed, err = flinkControllerForTest.UpdateMetricsStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 2, jobMetricsCalls)
+	assert.Equal(t, 2, len(flinkApp.Status.Metrics))
+}
+
+func TestUpdateMetricsStatusKeepsPreviousValues(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+	flinkApp.Spec.Metrics = []v1alpha1.FlinkMetricSelector{
+		{Name: "uptime"},
+		{Name: "numRecordsIn", Scope: v1alpha1.MetricScopeVertex, Vertex: "Missing"},
+	}
+	updatedAt := metaV1.NewTime(time.Now().Add(-time.Hour))
+	flinkApp.Status.MetricsUpdatedAt = &updatedAt
+	flinkApp.Status.Metrics = []v1alpha1.FlinkMetricStatus{
+		{Name: "uptime", Scope: v1alpha1.MetricScopeJob, Value: "1000"},
+		{Name: "numRecordsIn", Scope: v1alpha1.MetricScopeVertex, Vertex: "Missing",
+			Aggregation: v1alpha1.MetricAggregationSum, Value: "100"},
+	}
+
+	mockJmClient := flinkControllerForTest.flinkClient.(*clientMock.JobManagerClient)
+	mockJmClient.GetJobMetricsFunc = func(ctx context.Context, url string, jobID string, query client.MetricsQuery) ([]client.Metric, error) {
+		return nil, errors.New("jobmanager unavailable")
+	}
+	mockJmClient.GetJobOverviewFunc = func(ctx context.Context, url string, jobID string) (*client.FlinkJobOverview, error) {
+		return &client.FlinkJobOverview{}, nil
+	}
+
+	changed, err := flinkControllerForTest.UpdateMetricsStatus(context.Background(), &flinkApp, "hash")
+	assert.True(t, changed)
+	assert.Equal(t, "jobmanager unavailable; job has no vertex named Missing", err.Error())
+	assert.Equal(t, "1000", flinkApp.Status.Metrics[0].Value)
+	assert.Equal(t, "100", flinkApp.Status.Metrics[1].Value)
+	assert.True(t, flinkApp.Status.MetricsUpdatedAt.After(updatedAt.Time))
+}
+
+func TestUpdateMetricsStatusNoMetrics(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getFlinkTestApp()
+
+	changed, err := flinkControllerForTest.UpdateMetricsStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	now := metaV1.Now()
+	flinkApp.Status.MetricsUpdatedAt = &now
+	flinkApp.Status.Metrics = []v1alpha1.FlinkMetricStatus{{Name: "uptime", Value: "1000"}}
+	changed, err = flinkControllerForTest.UpdateMetricsStatus(context.Background(), &flinkApp, "hash")
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Nil(t, flinkApp.Status.Metrics)
+	assert.Nil(t, flinkApp.Status.MetricsUpdatedAt)
+}
@@ -2,37 +2,53 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
 	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type CreateClusterFunc func(ctx context.Context, application *v1alpha1.FlinkApplication) error
 type DeleteClusterFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) error
+type GetOrphanedClustersFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, createdBefore time.Time) ([]string, error)
 type CancelWithSavepointFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (string, error)
 type ForceCancelFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) error
 type StartFlinkJobFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string,
 	jarName string, parallelism int32, entryClass string, programArgs string) (string, error)
 type GetSavepointStatusFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (*client.SavepointResponse, error)
+type DisposeSavepointFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, savepointPath string) (string, error)
+type GetSavepointDisposalStatusFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error)
+type RescaleJobFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, parallelism int32) (string, error)
+type GetRescaleStatusFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error)
 type IsClusterReadyFunc func(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error)
+type GetPodFailuresFunc func(ctx context.Context, application *v1alpha1.FlinkApplication) ([]common.PodFailure, error)
 type IsServiceReadyFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error)
 type GetJobsForApplicationFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) ([]client.FlinkJob, error)
 type GetCurrentAndOldDeploymentsForAppFunc func(ctx context.Context, application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error)
 type FindExternalizedCheckpointFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (string, error)
 type CompareAndUpdateClusterStatusFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error)
 type CompareAndUpdateJobStatusFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error)
+type RemoveAppFunc func(application types.NamespacedName)
+type UpdateMetricsStatusFunc func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error)
 
 type FlinkController struct {
 	CreateClusterFunc                     CreateClusterFunc
 	DeleteClusterFunc                     DeleteClusterFunc
+	GetOrphanedClustersFunc               GetOrphanedClustersFunc
 	CancelWithSavepointFunc               CancelWithSavepointFunc
 	ForceCancelFunc                       ForceCancelFunc
 	StartFlinkJobFunc                     StartFlinkJobFunc
 	GetSavepointStatusFunc                GetSavepointStatusFunc
+	DisposeSavepointFunc                  DisposeSavepointFunc
+	GetSavepointDisposalStatusFunc        GetSavepointDisposalStatusFunc
+	RescaleJobFunc                        RescaleJobFunc
+	GetRescaleStatusFunc                  GetRescaleStatusFunc
 	IsClusterReadyFunc                    IsClusterReadyFunc
+	GetPodFailuresFunc                    GetPodFailuresFunc
 	IsServiceReadyFunc                    IsServiceReadyFunc
 	GetJobsForApplicationFunc             GetJobsForApplicationFunc
 	GetCurrentAndOldDeploymentsForAppFunc GetCurrentAndOldDeploymentsForAppFunc
@@ -40,6 +56,8 @@ type FlinkController struct {
 	Events                                []corev1.Event
 	CompareAndUpdateClusterStatusFunc     CompareAndUpdateClusterStatusFunc
 	CompareAndUpdateJobStatusFunc         CompareAndUpdateJobStatusFunc
+	RemoveAppFunc                         RemoveAppFunc
+	UpdateMetricsStatusFunc               UpdateMetricsStatusFunc
 }
 
 func (m *FlinkController) GetCurrentAndOldDeploymentsForApp(ctx context.Context, application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error) {
@@ -56,6 +74,13 @@ func (m *FlinkController) DeleteCluster(ctx context.Context, application *v1alph
 	return nil
 }
 
+func (m *FlinkController) GetOrphanedClusters(ctx context.Context, application *v1alpha1.FlinkApplication, createdBefore time.Time) ([]string, error) {
+	if m.GetOrphanedClustersFunc != nil {
+		return m.GetOrphanedClustersFunc(ctx, application, createdBefore)
+	}
+	return nil, nil
+}
+
 func (m *FlinkController) CreateCluster(ctx context.Context, application *v1alpha1.FlinkApplication) error {
 	if m.CreateClusterFunc != nil {
 		return m.CreateClusterFunc(ctx, application)
@@ -70,6 +95,34 @@ func (m *FlinkController) CancelWithSavepoint(ctx context.Context, application *
 	return "", nil
 }
 
+func (m *FlinkController) DisposeSavepoint(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, savepointPath string) (string, error) {
+	if m.DisposeSavepointFunc != nil {
+		return m.DisposeSavepointFunc(ctx, application, hash, savepointPath)
+	}
+	return "", nil
+}
+
+func (m *FlinkController) GetSavepointDisposalStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+	if m.GetSavepointDisposalStatusFunc != nil {
+		return m.GetSavepointDisposalStatusFunc(ctx, application, hash, triggerID)
+	}
+	return nil, nil
+}
+
+func (m *FlinkController) RescaleJob(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, parallelism int32) (string, error) {
+	if m.RescaleJobFunc != nil {
+		return m.RescaleJobFunc(ctx, application, hash, parallelism)
+	}
+	return "", nil
+}
+
+func (m *FlinkController) GetRescaleStatus(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+	if m.GetRescaleStatusFunc != nil {
+		return m.GetRescaleStatusFunc(ctx, application, hash, triggerID)
+	}
+	return nil, nil
+}
+
 func (m *FlinkController) ForceCancel(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) error {
 	if m.ForceCancelFunc != nil {
 		return m.ForceCancelFunc(ctx, application, hash)
@@ -99,6 +152,13 @@ func (m *FlinkController) IsClusterReady(ctx context.Context, application *v1alp
 	return false, nil
 }
 
+func (m *FlinkController) GetPodFailures(ctx context.Context, application *v1alpha1.FlinkApplication) ([]common.PodFailure, error) {
+	if m.GetPodFailuresFunc != nil {
+		return m.GetPodFailuresFunc(ctx, application)
+	}
+	return nil, nil
+}
+
 func (m *FlinkController) IsServiceReady(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
 	if m.IsServiceReadyFunc != nil {
 		return m.IsServiceReadyFunc(ctx, application, hash)
@@ -139,3 +199,17 @@ func (m *FlinkController) CompareAndUpdateJobStatus(ctx context.Context, app *v1
 
 	return false, nil
 }
+
+func (m *FlinkController) RemoveApp(application types.NamespacedName) {
+	if m.RemoveAppFunc != nil {
+		m.RemoveAppFunc(application)
+	}
+}
+
+func (m *FlinkController) UpdateMetricsStatus(ctx context.Context, app *v1alpha1.FlinkApplication, hash string) (bool, error) {
+	if m.UpdateMetricsStatusFunc != nil {
+		return m.UpdateMetricsStatusFunc(ctx, app, hash)
+	}
+
+	return false, nil
+}
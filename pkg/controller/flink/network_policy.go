@@ -0,0 +1,132 @@
+package flink
+
+import (
+	"fmt"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	networkingV1 "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const NetworkPolicyNameFormat = "%s-%s"
+
+func isNetworkPolicyEnabled(app *v1alpha1.FlinkApplication) bool {
+	return app.Spec.NetworkPolicy != nil && app.Spec.NetworkPolicy.Enabled
+}
+
+func getNetworkPolicyName(app *v1alpha1.FlinkApplication, hash string) string {
+	return fmt.Sprintf(NetworkPolicyNameFormat, app.Name, hash)
+}
+
+// Returns a peer selecting the pods matching the given label selectors, or nil if neither is set. An unset namespace
+// selector matches pods in all namespaces.
+func getNetworkPolicyPeer(namespaceSelector string, podSelector string) (*networkingV1.NetworkPolicyPeer, error) {
+	if namespaceSelector == "" && podSelector == "" {
+		return nil, nil
+	}
+
+	namespaces, err := metaV1.ParseToLabelSelector(namespaceSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid namespace selector %q", namespaceSelector)
+	}
+	pods, err := metaV1.ParseToLabelSelector(podSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pod selector %q", podSelector)
+	}
+	return &networkingV1.NetworkPolicyPeer{
+		NamespaceSelector: namespaces,
+		PodSelector:       pods,
+	}, nil
+}
+
+// The UI port also serves the REST API, so it has to be reachable by both the operator and the ingress controller.
+// If either of them is not configured, we cannot tell its traffic apart and leave the port open to all sources.
+func getUIIngressRule(app *v1alpha1.FlinkApplication) (networkingV1.NetworkPolicyIngressRule, error) {
+	cfg := config.GetConfig().NetworkPolicy
+	tcp := coreV1.ProtocolTCP
+	uiPort := intstr.FromInt(int(getUIPort(app)))
+	rule := networkingV1.NetworkPolicyIngressRule{
+		Ports: []networkingV1.NetworkPolicyPort{{
+			Protocol: &tcp,
+			Port:     &uiPort,
+		}},
+	}
+
+	operator, err := getNetworkPolicyPeer(cfg.OperatorNamespaceSelector, cfg.OperatorPodSelector)
+	if err != nil {
+		return rule, errors.Wrap(err, "invalid operator network policy config")
+	}
+	ingressController, err := getNetworkPolicyPeer(cfg.IngressNamespaceSelector, cfg.IngressPodSelector)
+	if err != nil {
+		return rule, errors.Wrap(err, "invalid ingress controller network policy config")
+	}
+	if operator != nil && ingressController != nil {
+		rule.From = []networkingV1.NetworkPolicyPeer{*operator, *ingressController}
+	}
+	return rule, nil
+}
+
+// Creates the NetworkPolicy isolating the cluster for a particular version of the application. Like the versioned
+// JobManager service, it only selects the pods of that version and is deleted along with them.
+func FetchNetworkPolicyCreateObj(app *v1alpha1.FlinkApplication, hash string) (*networkingV1.NetworkPolicy, error) {
+	labels := getCommonAppLabels(app)
+	labels[FlinkAppHash] = hash
+
+	uiRule, err := getUIIngressRule(app)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []networkingV1.NetworkPolicyIngressRule{
+		{
+			// JobManagers and TaskManagers of the cluster communicate over all of their ports
+			From: []networkingV1.NetworkPolicyPeer{{
+				PodSelector: &metaV1.LabelSelector{
+					MatchLabels: labels,
+				},
+			}},
+		},
+		uiRule,
+	}
+	rules = append(rules, app.Spec.NetworkPolicy.Ingress...)
+
+	return &networkingV1.NetworkPolicy{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: networkingV1.SchemeGroupVersion.String(),
+			Kind:       k8.NetworkPolicy,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      getNetworkPolicyName(app, hash),
+			Namespace: app.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metaV1.OwnerReference{
+				*metaV1.NewControllerRef(app, app.GroupVersionKind()),
+			},
+		},
+		Spec: networkingV1.NetworkPolicySpec{
+			PodSelector: metaV1.LabelSelector{
+				MatchLabels: labels,
+			},
+			PolicyTypes: []networkingV1.PolicyType{networkingV1.PolicyTypeIngress},
+			Ingress:     rules,
+		},
+	}, nil
+}
+
+func FetchNetworkPolicyDeleteObj(app *v1alpha1.FlinkApplication, hash string) *networkingV1.NetworkPolicy {
+	return &networkingV1.NetworkPolicy{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: networkingV1.SchemeGroupVersion.String(),
+			Kind:       k8.NetworkPolicy,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      getNetworkPolicyName(app, hash),
+			Namespace: app.Namespace,
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package flink
+
+import (
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/stretchr/testify/assert"
+	networkingV1 "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFetchNetworkPolicyCreateObj(t *testing.T) {
+	err := config.ConfigSection.SetConfig(&config.Config{
+		NetworkPolicy: config.NetworkPolicyConfig{
+			OperatorNamespaceSelector: "name=flink-operator",
+			OperatorPodSelector:       "app=flinkoperator",
+			IngressPodSelector:        "app=nginx-ingress",
+		},
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, config.ConfigSection.SetConfig(&config.Config{}))
+	}()
+
+	app := getFlinkTestApp()
+	prometheus := networkingV1.NetworkPolicyPeer{
+		PodSelector: &metaV1.LabelSelector{
+			MatchLabels: map[string]string{"app": "prometheus"},
+		},
+	}
+	app.Spec.NetworkPolicy = &v1alpha1.NetworkPolicyConfig{
+		Enabled: true,
+		Ingress: []networkingV1.NetworkPolicyIngressRule{{
+			From: []networkingV1.NetworkPolicyPeer{prometheus},
+		}},
+	}
+	hash := HashForApplication(&app)
+
+	networkPolicy, err := FetchNetworkPolicyCreateObj(&app, hash)
+	assert.Nil(t, err)
+	assert.Equal(t, app.Name+"-"+hash, networkPolicy.Name)
+	assert.Equal(t, app.Namespace, networkPolicy.Namespace)
+	assert.Equal(t, app.Name, networkPolicy.OwnerReferences[0].Name)
+
+	labels := map[string]string{
+		"flink-app":      app.Name,
+		"flink-app-hash": hash,
+	}
+	assert.Equal(t, labels, networkPolicy.Spec.PodSelector.MatchLabels)
+	assert.Equal(t, []networkingV1.PolicyType{networkingV1.PolicyTypeIngress}, networkPolicy.Spec.PolicyTypes)
+
+	rules := networkPolicy.Spec.Ingress
+	assert.Equal(t, 3, len(rules))
+	assert.Empty(t, rules[0].Ports)
+	assert.Equal(t, labels, rules[0].From[0].PodSelector.MatchLabels)
+
+	assert.Equal(t, getUIPort(&app), rules[1].Ports[0].Port.IntVal)
+	assert.Equal(t, 2, len(rules[1].From))
+	assert.Equal(t, map[string]string{"name": "flink-operator"}, rules[1].From[0].NamespaceSelector.MatchLabels)
+	assert.Equal(t, map[string]string{"app": "flinkoperator"}, rules[1].From[0].PodSelector.MatchLabels)
+	// an unset namespace selector matches all namespaces
+	assert.Empty(t, rules[1].From[1].NamespaceSelector.MatchLabels)
+	assert.Equal(t, map[string]string{"app": "nginx-ingress"}, rules[1].From[1].PodSelector.MatchLabels)
+
+	assert.Equal(t, prometheus, rules[2].From[0])
+}
+
+func TestFetchNetworkPolicyCreateObjWithoutSelectors(t *testing.T) {
+	app := getFlinkTestApp()
+	app.Spec.NetworkPolicy = &v1alpha1.NetworkPolicyConfig{Enabled: true}
+
+	networkPolicy, err := FetchNetworkPolicyCreateObj(&app, "hash")
+	assert.Nil(t, err)
+	// without selectors for the operator and ingress controller the UI port is open to all sources
+	assert.Equal(t, 2, len(networkPolicy.Spec.Ingress))
+	assert.Empty(t, networkPolicy.Spec.Ingress[1].From)
+}
+
+func TestFetchNetworkPolicyCreateObjInvalidSelector(t *testing.T) {
+	err := config.ConfigSection.SetConfig(&config.Config{
+		NetworkPolicy: config.NetworkPolicyConfig{
+			OperatorPodSelector: "app in (",
+			IngressPodSelector:  "app=nginx-ingress",
+		},
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, config.ConfigSection.SetConfig(&config.Config{}))
+	}()
+
+	app := getFlinkTestApp()
+	app.Spec.NetworkPolicy = &v1alpha1.NetworkPolicyConfig{Enabled: true}
+	_, err = FetchNetworkPolicyCreateObj(&app, "hash")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,85 @@
+package flink
+
+import (
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"k8s.io/api/policy/v1beta1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// By default at most one pod of each deployment may be evicted at a time, so that node drains do not take down
+// several TaskManagers at once
+var defaultMaxUnavailable = intstr.FromInt(1)
+
+func isPodDisruptionBudgetEnabled(config *v1alpha1.PodDisruptionBudgetConfig) bool {
+	return config == nil || !config.Disabled
+}
+
+func getMaxUnavailable(config *v1alpha1.PodDisruptionBudgetConfig) intstr.IntOrString {
+	if config == nil || config.MaxUnavailable == nil {
+		return defaultMaxUnavailable
+	}
+	return *config.MaxUnavailable
+}
+
+// PodDisruptionBudgets are created per hash alongside the deployments they cover, and are named after them
+func podDisruptionBudgetCreateObj(app *v1alpha1.FlinkApplication, hash string, name string, deploymentType string,
+	config *v1alpha1.PodDisruptionBudgetConfig) *v1beta1.PodDisruptionBudget {
+	labels := getCommonAppLabels(app)
+	labels[FlinkAppHash] = hash
+	labels[FlinkDeploymentType] = deploymentType
+
+	maxUnavailable := getMaxUnavailable(config)
+	return &v1beta1.PodDisruptionBudget{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: v1beta1.SchemeGroupVersion.String(),
+			Kind:       k8.PodDisruptionBudget,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      name,
+			Namespace: app.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metaV1.OwnerReference{
+				*metaV1.NewControllerRef(app, app.GroupVersionKind()),
+			},
+		},
+		Spec: v1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metaV1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+}
+
+func podDisruptionBudgetDeleteObj(app *v1alpha1.FlinkApplication, name string) *v1beta1.PodDisruptionBudget {
+	return &v1beta1.PodDisruptionBudget{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: v1beta1.SchemeGroupVersion.String(),
+			Kind:       k8.PodDisruptionBudget,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      name,
+			Namespace: app.Namespace,
+		},
+	}
+}
+
+func FetchJobManagerPodDisruptionBudgetCreateObj(app *v1alpha1.FlinkApplication, hash string) *v1beta1.PodDisruptionBudget {
+	return podDisruptionBudgetCreateObj(app, hash, getJobManagerName(app, hash), FlinkDeploymentTypeJobmanager,
+		app.Spec.JobManagerConfig.PodDisruptionBudget)
+}
+
+func FetchJobManagerPodDisruptionBudgetDeleteObj(app *v1alpha1.FlinkApplication, hash string) *v1beta1.PodDisruptionBudget {
+	return podDisruptionBudgetDeleteObj(app, getJobManagerName(app, hash))
+}
+
+func FetchTaskManagerPodDisruptionBudgetCreateObj(app *v1alpha1.FlinkApplication, hash string) *v1beta1.PodDisruptionBudget {
+	return podDisruptionBudgetCreateObj(app, hash, getTaskManagerName(app, hash), FlinkDeploymentTypeTaskmanager,
+		app.Spec.TaskManagerConfig.PodDisruptionBudget)
+}
+
+func FetchTaskManagerPodDisruptionBudgetDeleteObj(app *v1alpha1.FlinkApplication, hash string) *v1beta1.PodDisruptionBudget {
+	return podDisruptionBudgetDeleteObj(app, getTaskManagerName(app, hash))
+}
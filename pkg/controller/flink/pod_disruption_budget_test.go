@@ -0,0 +1,45 @@
+package flink
+
+import (
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestPodDisruptionBudgetDefaults(t *testing.T) {
+	app := getFlinkTestApp()
+	hash := HashForApplication(&app)
+
+	pdb := FetchJobManagerPodDisruptionBudgetCreateObj(&app, hash)
+	assert.Equal(t, getJobManagerName(&app, hash), pdb.Name)
+	assert.Equal(t, app.Namespace, pdb.Namespace)
+	assert.Equal(t, intstr.FromInt(1), *pdb.Spec.MaxUnavailable)
+	assert.Equal(t, map[string]string{
+		"flink-app":             app.Name,
+		"flink-app-hash":        hash,
+		"flink-deployment-type": "jobmanager",
+	}, pdb.Spec.Selector.MatchLabels)
+
+	assert.True(t, isPodDisruptionBudgetEnabled(app.Spec.TaskManagerConfig.PodDisruptionBudget))
+}
+
+func TestPodDisruptionBudgetConfig(t *testing.T) {
+	app := getFlinkTestApp()
+	maxUnavailable := intstr.FromString("25%")
+	app.Spec.TaskManagerConfig.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetConfig{
+		MaxUnavailable: &maxUnavailable,
+	}
+	hash := HashForApplication(&app)
+
+	pdb := FetchTaskManagerPodDisruptionBudgetCreateObj(&app, hash)
+	assert.Equal(t, getTaskManagerName(&app, hash), pdb.Name)
+	assert.Equal(t, maxUnavailable, *pdb.Spec.MaxUnavailable)
+	assert.Equal(t, "taskmanager", pdb.Spec.Selector.MatchLabels[FlinkDeploymentType])
+
+	// the budget does not affect the deployments, so changing it does not trigger a redeploy
+	app.Spec.TaskManagerConfig.PodDisruptionBudget.Disabled = true
+	assert.False(t, isPodDisruptionBudgetEnabled(app.Spec.TaskManagerConfig.PodDisruptionBudget))
+	assert.Equal(t, hash, HashForApplication(&app))
+}
@@ -0,0 +1,58 @@
+package flink
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Values that are derived from other Kubernetes objects, such as the REST client built from an application's secrets,
+// are resolved once per reconcile instead of on every call to the JobManager
+type reconcileCache struct {
+	lock   sync.Mutex
+	values map[reconcileCacheKey]interface{}
+}
+
+type reconcileCacheKey struct {
+	kind string
+	app  types.NamespacedName
+}
+
+type reconcileCacheContextKey struct{}
+
+// Returns a context in which values derived from other Kubernetes objects are resolved at most once. A new context
+// should be used for every reconcile, so that changes to those objects are picked up by the next one.
+func WithReconcileCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reconcileCacheContextKey{}, &reconcileCache{
+		values: map[reconcileCacheKey]interface{}{},
+	})
+}
+
+// Returns the value of the given kind resolved earlier in this reconcile, or calls resolve. Errors are not cached, and
+// nothing is cached if the context was not created by WithReconcileCache.
+func resolveOnce(ctx context.Context, kind string, app *v1alpha1.FlinkApplication,
+	resolve func() (interface{}, error)) (interface{}, error) {
+	cache, ok := ctx.Value(reconcileCacheContextKey{}).(*reconcileCache)
+	if !ok {
+		return resolve()
+	}
+
+	key := reconcileCacheKey{
+		kind: kind,
+		app:  types.NamespacedName{Namespace: app.Namespace, Name: app.Name},
+	}
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if value, ok := cache.values[key]; ok {
+		return value, nil
+	}
+
+	value, err := resolve()
+	if err != nil {
+		return nil, err
+	}
+	cache.values[key] = value
+	return value, nil
+}
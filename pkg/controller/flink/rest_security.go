@@ -0,0 +1,237 @@
+package flink
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+const (
+	RestTLSVolumeName = "flink-rest-tls"
+	RestTLSMountPath  = "/etc/flink/rest-tls"
+
+	// Keys read from the TLS secret. The keystore and truststore are used by Flink, while the PEM files are used by
+	// the operator to connect to the REST endpoint.
+	RestKeystoreKey           = "keystore.jks"
+	RestTruststoreKey         = "truststore.jks"
+	RestKeystorePasswordKey   = "keystore-password"
+	RestKeyPasswordKey        = "key-password"
+	RestTruststorePasswordKey = "truststore-password"
+	RestCACertKey             = "ca.crt"
+	RestClientCertKey         = "tls.crt"
+	RestClientKeyKey          = "tls.key"
+
+	// Keys read from the auth secret
+	RestAuthTokenKey    = "token"
+	RestAuthUsernameKey = "username"
+	RestAuthPasswordKey = "password"
+
+	// Flink does not support reading secrets from the environment, so the passwords are exposed to the container as
+	// environment variables and appended to flink-conf.yaml by the entrypoint, or passed as dynamic properties in the
+	// ConfigMap config mode
+	RestKeystorePasswordEnvVar   = "FLINK_REST_SSL_KEYSTORE_PASSWORD"
+	RestKeyPasswordEnvVar        = "FLINK_REST_SSL_KEY_PASSWORD"
+	RestTruststorePasswordEnvVar = "FLINK_REST_SSL_TRUSTSTORE_PASSWORD"
+)
+
+func isRestTLSEnabled(app *v1alpha1.FlinkApplication) bool {
+	return app.Spec.RestSecurity != nil && app.Spec.RestSecurity.TLSEnabled
+}
+
+func getRestScheme(app *v1alpha1.FlinkApplication) string {
+	if isRestTLSEnabled(app) {
+		return "https"
+	}
+	return "http"
+}
+
+func isRestMutualTLSEnabled(app *v1alpha1.FlinkApplication) bool {
+	return isRestTLSEnabled(app) && app.Spec.RestSecurity.MutualTLS
+}
+
+// Adds the flink configuration needed to serve the REST endpoint over TLS
+func addRestSecurityConfig(app *v1alpha1.FlinkApplication, config *v1alpha1.FlinkConfig) {
+	if !isRestTLSEnabled(app) {
+		return
+	}
+
+	(*config)["security.ssl.rest.enabled"] = true
+	(*config)["security.ssl.rest.authentication-enabled"] = isRestMutualTLSEnabled(app)
+	(*config)["security.ssl.rest.keystore"] = fmt.Sprintf("%s/%s", RestTLSMountPath, RestKeystoreKey)
+	(*config)["security.ssl.rest.truststore"] = fmt.Sprintf("%s/%s", RestTLSMountPath, RestTruststoreKey)
+}
+
+// Returns the keystore and truststore passwords by configuration key, along with the environment variables that expose
+// them to the container
+func getRestSecurityPodConfig(app *v1alpha1.FlinkApplication) map[string]string {
+	if !isRestTLSEnabled(app) {
+		return map[string]string{}
+	}
+
+	return map[string]string{
+		"security.ssl.rest.keystore-password":   RestKeystorePasswordEnvVar,
+		"security.ssl.rest.key-password":        RestKeyPasswordEnvVar,
+		"security.ssl.rest.truststore-password": RestTruststorePasswordEnvVar,
+	}
+}
+
+func getRestSecurityVolumes(app *v1alpha1.FlinkApplication) []coreV1.Volume {
+	if !isRestTLSEnabled(app) {
+		return nil
+	}
+
+	return []coreV1.Volume{
+		{
+			Name: RestTLSVolumeName,
+			VolumeSource: coreV1.VolumeSource{
+				Secret: &coreV1.SecretVolumeSource{
+					SecretName: app.Spec.RestSecurity.TLSSecretName,
+				},
+			},
+		},
+	}
+}
+
+func getRestSecurityVolumeMounts(app *v1alpha1.FlinkApplication) []coreV1.VolumeMount {
+	if !isRestTLSEnabled(app) {
+		return nil
+	}
+
+	return []coreV1.VolumeMount{
+		{
+			Name:      RestTLSVolumeName,
+			MountPath: RestTLSMountPath,
+			ReadOnly:  true,
+		},
+	}
+}
+
+func getRestSecurityEnv(app *v1alpha1.FlinkApplication) []coreV1.EnvVar {
+	if !isRestTLSEnabled(app) {
+		return nil
+	}
+
+	secretEnv := func(name string, key string) coreV1.EnvVar {
+		return coreV1.EnvVar{
+			Name: name,
+			ValueFrom: &coreV1.EnvVarSource{
+				SecretKeyRef: &coreV1.SecretKeySelector{
+					LocalObjectReference: coreV1.LocalObjectReference{
+						Name: app.Spec.RestSecurity.TLSSecretName,
+					},
+					Key: key,
+				},
+			},
+		}
+	}
+
+	return []coreV1.EnvVar{
+		secretEnv(RestKeystorePasswordEnvVar, RestKeystorePasswordKey),
+		secretEnv(RestKeyPasswordEnvVar, RestKeyPasswordKey),
+		secretEnv(RestTruststorePasswordEnvVar, RestTruststorePasswordKey),
+	}
+}
+
+// Returns the volumes for the application's pods, without modifying the application
+func getVolumes(app *v1alpha1.FlinkApplication) []coreV1.Volume {
+	operatorVolumes := append(getRestSecurityVolumes(app), getFlinkConfigVolumes(app)...)
+	if len(operatorVolumes) == 0 {
+		return app.Spec.Volumes
+	}
+	volumes := make([]coreV1.Volume, 0, len(app.Spec.Volumes)+len(operatorVolumes))
+	volumes = append(volumes, app.Spec.Volumes...)
+	return append(volumes, operatorVolumes...)
+}
+
+// Returns the volume mounts for the application's containers, without modifying the application
+func getVolumeMounts(app *v1alpha1.FlinkApplication) []coreV1.VolumeMount {
+	operatorMounts := append(getRestSecurityVolumeMounts(app), getFlinkConfigVolumeMounts(app)...)
+	if len(operatorMounts) == 0 {
+		return app.Spec.VolumeMounts
+	}
+	mounts := make([]coreV1.VolumeMount, 0, len(app.Spec.VolumeMounts)+len(operatorMounts))
+	mounts = append(mounts, app.Spec.VolumeMounts...)
+	return append(mounts, operatorMounts...)
+}
+
+// Builds the settings used to connect to the application's REST endpoint from the referenced secrets. Also returns a
+// version string that changes whenever the config or the secrets change.
+func (f *Controller) getRestSecurity(ctx context.Context, app *v1alpha1.FlinkApplication) (*client.RestSecurity, string, error) {
+	spec := app.Spec.RestSecurity
+	security := client.RestSecurity{}
+	version := fmt.Sprintf("%+v", *spec)
+
+	if spec.TLSEnabled {
+		if spec.TLSSecretName == "" {
+			return nil, "", flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "tlsSecretName must be set when TLS is enabled")
+		}
+		secret, err := f.k8Cluster.GetSecret(ctx, app.Namespace, spec.TLSSecretName)
+		if err != nil {
+			return nil, "", getSecretError(err, "failed to get TLS secret %s", spec.TLSSecretName)
+		}
+		tlsConfig, err := buildRestTLSConfig(secret, spec.MutualTLS)
+		if err != nil {
+			return nil, "", err
+		}
+		security.TLSConfig = tlsConfig
+		version += "/" + secret.ResourceVersion
+	}
+
+	if spec.AuthSecretName != "" {
+		secret, err := f.k8Cluster.GetSecret(ctx, app.Namespace, spec.AuthSecretName)
+		if err != nil {
+			return nil, "", getSecretError(err, "failed to get auth secret %s", spec.AuthSecretName)
+		}
+		if token, ok := secret.Data[RestAuthTokenKey]; ok {
+			security.BearerToken = string(token)
+		} else if username, ok := secret.Data[RestAuthUsernameKey]; ok {
+			security.Username = string(username)
+			security.Password = string(secret.Data[RestAuthPasswordKey])
+		} else {
+			return nil, "", flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "auth secret %s must contain either %s or %s",
+				spec.AuthSecretName, RestAuthTokenKey, RestAuthUsernameKey)
+		}
+		version += "/" + secret.ResourceVersion
+	}
+
+	return &security, version, nil
+}
+
+// Secrets that do not exist have to be created by the user, while other errors getting them are transient
+func getSecretError(err error, msgFmt string, args ...interface{}) error {
+	if k8.IsK8sObjectDoesNotExist(err) {
+		return flinkErrors.WrapErrorf(flinkErrors.BadJobSpecificationError, err, msgFmt, args...)
+	}
+	return errors.Wrapf(err, msgFmt, args...)
+}
+
+func buildRestTLSConfig(secret *coreV1.Secret, mutualTLS bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if ca, ok := secret.Data[RestCACertKey]; ok {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "failed to parse %s from secret %s", RestCACertKey, secret.Name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if mutualTLS {
+		cert, err := tls.X509KeyPair(secret.Data[RestClientCertKey], secret.Data[RestClientKeyKey])
+		if err != nil {
+			return nil, flinkErrors.WrapErrorf(flinkErrors.BadJobSpecificationError, err,
+				"failed to load client certificate from secret %s", secret.Name)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
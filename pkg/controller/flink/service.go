@@ -0,0 +1,143 @@
+package flink
+
+import (
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+func getJobManagerServiceConfig(app *v1alpha1.FlinkApplication) v1alpha1.JobManagerServiceConfig {
+	if app.Spec.JobManagerConfig.Service == nil {
+		return v1alpha1.JobManagerServiceConfig{}
+	}
+	return *app.Spec.JobManagerConfig.Service
+}
+
+func getJobManagerServiceType(app *v1alpha1.FlinkApplication) coreV1.ServiceType {
+	if serviceType := getJobManagerServiceConfig(app).Type; serviceType != "" {
+		return serviceType
+	}
+	return coreV1.ServiceTypeClusterIP
+}
+
+// Returns the ports exposed by the generic service, in the order of getJobManagerServicePorts
+func getExposedJobManagerServicePorts(app *v1alpha1.FlinkApplication) []coreV1.ServicePort {
+	ports := getJobManagerServicePorts(app)
+	names := getJobManagerServiceConfig(app).Ports
+	if len(names) == 0 {
+		return ports
+	}
+
+	exposed := make([]coreV1.ServicePort, 0, len(names))
+	for _, p := range ports {
+		for _, name := range names {
+			if p.Name == name {
+				exposed = append(exposed, p)
+				break
+			}
+		}
+	}
+	return exposed
+}
+
+func ValidateJobManagerService(app *v1alpha1.FlinkApplication) error {
+	serviceConfig := getJobManagerServiceConfig(app)
+	serviceType := getJobManagerServiceType(app)
+	switch serviceType {
+	case coreV1.ServiceTypeClusterIP, coreV1.ServiceTypeNodePort, coreV1.ServiceTypeLoadBalancer:
+	default:
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "unsupported jobmanager service type %s: must be one of %s, %s or %s",
+			serviceType, coreV1.ServiceTypeClusterIP, coreV1.ServiceTypeNodePort, coreV1.ServiceTypeLoadBalancer)
+	}
+	if len(serviceConfig.LoadBalancerSourceRanges) > 0 && serviceType != coreV1.ServiceTypeLoadBalancer {
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "jobmanager service loadBalancerSourceRanges require the %s type", coreV1.ServiceTypeLoadBalancer)
+	}
+
+	exposesUIPort := false
+	for _, name := range serviceConfig.Ports {
+		known := false
+		for _, p := range getJobManagerServicePorts(app) {
+			known = known || p.Name == name
+		}
+		if !known {
+			return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "unknown jobmanager service port %s", name)
+		}
+		exposesUIPort = exposesUIPort || name == FlinkUIPortName
+	}
+	// the ingress routes to the UI port of the generic service
+	if isIngressEnabled(app) && len(serviceConfig.Ports) > 0 && !exposesUIPort {
+		return flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "the jobmanager service must expose the %s port used by the ingress", FlinkUIPortName)
+	}
+	return nil
+}
+
+// Compares the ports by the fields set by the operator, as the others are defaulted by the API server
+func servicePortsEqual(a []coreV1.ServicePort, b []coreV1.ServicePort) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Port != b[i].Port || a[i].NodePort != b[i].NodePort {
+			return false
+		}
+	}
+	return true
+}
+
+// Applies the exposure configured in the application to the generic service, returning true if it was changed. Node
+// ports already allocated to the service are kept, so that they do not change with every deploy. Without a service
+// config, the service is reset to an internal service exposing all ports, as the operator creates it.
+func UpdateJobManagerServiceExposure(service *coreV1.Service, app *v1alpha1.FlinkApplication) bool {
+	serviceConfig := getJobManagerServiceConfig(app)
+	serviceType := getJobManagerServiceType(app)
+
+	ports := getExposedJobManagerServicePorts(app)
+	if serviceType != coreV1.ServiceTypeClusterIP {
+		for i := range ports {
+			for _, existing := range service.Spec.Ports {
+				if existing.Name == ports[i].Name {
+					ports[i].NodePort = existing.NodePort
+				}
+			}
+		}
+	}
+
+	var sourceRanges []string
+	if serviceType == coreV1.ServiceTypeLoadBalancer {
+		sourceRanges = serviceConfig.LoadBalancerSourceRanges
+	}
+
+	changed := false
+	// the type is defaulted to ClusterIP by the API server
+	if service.Spec.Type != serviceType && (service.Spec.Type != "" || serviceType != coreV1.ServiceTypeClusterIP) {
+		service.Spec.Type = serviceType
+		changed = true
+	}
+	if !servicePortsEqual(service.Spec.Ports, ports) {
+		service.Spec.Ports = ports
+		changed = true
+	}
+	if !equality.Semantic.DeepEqual(service.Spec.LoadBalancerSourceRanges, sourceRanges) {
+		service.Spec.LoadBalancerSourceRanges = sourceRanges
+		changed = true
+	}
+	// annotations that are no longer configured are left in place, as they may have been added by other controllers
+	for k, v := range serviceConfig.Annotations {
+		if service.Annotations[k] != v {
+			if service.Annotations == nil {
+				service.Annotations = map[string]string{}
+			}
+			service.Annotations[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Creates the generic service, which is exposed as configured in the application
+func FetchJobManagerGenericServiceCreateObj(app *v1alpha1.FlinkApplication, hash string) *coreV1.Service {
+	service := FetchJobManagerServiceCreateObj(app, hash)
+	UpdateJobManagerServiceExposure(service, app)
+	return service
+}
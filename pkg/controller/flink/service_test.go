@@ -0,0 +1,106 @@
+package flink
+
+import (
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+func getServiceTestApp() v1alpha1.FlinkApplication {
+	app := getFlinkTestApp()
+	app.Spec.JobManagerConfig.Service = &v1alpha1.JobManagerServiceConfig{
+		Type: coreV1.ServiceTypeLoadBalancer,
+		Annotations: map[string]string{
+			"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+		},
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		Ports:                    []string{FlinkUIPortName},
+	}
+	return app
+}
+
+func TestFetchJobManagerGenericServiceCreateObj(t *testing.T) {
+	app := getServiceTestApp()
+	hash := HashForApplication(&app)
+
+	service := FetchJobManagerGenericServiceCreateObj(&app, hash)
+	assert.Equal(t, app.Name, service.Name)
+	assert.Equal(t, coreV1.ServiceTypeLoadBalancer, service.Spec.Type)
+	assert.Equal(t, "true", service.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"])
+	assert.Equal(t, []string{"10.0.0.0/8"}, service.Spec.LoadBalancerSourceRanges)
+	assert.Equal(t, 1, len(service.Spec.Ports))
+	assert.Equal(t, FlinkUIPortName, service.Spec.Ports[0].Name)
+	assert.Equal(t, int32(8081), service.Spec.Ports[0].Port)
+
+	// the service config does not change the hash
+	assert.Equal(t, testAppHash, hash)
+
+	// applications without a service config get the same service as before
+	app = getFlinkTestApp()
+	service = FetchJobManagerGenericServiceCreateObj(&app, hash)
+	assert.Empty(t, service.Spec.Type)
+	assert.Equal(t, 5, len(service.Spec.Ports))
+}
+
+func TestUpdateJobManagerServiceExposure(t *testing.T) {
+	app := getServiceTestApp()
+	app.Spec.JobManagerConfig.Service.Type = coreV1.ServiceTypeNodePort
+	app.Spec.JobManagerConfig.Service.LoadBalancerSourceRanges = nil
+	app.Spec.JobManagerConfig.Service.Ports = []string{FlinkUIPortName, FlinkRPCPortName}
+
+	service := FetchJobManagerServiceCreateObj(&app, testAppHash)
+	service.Spec.Type = coreV1.ServiceTypeNodePort
+	service.Spec.Ports[0].NodePort = 30123
+	service.Spec.Ports[3].NodePort = 30081
+
+	assert.True(t, UpdateJobManagerServiceExposure(service, &app))
+	// allocated node ports are kept
+	assert.Equal(t, []coreV1.ServicePort{
+		{Name: FlinkRPCPortName, Port: 6123, NodePort: 30123},
+		{Name: FlinkUIPortName, Port: 8081, NodePort: 30081},
+	}, service.Spec.Ports)
+
+	// once applied, the service is unchanged
+	assert.False(t, UpdateJobManagerServiceExposure(service, &app))
+
+	// switching back to ClusterIP releases the node ports
+	app.Spec.JobManagerConfig.Service.Type = coreV1.ServiceTypeClusterIP
+	assert.True(t, UpdateJobManagerServiceExposure(service, &app))
+	assert.Equal(t, coreV1.ServiceTypeClusterIP, service.Spec.Type)
+	assert.Equal(t, int32(0), service.Spec.Ports[0].NodePort)
+
+	// without a service config, the service is reset to how the operator creates it
+	app.Spec.JobManagerConfig.Service = nil
+	service.Spec.Type = coreV1.ServiceTypeLoadBalancer
+	service.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	assert.True(t, UpdateJobManagerServiceExposure(service, &app))
+	assert.Equal(t, coreV1.ServiceTypeClusterIP, service.Spec.Type)
+	assert.Nil(t, service.Spec.LoadBalancerSourceRanges)
+	assert.Equal(t, getJobManagerServicePorts(&app), service.Spec.Ports)
+	assert.False(t, UpdateJobManagerServiceExposure(service, &app))
+}
+
+func TestValidateJobManagerService(t *testing.T) {
+	app := getServiceTestApp()
+	assert.Nil(t, ValidateJobManagerService(&app))
+
+	app.Spec.JobManagerConfig.Service.Type = coreV1.ServiceTypeExternalName
+	assert.EqualError(t, ValidateJobManagerService(&app),
+		"ErrorCode: [BadJobSpecificationError] Reason: [unsupported jobmanager service type ExternalName: must be one of ClusterIP, NodePort or LoadBalancer]")
+
+	app.Spec.JobManagerConfig.Service.Type = coreV1.ServiceTypeNodePort
+	assert.EqualError(t, ValidateJobManagerService(&app),
+		"ErrorCode: [BadJobSpecificationError] Reason: [jobmanager service loadBalancerSourceRanges require the LoadBalancer type]")
+
+	app = getServiceTestApp()
+	app.Spec.JobManagerConfig.Service.Ports = []string{"web"}
+	assert.EqualError(t, ValidateJobManagerService(&app), "ErrorCode: [BadJobSpecificationError] Reason: [unknown jobmanager service port web]")
+
+	app.Spec.JobManagerConfig.Service.Ports = []string{FlinkRPCPortName}
+	assert.EqualError(t, ValidateJobManagerService(&app), "ErrorCode: [BadJobSpecificationError] Reason: [the jobmanager service must expose the ui port used by the ingress]")
+
+	app.Spec.Ingress = &v1alpha1.IngressConfig{Disabled: true}
+	assert.Nil(t, ValidateJobManagerService(&app))
+}
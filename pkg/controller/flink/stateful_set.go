@@ -0,0 +1,203 @@
+package flink
+
+import (
+	"fmt"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	v1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const TaskManagerPodNameEnvVar = "TASKMANAGER_POD_NAME"
+
+func isStatefulSetEnabled(app *v1alpha1.FlinkApplication) bool {
+	return app.Spec.TaskManagerConfig.StatefulSet != nil
+}
+
+// The headless service governing the StatefulSet shares its name
+func getTaskManagerServiceName(app *v1alpha1.FlinkApplication, hash string) string {
+	return getTaskManagerName(app, hash)
+}
+
+// Translates a FlinkApplication into a TaskManager StatefulSet. Like taskmanagerTemplate, this is hashed, so changes
+// to it will cause redeployments of all applications running their TaskManagers as a StatefulSet.
+func taskmanagerStatefulSetTemplate(app *v1alpha1.FlinkApplication) *v1.StatefulSet {
+	deployment := taskmanagerTemplate(app)
+	statefulSetConfig := app.Spec.TaskManagerConfig.StatefulSet
+
+	podSpec := deployment.Spec.Template.Spec
+	container := &podSpec.Containers[0]
+	mounts := make([]coreV1.VolumeMount, 0, len(container.VolumeMounts)+len(statefulSetConfig.VolumeMounts))
+	mounts = append(mounts, container.VolumeMounts...)
+	container.VolumeMounts = append(mounts, statefulSetConfig.VolumeMounts...)
+
+	// the claims are labelled like the pods, so that they can be found and deleted along with the cluster
+	claims := make([]coreV1.PersistentVolumeClaim, 0, len(statefulSetConfig.VolumeClaimTemplates))
+	for _, claim := range statefulSetConfig.VolumeClaimTemplates {
+		claim.Labels = common.CopyMap(common.DuplicateMap(claim.Labels), deployment.Labels)
+		claims = append(claims, claim)
+	}
+
+	return &v1.StatefulSet{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       k8.StatefulSet,
+		},
+		ObjectMeta: deployment.ObjectMeta,
+		Spec: v1.StatefulSetSpec{
+			Selector: deployment.Spec.Selector,
+			Replicas: deployment.Spec.Replicas,
+			Template: coreV1.PodTemplateSpec{
+				ObjectMeta: deployment.Spec.Template.ObjectMeta,
+				Spec:       podSpec,
+			},
+			VolumeClaimTemplates: claims,
+			// the TaskManagers do not depend on each other, so there is no need to start them one at a time
+			PodManagementPolicy: v1.ParallelPodManagement,
+		},
+	}
+}
+
+// Advertises the stable hostname of each pod, which is resolved through the headless service, instead of its IP
+func setStableTaskManagerHostname(podSpec *coreV1.PodSpec, app *v1alpha1.FlinkApplication, hash string) {
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		env := make([]coreV1.EnvVar, 0, len(container.Env)+1)
+		for _, envVar := range container.Env {
+			if envVar.Name == TaskManagerHostnameEnvVar {
+				// variables can only reference those defined before them
+				env = append(env, coreV1.EnvVar{
+					Name: TaskManagerPodNameEnvVar,
+					ValueFrom: &coreV1.EnvVarSource{
+						FieldRef: &coreV1.ObjectFieldSelector{
+							FieldPath: "metadata.name",
+						},
+					},
+				})
+				envVar = coreV1.EnvVar{
+					Name: TaskManagerHostnameEnvVar,
+					Value: fmt.Sprintf("$(%s).%s.%s.svc", TaskManagerPodNameEnvVar,
+						getTaskManagerServiceName(app, hash), app.Namespace),
+				}
+			}
+			env = append(env, envVar)
+		}
+		container.Env = env
+	}
+}
+
+func FetchTaskManagerStatefulSetCreateObj(app *v1alpha1.FlinkApplication, hash string) *v1.StatefulSet {
+	template := taskmanagerStatefulSetTemplate(app.DeepCopy())
+
+	// the pods of a StatefulSet are named after it, so the pod template is left unnamed
+	template.Name = getTaskManagerName(app, hash)
+	template.Labels[FlinkAppHash] = hash
+	template.Spec.Template.Labels[FlinkAppHash] = hash
+	template.Spec.Selector.MatchLabels[FlinkAppHash] = hash
+	template.Spec.ServiceName = getTaskManagerServiceName(app, hash)
+	for i := range template.Spec.VolumeClaimTemplates {
+		template.Spec.VolumeClaimTemplates[i].Labels[FlinkAppHash] = hash
+	}
+
+	injectHashesIntoPodSpec(&template.Spec.Template.Spec, app, hash)
+	setStableTaskManagerHostname(&template.Spec.Template.Spec, app, hash)
+
+	return template
+}
+
+func FetchTaskManagerStatefulSetDeleteObj(app *v1alpha1.FlinkApplication, hash string) *v1.StatefulSet {
+	return &v1.StatefulSet{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       k8.StatefulSet,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Namespace: app.Namespace,
+			Name:      getTaskManagerName(app, hash),
+		},
+	}
+}
+
+// Creates the headless service that provides the DNS records for the stable hostnames of the TaskManagers. The
+// records are published before the pods are ready, as the TaskManagers resolve their own hostname on startup.
+func FetchTaskManagerServiceCreateObj(app *v1alpha1.FlinkApplication, hash string) *coreV1.Service {
+	labels := getCommonAppLabels(app)
+	labels[FlinkAppHash] = hash
+	labels[FlinkDeploymentType] = FlinkDeploymentTypeTaskmanager
+
+	ports := GetTaskManagerPorts(app)
+	servicePorts := make([]coreV1.ServicePort, 0, len(ports))
+	for _, p := range ports {
+		servicePorts = append(servicePorts, coreV1.ServicePort{
+			Name: p.Name,
+			Port: p.ContainerPort,
+		})
+	}
+
+	return &coreV1.Service{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       k8.Service,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      getTaskManagerServiceName(app, hash),
+			Namespace: app.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metaV1.OwnerReference{
+				*metaV1.NewControllerRef(app, app.GroupVersionKind()),
+			},
+		},
+		Spec: coreV1.ServiceSpec{
+			ClusterIP:                coreV1.ClusterIPNone,
+			PublishNotReadyAddresses: true,
+			Ports:                    servicePorts,
+			Selector:                 labels,
+		},
+	}
+}
+
+func FetchTaskManagerServiceDeleteObj(app *v1alpha1.FlinkApplication, hash string) *coreV1.Service {
+	return &coreV1.Service{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       k8.Service,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      getTaskManagerServiceName(app, hash),
+			Namespace: app.Namespace,
+		},
+	}
+}
+
+func TaskManagerStatefulSetMatches(statefulSet *v1.StatefulSet, application *v1alpha1.FlinkApplication) bool {
+	statefulSetFromApp := FetchTaskManagerStatefulSetCreateObj(application, HashForApplication(application))
+	if len(statefulSetFromApp.Spec.VolumeClaimTemplates) != len(statefulSet.Spec.VolumeClaimTemplates) {
+		return false
+	}
+	// the claim specs are defaulted by the API server, so only their names are compared
+	for i, claim := range statefulSetFromApp.Spec.VolumeClaimTemplates {
+		if claim.Name != statefulSet.Spec.VolumeClaimTemplates[i].Name {
+			return false
+		}
+	}
+	return DeploymentsEqual(statefulSetAsDeployment(statefulSetFromApp), statefulSetAsDeployment(statefulSet))
+}
+
+// Returns a deployment with the same metadata and pod template as the StatefulSet, so that they can be compared with
+// DeploymentsEqual
+func statefulSetAsDeployment(statefulSet *v1.StatefulSet) *v1.Deployment {
+	return &v1.Deployment{
+		ObjectMeta: statefulSet.ObjectMeta,
+		Spec: v1.DeploymentSpec{
+			Replicas: statefulSet.Spec.Replicas,
+			Template: statefulSet.Spec.Template,
+		},
+	}
+}
+
+func isStatefulSetReady(statefulSet *v1.StatefulSet) bool {
+	return statefulSet.Spec.Replicas == nil || statefulSet.Status.ReadyReplicas >= *statefulSet.Spec.Replicas
+}
@@ -0,0 +1,179 @@
+package flink
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	k8mock "github.com/lyft/flinkk8soperator/pkg/controller/k8/mock"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func getStatefulSetTestApp() v1alpha1.FlinkApplication {
+	app := getFlinkTestApp()
+	app.Spec.TaskManagerConfig.StatefulSet = &v1alpha1.StatefulSetConfig{
+		VolumeClaimTemplates: []coreV1.PersistentVolumeClaim{{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name: "local-state",
+			},
+			Spec: coreV1.PersistentVolumeClaimSpec{
+				AccessModes: []coreV1.PersistentVolumeAccessMode{coreV1.ReadWriteOnce},
+				Resources: coreV1.ResourceRequirements{
+					Requests: coreV1.ResourceList{
+						coreV1.ResourceStorage: resource.MustParse("10Gi"),
+					},
+				},
+			},
+		}},
+		VolumeMounts: []coreV1.VolumeMount{{
+			Name:      "local-state",
+			MountPath: "/flink/local-state",
+		}},
+	}
+	return app
+}
+
+func TestFetchTaskManagerStatefulSetCreateObj(t *testing.T) {
+	app := getStatefulSetTestApp()
+	hash := HashForApplication(&app)
+
+	statefulSet := FetchTaskManagerStatefulSetCreateObj(&app, hash)
+	assert.Equal(t, getTaskManagerName(&app, hash), statefulSet.Name)
+	assert.Equal(t, getTaskManagerServiceName(&app, hash), statefulSet.Spec.ServiceName)
+	assert.Equal(t, v1.PodManagementPolicyType(v1.ParallelPodManagement), statefulSet.Spec.PodManagementPolicy)
+	assert.Equal(t, hash, statefulSet.Spec.Selector.MatchLabels[FlinkAppHash])
+	assert.Equal(t, app.Name, statefulSet.OwnerReferences[0].Name)
+
+	// the claims are labelled so that they can be deleted along with the cluster
+	claim := statefulSet.Spec.VolumeClaimTemplates[0]
+	assert.Equal(t, "local-state", claim.Name)
+	assert.Equal(t, hash, claim.Labels[FlinkAppHash])
+	assert.Equal(t, app.Name, claim.Labels[k8.AppKey])
+	assert.Nil(t, app.Spec.TaskManagerConfig.StatefulSet.VolumeClaimTemplates[0].Labels)
+
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "/flink/local-state", container.VolumeMounts[0].MountPath)
+	assert.Equal(t, "metadata.name", common.GetEnvVar(container.Env, TaskManagerPodNameEnvVar).ValueFrom.FieldRef.FieldPath)
+	assert.Equal(t, "$(TASKMANAGER_POD_NAME).app-name-"+hash+"-tm.ns.svc",
+		common.GetEnvVar(container.Env, TaskManagerHostnameEnvVar).Value)
+
+	assert.True(t, TaskManagerStatefulSetMatches(statefulSet, &app))
+	app.Spec.TaskManagerConfig.StatefulSet.VolumeClaimTemplates[0].Name = "other"
+	assert.False(t, TaskManagerStatefulSetMatches(statefulSet, &app))
+
+	// applications running their TaskManagers as a deployment are unchanged
+	app = getFlinkTestApp()
+	assert.Equal(t, testAppHash, HashForApplication(&app))
+}
+
+func TestFetchTaskManagerServiceCreateObj(t *testing.T) {
+	app := getStatefulSetTestApp()
+	hash := HashForApplication(&app)
+
+	service := FetchTaskManagerServiceCreateObj(&app, hash)
+	assert.Equal(t, getTaskManagerServiceName(&app, hash), service.Name)
+	assert.Equal(t, coreV1.ClusterIPNone, service.Spec.ClusterIP)
+	assert.True(t, service.Spec.PublishNotReadyAddresses)
+	assert.Equal(t, FlinkDeploymentTypeTaskmanager, service.Spec.Selector[FlinkDeploymentType])
+	assert.Equal(t, hash, service.Spec.Selector[FlinkAppHash])
+}
+
+func TestTaskManagerCreateStatefulSet(t *testing.T) {
+	testController := getTMControllerForTest()
+	app := getStatefulSetTestApp()
+
+	var kinds []string
+	mockK8Cluster := testController.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.CreateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		switch object.(type) {
+		case *coreV1.Service, *v1.StatefulSet, *policyV1beta1.PodDisruptionBudget:
+			kinds = append(kinds, object.GetObjectKind().GroupVersionKind().Kind)
+		default:
+			assert.Fail(t, "unexpected object created")
+		}
+		return nil
+	}
+	newlyCreated, err := testController.CreateIfNotExist(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, newlyCreated)
+	assert.Equal(t, []string{"Service", "StatefulSet", "PodDisruptionBudget"}, kinds)
+}
+
+func TestGetCurrentAndOldDeploymentsForAppWithStatefulSet(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getStatefulSetTestApp()
+	hash := HashForApplication(&flinkApp)
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetDeploymentsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error) {
+		return &v1.DeploymentList{
+			Items: []v1.Deployment{
+				*FetchJobMangerDeploymentCreateObj(&flinkApp, hash),
+				*FetchJobMangerDeploymentCreateObj(&flinkApp, "old-hash"),
+				*FetchTaskMangerDeploymentCreateObj(&flinkApp, "old-hash"),
+			},
+		}, nil
+	}
+	mockK8Cluster.GetStatefulSetsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.StatefulSetList, error) {
+		assert.Equal(t, testNamespace, namespace)
+		assert.Equal(t, k8.GetAppLabel(testAppName), labelMap)
+		return &v1.StatefulSetList{
+			Items: []v1.StatefulSet{*FetchTaskManagerStatefulSetCreateObj(&flinkApp, hash)},
+		}, nil
+	}
+
+	cur, old, err := flinkControllerForTest.GetCurrentAndOldDeploymentsForApp(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.NotNil(t, cur)
+	assert.Nil(t, cur.Taskmanager)
+	assert.Equal(t, getTaskManagerName(&flinkApp, hash), cur.TaskmanagerStatefulSet.Name)
+	assert.Equal(t, 1, len(old))
+	assert.Equal(t, "old-hash", old[0].Hash)
+	assert.NotNil(t, old[0].Taskmanager)
+}
+
+func TestFlinkIsClusterReadyWithStatefulSet(t *testing.T) {
+	flinkControllerForTest := getTestFlinkController()
+	flinkApp := getStatefulSetTestApp()
+	hash := HashForApplication(&flinkApp)
+
+	mockK8Cluster := flinkControllerForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetDeploymentsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error) {
+		jmDeployment := FetchJobMangerDeploymentCreateObj(&flinkApp, hash)
+		jmDeployment.Status.AvailableReplicas = 1
+		return &v1.DeploymentList{
+			Items: []v1.Deployment{*jmDeployment},
+		}, nil
+	}
+
+	// the stateful set has not been created yet
+	result, err := flinkControllerForTest.IsClusterReady(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.False(t, result)
+
+	readyReplicas := int32(0)
+	mockK8Cluster.GetStatefulSetsWithLabelFunc = func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.StatefulSetList, error) {
+		assert.Equal(t, map[string]string{FlinkAppHash: hash}, labelMap)
+		statefulSet := FetchTaskManagerStatefulSetCreateObj(&flinkApp, hash)
+		statefulSet.Status.ReadyReplicas = readyReplicas
+		return &v1.StatefulSetList{
+			Items: []v1.StatefulSet{*statefulSet},
+		}, nil
+	}
+	result, err = flinkControllerForTest.IsClusterReady(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.False(t, result)
+
+	readyReplicas = 1
+	result, err = flinkControllerForTest.IsClusterReady(context.Background(), &flinkApp)
+	assert.Nil(t, err)
+	assert.True(t, result)
+}
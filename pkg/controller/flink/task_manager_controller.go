@@ -47,16 +47,28 @@ type TaskManagerController struct {
 func newTaskManagerMetrics(scope promutils.Scope) *taskManagerMetrics {
 	taskManagerControllerScope := scope.NewSubScope("task_manager_controller")
 	return &taskManagerMetrics{
-		scope:                     scope,
-		deploymentCreationSuccess: labeled.NewCounter("deployment_create_success", "Task manager deployment created successfully", taskManagerControllerScope),
-		deploymentCreationFailure: labeled.NewCounter("deployment_create_failure", "Task manager deployment creation failed", taskManagerControllerScope),
+		scope:                      scope,
+		deploymentCreationSuccess:  labeled.NewCounter("deployment_create_success", "Task manager deployment created successfully", taskManagerControllerScope),
+		deploymentCreationFailure:  labeled.NewCounter("deployment_create_failure", "Task manager deployment creation failed", taskManagerControllerScope),
+		statefulSetCreationSuccess: labeled.NewCounter("statefulset_create_success", "Task manager stateful set created successfully", taskManagerControllerScope),
+		statefulSetCreationFailure: labeled.NewCounter("statefulset_create_failure", "Task manager stateful set creation failed", taskManagerControllerScope),
+		serviceCreationSuccess:     labeled.NewCounter("service_create_success", "Task manager service created successfully", taskManagerControllerScope),
+		serviceCreationFailure:     labeled.NewCounter("service_create_failure", "Task manager service creation failed", taskManagerControllerScope),
+		pdbCreationSuccess:         labeled.NewCounter("pdb_create_success", "Task manager pod disruption budget created successfully", taskManagerControllerScope),
+		pdbCreationFailure:         labeled.NewCounter("pdb_create_failure", "Task manager pod disruption budget creation failed", taskManagerControllerScope),
 	}
 }
 
 type taskManagerMetrics struct {
-	scope                     promutils.Scope
-	deploymentCreationSuccess labeled.Counter
-	deploymentCreationFailure labeled.Counter
+	scope                      promutils.Scope
+	deploymentCreationSuccess  labeled.Counter
+	deploymentCreationFailure  labeled.Counter
+	statefulSetCreationSuccess labeled.Counter
+	statefulSetCreationFailure labeled.Counter
+	serviceCreationSuccess     labeled.Counter
+	serviceCreationFailure     labeled.Counter
+	pdbCreationSuccess         labeled.Counter
+	pdbCreationFailure         labeled.Counter
 }
 
 var TaskManagerDefaultResources = coreV1.ResourceRequirements{
@@ -72,7 +84,37 @@ var TaskManagerDefaultResources = coreV1.ResourceRequirements{
 
 func (t *TaskManagerController) CreateIfNotExist(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
 	hash := HashForApplication(application)
+	var newlyCreated bool
+	var err error
+	if isStatefulSetEnabled(application) {
+		newlyCreated, err = t.createStatefulSetIfNotExist(ctx, application, hash)
+	} else {
+		newlyCreated, err = t.createDeploymentIfNotExist(ctx, application, hash)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if isPodDisruptionBudgetEnabled(application.Spec.TaskManagerConfig.PodDisruptionBudget) {
+		pdb := FetchTaskManagerPodDisruptionBudgetCreateObj(application, hash)
+		err = t.k8Cluster.CreateK8Object(ctx, pdb)
+		if err != nil {
+			if !k8_err.IsAlreadyExists(err) {
+				logger.Errorf(ctx, "Taskmanager pod disruption budget creation failed %v", err)
+				t.metrics.pdbCreationFailure.Inc(ctx)
+				return false, err
+			}
+			logger.Infof(ctx, "Taskmanager pod disruption budget already exists")
+		} else {
+			newlyCreated = true
+			t.metrics.pdbCreationSuccess.Inc(ctx)
+		}
+	}
 
+	return newlyCreated, nil
+}
+
+func (t *TaskManagerController) createDeploymentIfNotExist(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
 	taskManagerDeployment := FetchTaskMangerDeploymentCreateObj(application, hash)
 	err := t.k8Cluster.CreateK8Object(ctx, taskManagerDeployment)
 	if err != nil {
@@ -82,12 +124,45 @@ func (t *TaskManagerController) CreateIfNotExist(ctx context.Context, applicatio
 			return false, err
 		}
 		logger.Infof(ctx, "Taskmanager deployment already exists")
+		return false, nil
+	}
+	t.metrics.deploymentCreationSuccess.Inc(ctx)
+	return true, nil
+}
+
+// The headless service is created first, so that the hostnames of the TaskManagers resolve once they start
+func (t *TaskManagerController) createStatefulSetIfNotExist(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
+	newlyCreated := false
+
+	taskManagerService := FetchTaskManagerServiceCreateObj(application, hash)
+	err := t.k8Cluster.CreateK8Object(ctx, taskManagerService)
+	if err != nil {
+		if !k8_err.IsAlreadyExists(err) {
+			logger.Errorf(ctx, "Taskmanager service creation failed %v", err)
+			t.metrics.serviceCreationFailure.Inc(ctx)
+			return false, err
+		}
+		logger.Infof(ctx, "Taskmanager service already exists")
+	} else {
+		newlyCreated = true
+		t.metrics.serviceCreationSuccess.Inc(ctx)
+	}
+
+	taskManagerStatefulSet := FetchTaskManagerStatefulSetCreateObj(application, hash)
+	err = t.k8Cluster.CreateK8Object(ctx, taskManagerStatefulSet)
+	if err != nil {
+		if !k8_err.IsAlreadyExists(err) {
+			logger.Errorf(ctx, "Taskmanager stateful set creation failed %v", err)
+			t.metrics.statefulSetCreationFailure.Inc(ctx)
+			return false, err
+		}
+		logger.Infof(ctx, "Taskmanager stateful set already exists")
 	} else {
-		t.metrics.deploymentCreationSuccess.Inc(ctx)
-		return true, nil
+		newlyCreated = true
+		t.metrics.statefulSetCreationSuccess.Inc(ctx)
 	}
 
-	return false, nil
+	return newlyCreated, nil
 }
 
 func getTaskManagerDeployment(deployments []v1.Deployment, application *v1alpha1.FlinkApplication) *v1.Deployment {
@@ -145,16 +220,19 @@ func FetchTaskManagerContainerObj(application *v1alpha1.FlinkApplication) *coreV
 
 	operatorEnv = append(operatorEnv, tmConfig.Environment.Env...)
 
+	podConfig := getRestSecurityPodConfig(application)
+	podConfig["taskmanager.host"] = TaskManagerHostnameEnvVar
+
 	return &coreV1.Container{
 		Name:            getFlinkContainerName(TaskManagerContainerName),
 		Image:           application.Spec.Image,
 		ImagePullPolicy: ImagePullPolicy(application),
 		Resources:       *resources,
-		Args:            []string{TaskManagerArg},
+		Args:            getContainerArgs(application, TaskManagerArg, podConfig),
 		Ports:           ports,
 		Env:             operatorEnv,
 		EnvFrom:         tmConfig.Environment.EnvFrom,
-		VolumeMounts:    application.Spec.VolumeMounts,
+		VolumeMounts:    getVolumeMounts(application),
 	}
 }
 
@@ -230,14 +308,15 @@ func taskmanagerTemplate(app *v1alpha1.FlinkApplication) *v1.Deployment {
 				ObjectMeta: metaV1.ObjectMeta{
 					Namespace:   app.Namespace,
 					Labels:      labels,
-					Annotations: app.Annotations,
+					Annotations: getPodAnnotations(app),
 				},
 				Spec: coreV1.PodSpec{
 					Containers: []coreV1.Container{
 						*taskContainer,
 					},
-					Volumes:          app.Spec.Volumes,
-					ImagePullSecrets: app.Spec.ImagePullSecrets,
+					Volumes:            getVolumes(app),
+					ImagePullSecrets:   app.Spec.ImagePullSecrets,
+					ServiceAccountName: getServiceAccountName(app),
 				},
 			},
 		},
@@ -14,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/apps/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -91,7 +92,16 @@ func TestTaskManagerCreateSuccess(t *testing.T) {
 		"flink-deployment-type": "taskmanager",
 	}
 	mockK8Cluster := testController.k8Cluster.(*k8mock.K8Cluster)
+	ctr := 0
 	mockK8Cluster.CreateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		ctr++
+		if pdb, ok := object.(*policyV1beta1.PodDisruptionBudget); ok {
+			assert.Equal(t, getTaskManagerName(&app, hash), pdb.Name)
+			assert.Equal(t, expectedLabels, pdb.Spec.Selector.MatchLabels)
+			assert.Equal(t, int32(1), pdb.Spec.MaxUnavailable.IntVal)
+			return nil
+		}
+
 		deployment := object.(*v1.Deployment)
 		assert.Equal(t, getTaskManagerName(&app, hash), deployment.Name)
 		assert.Equal(t, app.Namespace, deployment.Namespace)
@@ -116,6 +126,24 @@ func TestTaskManagerCreateSuccess(t *testing.T) {
 	newlyCreated, err := testController.CreateIfNotExist(context.Background(), &app)
 	assert.Nil(t, err)
 	assert.True(t, newlyCreated)
+	assert.Equal(t, 2, ctr)
+}
+
+func TestTaskManagerCreatePodDisruptionBudgetDisabled(t *testing.T) {
+	testController := getTMControllerForTest()
+	app := getFlinkTestApp()
+	app.Spec.TaskManagerConfig.PodDisruptionBudget = &v1alpha1.PodDisruptionBudgetConfig{
+		Disabled: true,
+	}
+	mockK8Cluster := testController.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.CreateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		_, ok := object.(*v1.Deployment)
+		assert.True(t, ok)
+		return nil
+	}
+	newlyCreated, err := testController.CreateIfNotExist(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, newlyCreated)
 }
 
 func TestTaskManagerCreateErr(t *testing.T) {
@@ -8,11 +8,14 @@ import (
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
 	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	"time"
 
+	"github.com/lyft/flinkk8soperator/pkg/controller/health"
 	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/lyft/flinkk8soperator/pkg/controller/tracing"
 	"github.com/lyft/flytestdlib/contextutils"
 	"github.com/lyft/flytestdlib/logger"
 	v1 "k8s.io/api/apps/v1"
@@ -36,6 +39,9 @@ type ReconcileFlinkApplication struct {
 	cache             cache.Cache
 	metrics           *reconcilerMetrics
 	flinkStateMachine FlinkHandlerInterface
+	namespaceFilter   *namespaceFilter
+	shardFilter       *shardFilter
+	health            *health.Monitor
 }
 
 type reconcilerMetrics struct {
@@ -71,10 +77,17 @@ func (r *ReconcileFlinkApplication) getFailureRetryInterval() time.Duration {
 	return config.GetConfig().ResyncPeriod.Duration / 2
 }
 
+// Errors caused by the application can only be fixed by the user updating it (which triggers a reconcile), so there is
+// no point retrying them faster than the resync period.
 func (r *ReconcileFlinkApplication) getReconcileResultForError(err error) reconcile.Result {
 	if err == nil {
 		return reconcile.Result{}
 	}
+	if flinkErrors.IsUserError(err) {
+		return reconcile.Result{
+			RequeueAfter: config.GetConfig().ResyncPeriod.Duration,
+		}
+	}
 	return reconcile.Result{
 		RequeueAfter: r.getFailureRetryInterval(),
 	}
@@ -84,31 +97,74 @@ func (r *ReconcileFlinkApplication) Reconcile(request reconcile.Request) (reconc
 	ctx := context.Background()
 	ctx = contextutils.WithNamespace(ctx, request.Namespace)
 	ctx = contextutils.WithAppName(ctx, request.Name)
+	ctx, span := tracing.StartSpan(ctx, "Reconcile")
+	defer span.End()
+
 	typeMeta := metaV1.TypeMeta{
 		Kind:       v1alpha1.FlinkApplicationKind,
 		APIVersion: v1alpha1.SchemeGroupVersion.String(),
 	}
+	watched, err := r.namespaceFilter.isWatched(ctx, request.Namespace)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return r.getReconcileResultForError(err), nil
+	}
+
 	// Fetch the FlinkApplication instance
 	instance := &v1alpha1.FlinkApplication{
 		TypeMeta: typeMeta,
 	}
 
-	err := r.getResource(ctx, request.NamespacedName, instance)
+	err = r.getResource(ctx, request.NamespacedName, instance)
 	if err != nil {
 		if k8.IsK8sObjectDoesNotExist(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Return and don't requeue
+			r.health.RemoveApp(request.NamespacedName)
+			r.shardFilter.remove(request.NamespacedName)
+			r.flinkStateMachine.RemoveApp(request.NamespacedName)
+			r.namespaceFilter.release(ctx, request.Namespace)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - we will check again in next loop
+		tracing.RecordError(span, err)
 		return r.getReconcileResultForError(err), nil
 	}
 	// We are seeing instances where getResource is removing TypeMeta
 	instance.TypeMeta = typeMeta
+
+	// Applications in namespaces that are not watched are left as they are, apart from their deletion, which would
+	// otherwise be blocked on the finalizer of the operator
+	if !watched && instance.DeletionTimestamp.IsZero() {
+		logger.Debugf(ctx, "Skipping application %v in a namespace that is not watched", request.NamespacedName)
+		return reconcile.Result{}, nil
+	}
+
+	// Applications of other shards are left to the operator instances handling them
+	owned, retryAfter, err := r.shardFilter.claim(ctx, instance)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return r.getReconcileResultForError(err), nil
+	}
+	if !owned {
+		return reconcile.Result{RequeueAfter: retryAfter}, nil
+	}
+
 	ctx = contextutils.WithPhase(ctx, string(instance.Status.Phase))
+	span.SetAttributes(
+		tracing.PhaseKey.String(instance.Status.Phase.VerboseString()),
+		tracing.HashKey.String(instance.Status.DeployHash))
+
 	err = r.flinkStateMachine.Handle(ctx, instance)
 	if err != nil {
 		logger.Warnf(ctx, "Failed to reconcile resource %v: %v", request.NamespacedName, err)
+		tracing.RecordError(span, err)
+	}
+	r.health.RecordReconcile(request.NamespacedName, instance.Status.Phase.VerboseString(), err)
+	// Returning an error requeues the request with a rate-limited backoff, which only helps for errors that may resolve
+	// by retrying; the others are requeued after the interval from getReconcileResultForError
+	if err != nil && !flinkErrors.IsRetryable(err) {
+		return r.getReconcileResultForError(err), nil
 	}
 	return r.getReconcileResultForError(err), err
 }
@@ -116,15 +172,59 @@ func (r *ReconcileFlinkApplication) Reconcile(request reconcile.Request) (reconc
 // Add creates a new FlinkApplication Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(ctx context.Context, mgr manager.Manager, cfg config.RuntimeConfig) error {
-	k8sCluster := k8.NewK8Cluster(mgr)
+	var err error
+	var k8sCluster k8.ClusterInterface
+	var namespacedCache *k8.NamespacedCache
+	var namespaceFilter *namespaceFilter
+	objectCache := mgr.GetCache()
+	objectClient := mgr.GetClient()
+	if config.GetConfig().WatchesMultipleNamespaces() {
+		// The objects are cached per watched namespace, rather than for the whole cluster as the manager would
+		namespacedCache, err = k8.NewNamespacedCache(mgr.GetConfig(), cache.Options{
+			Scheme: mgr.GetScheme(),
+			Mapper: mgr.GetRESTMapper(),
+			Resync: &config.GetConfig().ResyncPeriod.Duration,
+		})
+		if err != nil {
+			return err
+		}
+		if err := mgr.Add(namespacedCache); err != nil {
+			return err
+		}
+		cfg.Health.AddCache(namespacedCache)
+		namespaceFilter, err = newNamespaceFilter(config.GetConfig(), namespacedCache)
+		if err != nil {
+			return err
+		}
+		objectCache = namespacedCache
+		// reads that miss the cache go to the API server
+		objectClient, err = client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			return err
+		}
+		k8sCluster, err = k8.NewK8ClusterWithCache(mgr, namespacedCache)
+	} else {
+		k8sCluster, err = k8.NewK8Cluster(mgr)
+	}
+	if err != nil {
+		return err
+	}
 	flinkStateMachine := NewFlinkStateMachine(k8sCluster, cfg)
 
+	shardFilter, err := newShardFilter(config.GetConfig(), k8sCluster, cfg.MetricsScope)
+	if err != nil {
+		return err
+	}
+
 	metrics := newReconcilerMetrics(cfg.MetricsScope)
 	reconciler := ReconcileFlinkApplication{
-		client:            mgr.GetClient(),
-		cache:             mgr.GetCache(),
+		client:            objectClient,
+		cache:             objectCache,
 		metrics:           metrics,
 		flinkStateMachine: flinkStateMachine,
+		namespaceFilter:   namespaceFilter,
+		shardFilter:       shardFilter,
+		health:            cfg.Health,
 	}
 
 	c, err := controller.New("flinkAppController", mgr, controller.Options{
@@ -136,18 +236,46 @@ func Add(ctx context.Context, mgr manager.Manager, cfg config.RuntimeConfig) err
 		return err
 	}
 
-	if err = c.Watch(&source.Kind{Type: &v1alpha1.FlinkApplication{}}, &handler.EnqueueRequestForObject{}); err != nil {
+	newSource := func(object runtime.Object) source.Source {
+		return &source.Kind{Type: object}
+	}
+	if namespacedCache != nil {
+		newSource = func(object runtime.Object) source.Source {
+			return &k8.NamespacedKind{Cache: namespacedCache, Type: object}
+		}
+	}
+
+	// With sharding, only the applications of this shard are watched
+	var applicationPredicates []predicate.Predicate
+	if shardFilter != nil {
+		applicationPredicates = append(applicationPredicates, shardFilter.getPredicateFuncs())
+	}
+	if err = c.Watch(newSource(&v1alpha1.FlinkApplication{}), &handler.EnqueueRequestForObject{},
+		applicationPredicates...); err != nil {
+		return err
+	}
+
+	// Watch deployments, stateful sets and services for the application
+	if err := c.Watch(newSource(&v1.Deployment{}), &handler.Funcs{}, getPredicateFuncs()); err != nil {
 		return err
 	}
 
-	// Watch deployments and services for the application
-	if err := c.Watch(&source.Kind{Type: &v1.Deployment{}}, &handler.Funcs{}, getPredicateFuncs()); err != nil {
+	if err := c.Watch(newSource(&v1.StatefulSet{}), &handler.Funcs{}, getPredicateFuncs()); err != nil {
 		return err
 	}
 
-	if err := c.Watch(&source.Kind{Type: &coreV1.Service{}}, &handler.Funcs{}, getPredicateFuncs()); err != nil {
+	if err := c.Watch(newSource(&coreV1.Service{}), &handler.Funcs{}, getPredicateFuncs()); err != nil {
 		return err
 	}
+
+	// Watch namespaces for label changes that add them to, or remove them from, the selected namespaces
+	if namespaceFilter != nil && namespaceFilter.selector != nil {
+		informer, err := namespacedCache.GetInformer(&coreV1.Namespace{})
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(namespaceFilter.getNamespaceEventHandler())
+	}
 	return nil
 }
 
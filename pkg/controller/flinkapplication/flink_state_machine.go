@@ -2,6 +2,8 @@ package flinkapplication
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -9,26 +11,35 @@ import (
 	"fmt"
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
 	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
 	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/lyft/flinkk8soperator/pkg/controller/tracing"
 	"github.com/lyft/flytestdlib/logger"
 	"github.com/lyft/flytestdlib/promutils"
 	"github.com/lyft/flytestdlib/promutils/labeled"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
 )
 
 const (
 	jobFinalizer = "job.finalizers.flink.k8s.io"
+
+	defaultOrphanedClusterGracePeriod = 10 * time.Minute
 )
 
 // The core state machine that manages Flink clusters and jobs. See docs/state_machine.md for a description of the
 // states and transitions.
 type FlinkHandlerInterface interface {
 	Handle(ctx context.Context, application *v1alpha1.FlinkApplication) error
+
+	// Releases the state held for an application that has been deleted
+	RemoveApp(application types.NamespacedName)
 }
 
 type FlinkStateMachine struct {
@@ -36,13 +47,19 @@ type FlinkStateMachine struct {
 	k8Cluster       k8.ClusterInterface
 	clock           clock.Clock
 	metrics         *stateMachineMetrics
+
+	// the time at which the orphaned clusters of each application were last looked for
+	lastGarbageCollectionLock sync.Mutex
+	lastGarbageCollection     map[types.NamespacedName]time.Time
 }
 
 type stateMachineMetrics struct {
-	scope                             promutils.Scope
-	stateMachineHandlePhaseMap        map[v1alpha1.FlinkApplicationPhase]labeled.StopWatch
-	stateMachineHandleSuccessPhaseMap map[v1alpha1.FlinkApplicationPhase]labeled.StopWatch
-	errorCounterPhaseMap              map[v1alpha1.FlinkApplicationPhase]labeled.Counter
+	scope                              promutils.Scope
+	stateMachineHandlePhaseMap         map[v1alpha1.FlinkApplicationPhase]labeled.StopWatch
+	stateMachineHandleSuccessPhaseMap  map[v1alpha1.FlinkApplicationPhase]labeled.StopWatch
+	errorCounterPhaseMap               map[v1alpha1.FlinkApplicationPhase]labeled.Counter
+	orphanedClusterDeletedCounter      labeled.Counter
+	orphanedClusterDeleteFailedCounter labeled.Counter
 }
 
 func newStateMachineMetrics(scope promutils.Scope) *stateMachineMetrics {
@@ -65,7 +82,38 @@ func newStateMachineMetrics(scope promutils.Scope) *stateMachineMetrics {
 		stateMachineHandlePhaseMap:        stateMachineHandlePhaseMap,
 		stateMachineHandleSuccessPhaseMap: stateMachineHandleSuccessPhaseMap,
 		errorCounterPhaseMap:              errorCounterPhaseMap,
+		orphanedClusterDeletedCounter: labeled.NewCounter("orphaned_cluster_deleted",
+			"Orphaned clusters deleted", stateMachineScope),
+		orphanedClusterDeleteFailedCounter: labeled.NewCounter("orphaned_cluster_delete_failed",
+			"Failures to delete orphaned clusters", stateMachineScope),
+	}
+}
+
+type applicationSnapshotKey struct{}
+
+// The application as it was last read or written by the operator
+type applicationSnapshot struct {
+	application *v1alpha1.FlinkApplication
+}
+
+func withApplicationSnapshot(ctx context.Context, application *v1alpha1.FlinkApplication) context.Context {
+	return context.WithValue(ctx, applicationSnapshotKey{}, &applicationSnapshot{application: application.DeepCopy()})
+}
+
+// Writes the changes made to the application since it was last read or written as a patch, so that they do not
+// conflict with changes the user makes to the spec in the meantime. Applications that are not being handled by the
+// state machine, and so have no snapshot, are updated instead.
+func (s *FlinkStateMachine) updateApplication(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+	snapshot, ok := ctx.Value(applicationSnapshotKey{}).(*applicationSnapshot)
+	if !ok {
+		return s.k8Cluster.UpdateK8Object(ctx, application)
 	}
+
+	if err := s.k8Cluster.PatchK8Object(ctx, snapshot.application, application); err != nil {
+		return err
+	}
+	snapshot.application = application.DeepCopy()
+	return nil
 }
 
 func (s *FlinkStateMachine) updateApplicationPhase(ctx context.Context, application *v1alpha1.FlinkApplication, phase v1alpha1.FlinkApplicationPhase) error {
@@ -73,7 +121,7 @@ func (s *FlinkStateMachine) updateApplicationPhase(ctx context.Context, applicat
 	now := v1.NewTime(s.clock.Now())
 	application.Status.LastUpdatedAt = &now
 
-	return s.k8Cluster.UpdateK8Object(ctx, application)
+	return s.updateApplication(ctx, application)
 }
 
 func (s *FlinkStateMachine) shouldRollback(ctx context.Context, application *v1alpha1.FlinkApplication) bool {
@@ -96,21 +144,54 @@ func (s *FlinkStateMachine) shouldRollback(ctx context.Context, application *v1a
 	return false
 }
 
+func (s *FlinkStateMachine) RemoveApp(application types.NamespacedName) {
+	s.flinkController.RemoveApp(application)
+
+	s.lastGarbageCollectionLock.Lock()
+	defer s.lastGarbageCollectionLock.Unlock()
+	delete(s.lastGarbageCollection, application)
+}
+
 func (s *FlinkStateMachine) Handle(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+	ctx = flink.WithReconcileCache(ctx)
 	currentPhase := application.Status.Phase
 	timer := s.metrics.stateMachineHandlePhaseMap[currentPhase].Start(ctx)
 	successTimer := s.metrics.stateMachineHandleSuccessPhaseMap[currentPhase].Start(ctx)
 
+	ctx, span := tracing.StartSpan(ctx, "StateMachine."+currentPhase.VerboseString(),
+		tracing.PhaseKey.String(currentPhase.VerboseString()),
+		tracing.HashKey.String(application.Status.DeployHash))
+	defer span.End()
+
 	defer timer.Stop()
+	ctx = withApplicationSnapshot(ctx, application)
 	err := s.handle(ctx, application)
 	if err != nil {
 		s.metrics.errorCounterPhaseMap[currentPhase].Inc(ctx)
+		tracing.RecordError(span, err)
+		s.reportError(ctx, application, err)
 	} else {
 		successTimer.Stop()
 	}
 	return err
 }
 
+// Errors that will not resolve by retrying are surfaced to the user as an event and in the status reason, so that they
+// do not have to go through the operator logs to find out why the application is stuck. Retryable errors are only
+// logged, as they are expected to go away by themselves.
+func (s *FlinkStateMachine) reportError(ctx context.Context, application *v1alpha1.FlinkApplication, err error) {
+	if flinkErrors.IsRetryable(err) || application.Status.Reason == err.Error() {
+		return
+	}
+
+	s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeWarning,
+		fmt.Sprintf("%s error: %v", flinkErrors.GetErrorSource(err), err))
+	application.Status.Reason = err.Error()
+	if updateErr := s.updateApplication(ctx, application); updateErr != nil {
+		logger.Warnf(ctx, "Failed to update status reason: %v", updateErr)
+	}
+}
+
 func (s *FlinkStateMachine) handle(ctx context.Context, application *v1alpha1.FlinkApplication) error {
 	if !application.ObjectMeta.DeletionTimestamp.IsZero() && application.Status.Phase != v1alpha1.FlinkApplicationDeleting {
 		// Always perform a single application update per callback
@@ -133,6 +214,8 @@ func (s *FlinkStateMachine) handle(ctx context.Context, application *v1alpha1.Fl
 		return s.handleApplicationRunning(ctx, application)
 	case v1alpha1.FlinkApplicationSavepointing:
 		return s.handleApplicationSavepointing(ctx, application)
+	case v1alpha1.FlinkApplicationRescaling:
+		return s.handleRescaling(ctx, application)
 	case v1alpha1.FlinkApplicationRollingBackJob:
 		return s.handleRollingBack(ctx, application)
 	case v1alpha1.FlinkApplicationDeleting:
@@ -144,6 +227,30 @@ func (s *FlinkStateMachine) handle(ctx context.Context, application *v1alpha1.Fl
 // In this state we create a new cluster, either due to an entirely new FlinkApplication or due to an update.
 func (s *FlinkStateMachine) handleNewOrUpdating(ctx context.Context, application *v1alpha1.FlinkApplication) error {
 	// TODO: add up-front validation on the FlinkApplication resource
+	if err := flink.ValidateFlinkVersion(application); err != nil {
+		return s.rejectApplication(ctx, application, err.Error())
+	}
+	// the stop mode is applied to the job that is running when the application is next updated or deleted
+	if err := flink.ValidateStopMode(application, application.Status.DeployHash); err != nil {
+		return s.rejectApplication(ctx, application, err.Error())
+	}
+
+	if err := flink.ValidateMemoryModel(application); err != nil {
+		return s.rejectApplication(ctx, application, err.Error())
+	}
+	if err := flink.ValidateConfigMode(application); err != nil {
+		return s.rejectApplication(ctx, application, err.Error())
+	}
+	if err := flink.ValidateHighAvailability(application); err != nil {
+		return s.rejectApplication(ctx, application, err.Error())
+	}
+	if err := flink.ValidateIngress(application); err != nil {
+		return s.rejectApplication(ctx, application, err.Error())
+	}
+	if err := flink.ValidateJobManagerService(application); err != nil {
+		return s.rejectApplication(ctx, application, err.Error())
+	}
+
 	if s.shouldRollback(ctx, application) {
 		// we've failed to make progress; move to deploy failed
 		return s.deployFailed(ctx, application)
@@ -156,9 +263,35 @@ func (s *FlinkStateMachine) handleNewOrUpdating(ctx context.Context, application
 		return err
 	}
 
+	application.Status.UIURL = flink.GetFlinkUIURL(application)
 	return s.updateApplicationPhase(ctx, application, v1alpha1.FlinkApplicationClusterStarting)
 }
 
+// Leaves the application in its current phase with the reason it cannot be deployed. Any running cluster is left
+// untouched; the application will be reconciled again once the spec is fixed.
+func (s *FlinkStateMachine) rejectApplication(ctx context.Context, application *v1alpha1.FlinkApplication, reason string) error {
+	if application.Status.Reason == reason {
+		return nil
+	}
+
+	s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeWarning,
+		fmt.Sprintf("Invalid application: %s", reason))
+	application.Status.Reason = reason
+	return s.updateApplication(ctx, application)
+}
+
+// Describes how the job is being stopped, for events
+func stopVerb(app *v1alpha1.FlinkApplication) string {
+	switch flink.GetStopMode(app) {
+	case v1alpha1.StopModeStop:
+		return "Stopping"
+	case v1alpha1.StopModeDrain:
+		return "Draining"
+	default:
+		return "Cancelling"
+	}
+}
+
 func (s *FlinkStateMachine) deployFailed(ctx context.Context, app *v1alpha1.FlinkApplication) error {
 	s.flinkController.LogEvent(ctx, app, "", corev1.EventTypeWarning, "Deployment failed, rolled back successfully")
 	app.Status.FailedDeployHash = flink.HashForApplication(app)
@@ -180,14 +313,59 @@ func (s *FlinkStateMachine) handleClusterStarting(ctx context.Context, applicati
 		return err
 	}
 	if !ready {
-		return nil
+		failures, err := s.flinkController.GetPodFailures(ctx, application)
+		if err != nil {
+			// not being able to inspect the pods shouldn't prevent the cluster from starting
+			logger.Warnf(ctx, "Failed to check for pod failures: %v", err)
+			return nil
+		}
+		return s.handlePodFailures(ctx, application, failures)
 	}
 
 	logger.Infof(ctx, "Flink cluster has started successfully")
+	application.Status.Reason = ""
 	// TODO: in single mode move to submitting job
 	return s.updateApplicationPhase(ctx, application, v1alpha1.FlinkApplicationSavepointing)
 }
 
+// Surfaces pod failures in the new cluster as events and in the status reason. If any of the failures is unrecoverable
+// we fail the deploy immediately rather than waiting for the staleness duration to elapse.
+func (s *FlinkStateMachine) handlePodFailures(ctx context.Context, application *v1alpha1.FlinkApplication,
+	failures []common.PodFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(failures))
+	unrecoverable := false
+	for _, failure := range failures {
+		reasons = append(reasons, fmt.Sprintf("%s: %s: %s", failure.PodName, failure.Reason, failure.Message))
+		unrecoverable = unrecoverable || failure.Unrecoverable
+	}
+	reason := strings.Join(reasons, "; ")
+
+	// Only log events when the set of failures changes, so that we do not emit an event on every reconcile
+	changed := reason != application.Status.Reason
+	if changed {
+		for _, failure := range failures {
+			s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeWarning,
+				fmt.Sprintf("Pod %s failed to start: %s: %s", failure.PodName, failure.Reason, failure.Message))
+		}
+		application.Status.Reason = reason
+	}
+
+	// As in shouldRollback, on the first deploy there is nothing to roll back to, so we keep waiting for the user to
+	// fix the application
+	if unrecoverable && application.Status.DeployHash != "" {
+		return s.deployFailed(ctx, application)
+	}
+
+	if changed {
+		return s.updateApplication(ctx, application)
+	}
+	return nil
+}
+
 func (s *FlinkStateMachine) handleApplicationSavepointing(ctx context.Context, application *v1alpha1.FlinkApplication) error {
 	// we've already savepointed (or this is our first deploy), continue on
 	if application.Spec.SavepointInfo.SavepointLocation != "" || application.Status.DeployHash == "" {
@@ -210,10 +388,10 @@ func (s *FlinkStateMachine) handleApplicationSavepointing(ctx context.Context, a
 			return err
 		}
 
-		s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeNormal, fmt.Sprintf("Cancelling job %s with a final savepoint", application.Status.JobStatus.JobID))
+		s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeNormal, fmt.Sprintf("%s job %s with a final savepoint", stopVerb(application), application.Status.JobStatus.JobID))
 
 		application.Spec.SavepointInfo.TriggerID = triggerID
-		return s.k8Cluster.UpdateK8Object(ctx, application)
+		return s.updateApplication(ctx, application)
 	}
 
 	// check the savepoints in progress
@@ -247,6 +425,7 @@ func (s *FlinkStateMachine) handleApplicationSavepointing(ctx context.Context, a
 		s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeNormal, fmt.Sprintf("Canceled job with savepoint %s",
 			savepointStatusResponse.Operation.Location))
 		restorePath = savepointStatusResponse.Operation.Location
+		recordSavepoint(application, restorePath)
 	}
 
 	if restorePath != "" {
@@ -257,6 +436,85 @@ func (s *FlinkStateMachine) handleApplicationSavepointing(ctx context.Context, a
 	return nil
 }
 
+// Parallelism-only changes can be applied by rescaling the running job in place, provided the Flink version supports
+// it and the cluster has enough free slots for the additional parallelism
+func (s *FlinkStateMachine) canRescale(application *v1alpha1.FlinkApplication) bool {
+	if application.Status.JobStatus.State != v1alpha1.Running || !flink.IsParallelismOnlyChange(application) {
+		return false
+	}
+
+	// we've already failed to deploy this version of the application
+	if flink.HashForApplication(application) == application.Status.FailedDeployHash {
+		return false
+	}
+
+	if !flink.SupportsRescaling(application) {
+		return false
+	}
+
+	additionalSlots := application.Spec.Parallelism - application.Status.JobStatus.Parallelism
+	return additionalSlots <= application.Status.ClusterStatus.AvailableTaskSlots
+}
+
+// In this state we rescale the running job in place to the new parallelism. If rescaling fails, we fall back to a
+// regular update, which savepoints the job and resubmits it with the new parallelism.
+func (s *FlinkStateMachine) handleRescaling(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+	if s.shouldRollback(ctx, application) {
+		return s.rescaleFailed(ctx, application)
+	}
+
+	if application.Status.RescaleTriggerID == "" {
+		triggerID, err := s.flinkController.RescaleJob(ctx, application, application.Status.DeployHash, application.Spec.Parallelism)
+		if err != nil {
+			s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeWarning, fmt.Sprintf("Failed to rescale job: %v", err))
+			return s.rescaleFailed(ctx, application)
+		}
+
+		s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeNormal, fmt.Sprintf("Rescaling job %s from parallelism %d to %d",
+			application.Status.JobStatus.JobID, application.Status.JobStatus.Parallelism, application.Spec.Parallelism))
+
+		application.Status.RescaleTriggerID = triggerID
+		return s.updateApplication(ctx, application)
+	}
+
+	rescaleStatusResponse, err := s.flinkController.GetRescaleStatus(ctx, application, application.Status.DeployHash,
+		application.Status.RescaleTriggerID)
+	if err != nil {
+		return err
+	}
+	if rescaleStatusResponse.SavepointStatus.Status != client.SavePointCompleted {
+		return nil
+	}
+
+	if rescaleStatusResponse.Operation.FailureCause != (client.FailureCause{}) {
+		s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeWarning, fmt.Sprintf("Failed to rescale job: %v",
+			rescaleStatusResponse.Operation.FailureCause))
+		return s.rescaleFailed(ctx, application)
+	}
+
+	s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeNormal, fmt.Sprintf("Rescaled job %s to parallelism %d",
+		application.Status.JobStatus.JobID, application.Spec.Parallelism))
+
+	// the cluster keeps its hash, so we record the hash the application now has in order to recognize the cluster as
+	// current (see GetCurrentAndOldDeploymentsForApp)
+	hash := flink.HashForApplication(application)
+	if hash == application.Status.DeployHash {
+		application.Status.RescaledHash = ""
+	} else {
+		application.Status.RescaledHash = hash
+	}
+	application.Status.RescaleTriggerID = ""
+	application.Status.JobStatus.Parallelism = application.Spec.Parallelism
+	return s.updateApplicationPhase(ctx, application, v1alpha1.FlinkApplicationRunning)
+}
+
+func (s *FlinkStateMachine) rescaleFailed(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+	s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeWarning,
+		"Unable to rescale job in place, updating the application with a savepoint instead")
+	application.Status.RescaleTriggerID = ""
+	return s.updateApplicationPhase(ctx, application, v1alpha1.FlinkApplicationUpdating)
+}
+
 func (s *FlinkStateMachine) submitJobIfNeeded(ctx context.Context, app *v1alpha1.FlinkApplication, hash string,
 	jarName string, parallelism int32, entryClass string, programArgs string) (*client.FlinkJob, error) {
 	isReady, _ := s.flinkController.IsServiceReady(ctx, app, hash)
@@ -311,10 +569,13 @@ func (s *FlinkStateMachine) updateGenericService(ctx context.Context, app *v1alp
 		return errors.New("service does not exist")
 	}
 
-	if service.Spec.Selector[flink.FlinkAppHash] != newHash {
+	// the exposure of the service is also brought up to date, as it is only set when the service is first created
+	original := service.DeepCopy()
+	exposureChanged := flink.UpdateJobManagerServiceExposure(service, app)
+	if service.Spec.Selector[flink.FlinkAppHash] != newHash || exposureChanged {
 		// the service hasn't yet been updated
 		service.Spec.Selector[flink.FlinkAppHash] = newHash
-		err = s.k8Cluster.UpdateK8Object(ctx, service)
+		err = s.k8Cluster.PatchK8Object(ctx, original, service)
 		if err != nil {
 			return err
 		}
@@ -339,6 +600,12 @@ func (s *FlinkStateMachine) handleSubmittingJob(ctx context.Context, app *v1alph
 	activeJob, err := s.submitJobIfNeeded(ctx, app, hash,
 		app.Spec.JarName, app.Spec.Parallelism, app.Spec.EntryClass, app.Spec.ProgramArgs)
 	if err != nil {
+		// Submitting the job again will fail in the same way, so if there is a previous job to go back to we roll
+		// back immediately rather than waiting for the staleness duration to elapse
+		if flinkErrors.IsUserError(err) && app.Status.DeployHash != "" {
+			app.Status.Reason = err.Error()
+			return s.updateApplicationPhase(ctx, app, v1alpha1.FlinkApplicationRollingBackJob)
+		}
 		return err
 	}
 
@@ -347,6 +614,8 @@ func (s *FlinkStateMachine) handleSubmittingJob(ctx context.Context, app *v1alph
 		app.Spec.SavepointInfo = v1alpha1.SavepointInfo{}
 		// Update the application status with the running job info
 		app.Status.DeployHash = hash
+		app.Status.DeployFlinkVersion = app.Spec.FlinkVersion
+		app.Status.RescaledHash = ""
 		app.Status.JobStatus.JarName = app.Spec.JarName
 		app.Status.JobStatus.Parallelism = app.Spec.Parallelism
 		app.Status.JobStatus.EntryClass = app.Spec.EntryClass
@@ -424,6 +693,12 @@ func (s *FlinkStateMachine) handleApplicationRunning(ctx context.Context, applic
 
 	logger.Debugf(ctx, "Application running with job %v", activeJob)
 
+	// If only the parallelism has changed, try to rescale the job in place rather than deploying a new cluster
+	if s.canRescale(application) {
+		logger.Infof(ctx, "Application parallelism has changed. Moving to Rescaling")
+		return s.updateApplicationPhase(ctx, application, v1alpha1.FlinkApplicationRescaling)
+	}
+
 	cur, old, err := s.flinkController.GetCurrentAndOldDeploymentsForApp(ctx, application)
 	if err != nil {
 		return err
@@ -446,6 +721,14 @@ func (s *FlinkStateMachine) handleApplicationRunning(ctx context.Context, applic
 		}
 	}
 
+	// Clean up what is left of clusters from failed or interrupted deploys
+	s.deleteOrphanedClusters(ctx, application, old)
+
+	// The service config is not part of the hash, so changes to it do not trigger a deploy and are applied here
+	if err := s.updateGenericService(ctx, application, application.Status.DeployHash); err != nil {
+		logger.Errorf(ctx, "Updating the jobmanager service failed with %v", err)
+	}
+
 	// Update status of the cluster
 	hasClusterStatusChanged, clusterErr := s.flinkController.CompareAndUpdateClusterStatus(ctx, application, application.Status.DeployHash)
 	if clusterErr != nil {
@@ -458,14 +741,82 @@ func (s *FlinkStateMachine) handleApplicationRunning(ctx context.Context, applic
 		logger.Errorf(ctx, "Updating jobs status failed with %v", jobsErr)
 	}
 
-	// Update k8s object if either job or cluster status has changed
-	if hasJobStatusChanged || hasClusterStatusChanged {
-		return s.k8Cluster.UpdateK8Object(ctx, application)
+	// Publish the metrics selected in the spec
+	haveMetricsChanged, metricsErr := s.flinkController.UpdateMetricsStatus(ctx, application, application.Status.DeployHash)
+	if metricsErr != nil {
+		logger.Errorf(ctx, "Updating metrics status failed with %v", metricsErr)
+	}
+
+	// Dispose savepoints from previous deploys that are no longer needed
+	haveSavepointsChanged := s.enforceSavepointRetention(ctx, application)
+
+	// Update k8s object if the job, cluster, metrics or savepoint status has changed
+	if hasJobStatusChanged || hasClusterStatusChanged || haveMetricsChanged || haveSavepointsChanged {
+		return s.updateApplication(ctx, application)
 	}
 
 	return nil
 }
 
+// Deletes the clusters of the application that are neither deployed nor being deployed, once the grace period has
+// passed since they were last touched. Unlike old clusters, these may be missing some of their objects. As nothing
+// can become orphaned faster than that, an application is checked at most once per grace period. Failures are only
+// logged, as they do not affect the running job and are retried on the next check.
+func (s *FlinkStateMachine) deleteOrphanedClusters(ctx context.Context, application *v1alpha1.FlinkApplication,
+	old []common.FlinkDeployment) {
+	now := s.clock.Now()
+	gracePeriod := s.getOrphanedClusterGracePeriod()
+	if !s.isGarbageCollectionDue(types.NamespacedName{Namespace: application.Namespace, Name: application.Name}, now, gracePeriod) {
+		return
+	}
+
+	orphaned, err := s.flinkController.GetOrphanedClusters(ctx, application, now.Add(-gracePeriod))
+	if err != nil {
+		logger.Warnf(ctx, "Failed to find orphaned clusters: %v", err)
+		return
+	}
+
+	deleted := map[string]bool{}
+	for _, fd := range old {
+		deleted[fd.Hash] = true
+	}
+	for _, hash := range orphaned {
+		if deleted[hash] {
+			continue
+		}
+		s.flinkController.LogEvent(ctx, application, "", corev1.EventTypeNormal, fmt.Sprintf("Deleting orphaned cluster with hash %s", hash))
+		if err := s.flinkController.DeleteCluster(ctx, application, hash); err != nil {
+			logger.Warnf(ctx, "Failed to delete orphaned cluster with hash %s: %v", hash, err)
+			s.metrics.orphanedClusterDeleteFailedCounter.Inc(ctx)
+			continue
+		}
+		s.metrics.orphanedClusterDeletedCounter.Inc(ctx)
+	}
+}
+
+// Returns whether the grace period has passed since the orphaned clusters of the application were last looked for,
+// and if so records the given time as the time of the next check
+func (s *FlinkStateMachine) isGarbageCollectionDue(key types.NamespacedName, now time.Time, gracePeriod time.Duration) bool {
+	s.lastGarbageCollectionLock.Lock()
+	defer s.lastGarbageCollectionLock.Unlock()
+	if last, ok := s.lastGarbageCollection[key]; ok && now.Sub(last) < gracePeriod {
+		return false
+	}
+
+	if s.lastGarbageCollection == nil {
+		s.lastGarbageCollection = map[types.NamespacedName]time.Time{}
+	}
+	s.lastGarbageCollection[key] = now
+	return true
+}
+
+func (s *FlinkStateMachine) getOrphanedClusterGracePeriod() time.Duration {
+	if gracePeriod := config.GetConfig().OrphanedClusterGracePeriod.Duration; gracePeriod > 0 {
+		return gracePeriod
+	}
+	return defaultOrphanedClusterGracePeriod
+}
+
 func (s *FlinkStateMachine) getStalenessDuration() time.Duration {
 	return config.GetConfig().StatemachineStalenessDuration.Duration
 }
@@ -478,8 +829,38 @@ func (s *FlinkStateMachine) addFinalizerIfMissing(ctx context.Context, applicati
 	}
 
 	// finalizer not present; add
-	application.Finalizers = append(application.Finalizers, finalizer)
-	return s.k8Cluster.UpdateK8Object(ctx, application)
+	return s.updateFinalizers(ctx, application, func(finalizers []string) []string {
+		for _, f := range finalizers {
+			if f == finalizer {
+				return finalizers
+			}
+		}
+		return append(finalizers, finalizer)
+	})
+}
+
+// Finalizers are a list, which a merge patch would replace as a whole, so they are written to the latest version of
+// the application instead, retrying on conflicts. Once written, the result is reflected in the in-memory application.
+func (s *FlinkStateMachine) updateFinalizers(ctx context.Context, application *v1alpha1.FlinkApplication,
+	update func([]string) []string) error {
+	return k8.RetryOnConflict(ctx, func() error {
+		latest := application.DeepCopy()
+		if err := s.k8Cluster.GetK8Object(ctx, latest); err != nil {
+			return err
+		}
+		latest.Finalizers = update(latest.Finalizers)
+		if err := s.k8Cluster.UpdateK8Object(ctx, latest); err != nil {
+			return err
+		}
+
+		application.Finalizers = latest.Finalizers
+		application.ResourceVersion = latest.ResourceVersion
+		if snapshot, ok := ctx.Value(applicationSnapshotKey{}).(*applicationSnapshot); ok {
+			snapshot.application.Finalizers = latest.Finalizers
+			snapshot.application.ResourceVersion = latest.ResourceVersion
+		}
+		return nil
+	})
 }
 
 func removeString(list []string, target string) []string {
@@ -494,8 +875,9 @@ func removeString(list []string, target string) []string {
 }
 
 func (s *FlinkStateMachine) clearFinalizers(ctx context.Context, app *v1alpha1.FlinkApplication) error {
-	app.Finalizers = removeString(app.Finalizers, jobFinalizer)
-	return s.k8Cluster.UpdateK8Object(ctx, app)
+	return s.updateFinalizers(ctx, app, func(finalizers []string) []string {
+		return removeString(finalizers, jobFinalizer)
+	})
 }
 
 func jobFinished(jobs []client.FlinkJob, id string) bool {
@@ -546,12 +928,15 @@ func (s *FlinkStateMachine) handleApplicationDeleting(ctx context.Context, app *
 		}
 
 		if app.Spec.SavepointInfo.TriggerID == "" {
+			if err := flink.ValidateStopMode(app, app.Status.DeployHash); err != nil {
+				return s.rejectApplication(ctx, app, err.Error())
+			}
 			// delete with savepoint
 			triggerID, err := s.flinkController.CancelWithSavepoint(ctx, app, app.Status.DeployHash)
 			if err != nil {
 				return err
 			}
-			s.flinkController.LogEvent(ctx, app, "", corev1.EventTypeNormal, fmt.Sprintf("Cancelling job with savepoint %v", triggerID))
+			s.flinkController.LogEvent(ctx, app, "", corev1.EventTypeNormal, fmt.Sprintf("%s job with savepoint %v", stopVerb(app), triggerID))
 			app.Spec.SavepointInfo.TriggerID = triggerID
 		} else {
 			// we've already started savepointing; check the status
@@ -573,7 +958,7 @@ func (s *FlinkStateMachine) handleApplicationDeleting(ctx context.Context, app *
 			}
 		}
 
-		return s.k8Cluster.UpdateK8Object(ctx, app)
+		return s.updateApplication(ctx, app)
 	default:
 		logger.Errorf(ctx, "Unsupported DeleteMode %s", app.Spec.DeleteMode)
 	}
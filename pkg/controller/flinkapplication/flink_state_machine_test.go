@@ -2,20 +2,33 @@ package flinkapplication
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
 	controller_config "github.com/lyft/flinkk8soperator/pkg/controller/config"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client/fake"
 	"github.com/lyft/flytestdlib/config"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
 	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	flinkErrors "github.com/lyft/flinkk8soperator/pkg/controller/errors"
 	"github.com/lyft/flinkk8soperator/pkg/controller/flink/mock"
 	k8mock "github.com/lyft/flinkk8soperator/pkg/controller/k8/mock"
+	"github.com/lyft/flinkk8soperator/pkg/controller/tracing"
 	mockScope "github.com/lyft/flytestdlib/promutils"
 	"github.com/lyft/flytestdlib/promutils/labeled"
 	"github.com/stretchr/testify/assert"
@@ -25,11 +38,11 @@ import (
 
 const testSavepointLocation = "location"
 
-func getTestStateMachine() FlinkStateMachine {
+func getTestStateMachine() *FlinkStateMachine {
 	testScope := mockScope.NewTestScope()
 	labeled.SetMetricKeys(common.GetValidLabelNames()...)
 
-	return FlinkStateMachine{
+	return &FlinkStateMachine{
 		flinkController: &mock.FlinkController{},
 		k8Cluster:       &k8mock.K8Cluster{},
 		clock:           &clock.FakeClock{},
@@ -57,9 +70,127 @@ func TestHandleNewOrCreate(t *testing.T) {
 	}
 
 	err := stateMachineForTest.Handle(context.Background(), &v1alpha1.FlinkApplication{
-		Spec: v1alpha1.FlinkApplicationSpec{},
+		Spec: v1alpha1.FlinkApplicationSpec{
+			FlinkVersion: "1.8",
+		},
+	})
+	assert.Nil(t, err)
+}
+
+func TestHandleNewOrCreateUIURL(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+
+	updated := false
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, "https://flink.example.com/", application.Status.UIURL)
+		updated = true
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &v1alpha1.FlinkApplication{
+		Spec: v1alpha1.FlinkApplicationSpec{
+			FlinkVersion: "1.8",
+			Ingress: &v1alpha1.IngressConfig{
+				Host:          "flink.example.com",
+				TLSSecretName: "flink-tls",
+			},
+		},
 	})
 	assert.Nil(t, err)
+	assert.True(t, updated)
+}
+
+func TestHandleNewUnsupportedFlinkVersion(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.CreateClusterFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+		assert.False(t, true)
+		return nil
+	}
+
+	updateInvoked := false
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, v1alpha1.FlinkApplicationNew, application.Status.Phase)
+		assert.Equal(t, "ErrorCode: [BadJobSpecificationError] Reason: [unsupported flink version 1.6: must be a 1.x release no older than 1.7]", application.Status.Reason)
+		updateInvoked = true
+		return nil
+	}
+
+	app := v1alpha1.FlinkApplication{
+		Spec: v1alpha1.FlinkApplicationSpec{
+			FlinkVersion: "1.6",
+		},
+	}
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, updateInvoked)
+
+	// the status is only updated when the reason changes
+	updateInvoked = false
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.False(t, updateInvoked)
+}
+
+func TestHandleNewUnsupportedStopMode(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.CreateClusterFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+		assert.False(t, true)
+		return nil
+	}
+
+	updateInvoked := false
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, "ErrorCode: [BadJobSpecificationError] Reason: [stop mode Stop requires flink 1.9 or later, but the cluster runs 1.8]", application.Status.Reason)
+		updateInvoked = true
+		return nil
+	}
+
+	app := v1alpha1.FlinkApplication{
+		Spec: v1alpha1.FlinkApplicationSpec{
+			FlinkVersion: "1.8",
+			StopMode:     v1alpha1.StopModeStop,
+		},
+	}
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, updateInvoked)
+}
+
+func TestHandleRecordsPhaseSpan(t *testing.T) {
+	exporter, restore := tracing.InstallInMemoryExporter()
+	defer restore()
+	stateMachineForTest := getTestStateMachine()
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		return errors.New("update failed")
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &v1alpha1.FlinkApplication{
+		Spec: v1alpha1.FlinkApplicationSpec{
+			FlinkVersion: "1.8",
+		},
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:      v1alpha1.FlinkApplicationNew,
+			DeployHash: "abcd1234",
+		},
+	})
+	assert.NotNil(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, "StateMachine.New", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, tracing.PhaseKey.String("New"))
+	assert.Contains(t, spans[0].Attributes, tracing.HashKey.String("abcd1234"))
+	assert.Equal(t, tracing.StatusError, spans[0].Status.Code)
 }
 
 func TestHandleStartingClusterStarting(t *testing.T) {
@@ -289,10 +420,11 @@ func TestSubmittingToRunning(t *testing.T) {
 			Namespace: "flink",
 		},
 		Spec: v1alpha1.FlinkApplicationSpec{
-			JarName:     "job.jar",
-			Parallelism: 5,
-			EntryClass:  "com.my.Class",
-			ProgramArgs: "--test",
+			FlinkVersion: "1.9",
+			JarName:      "job.jar",
+			Parallelism:  5,
+			EntryClass:   "com.my.Class",
+			ProgramArgs:  "--test",
 		},
 		Status: v1alpha1.FlinkApplicationStatus{
 			Phase:      v1alpha1.FlinkApplicationSubmittingJob,
@@ -355,6 +487,7 @@ func TestSubmittingToRunning(t *testing.T) {
 				Namespace: "flink",
 			},
 			Spec: v1.ServiceSpec{
+				Ports: flink.FetchJobManagerServiceCreateObj(&app, hash).Spec.Ports,
 				Selector: map[string]string{
 					"flink-app-hash": hash,
 				},
@@ -375,6 +508,7 @@ func TestSubmittingToRunning(t *testing.T) {
 			application := object.(*v1alpha1.FlinkApplication)
 			assert.Equal(t, jobID, application.Status.JobStatus.JobID)
 			assert.Equal(t, appHash, application.Status.DeployHash)
+			assert.Equal(t, app.Spec.FlinkVersion, application.Status.DeployFlinkVersion)
 			assert.Equal(t, app.Spec.JarName, app.Status.JobStatus.JarName)
 			assert.Equal(t, app.Spec.Parallelism, app.Status.JobStatus.Parallelism)
 			assert.Equal(t, app.Spec.EntryClass, app.Status.JobStatus.EntryClass)
@@ -426,6 +560,7 @@ func TestHandleApplicationNotReady(t *testing.T) {
 				Namespace: "flink",
 			},
 			Spec: v1.ServiceSpec{
+				Ports: flink.FetchJobManagerServiceCreateObj(&app, flink.HashForApplication(&app)).Spec.Ports,
 				Selector: map[string]string{
 					"flink-app-hash": flink.HashForApplication(&app),
 				},
@@ -462,6 +597,144 @@ func TestHandleApplicationRunning(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestHandleApplicationRunningUpdatesServiceExposure(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.GetCurrentAndOldDeploymentsForAppFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error) {
+		fd := testFlinkDeployment(application)
+		return &fd, nil, nil
+	}
+
+	app := v1alpha1.FlinkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app",
+			Namespace: "flink",
+		},
+		Spec: v1alpha1.FlinkApplicationSpec{
+			JobManagerConfig: v1alpha1.JobManagerConfig{
+				Service: &v1alpha1.JobManagerServiceConfig{Type: v1.ServiceTypeLoadBalancer},
+			},
+		},
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:      v1alpha1.FlinkApplicationRunning,
+			DeployHash: "hash",
+		},
+	}
+
+	service := flink.FetchJobManagerServiceCreateObj(&app, "hash")
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetServiceFunc = func(ctx context.Context, namespace string, name string) (*v1.Service, error) {
+		return service.DeepCopy(), nil
+	}
+	var updated []v1.ServiceType
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		service = object.(*v1.Service)
+		updated = append(updated, service.Spec.Type)
+		return nil
+	}
+
+	// changes to the service config are applied without a deploy
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.Equal(t, []v1.ServiceType{v1.ServiceTypeLoadBalancer}, updated)
+
+	// and removing the config resets the service
+	app.Spec.JobManagerConfig.Service = nil
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.Equal(t, []v1.ServiceType{v1.ServiceTypeLoadBalancer, v1.ServiceTypeClusterIP}, updated)
+
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(updated))
+}
+
+func TestHandleApplicationRunningMetrics(t *testing.T) {
+	updateInvoked := false
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.GetCurrentAndOldDeploymentsForAppFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error) {
+		fd := testFlinkDeployment(application)
+		return &fd, nil, nil
+	}
+	mockFlinkController.UpdateMetricsStatusFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
+		application.Status.Metrics = []v1alpha1.FlinkMetricStatus{{Name: "uptime", Value: "1000"}}
+		return true, nil
+	}
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, v1alpha1.FlinkApplicationRunning, application.Status.Phase)
+		assert.Equal(t, "1000", application.Status.Metrics[0].Value)
+		updateInvoked = true
+		return nil
+	}
+	err := stateMachineForTest.Handle(context.Background(), &v1alpha1.FlinkApplication{
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase: v1alpha1.FlinkApplicationRunning,
+		},
+	})
+	assert.True(t, updateInvoked)
+	assert.Nil(t, err)
+}
+
+func TestHandleApplicationRunningDeletesOrphanedClusters(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+	now := time.Now()
+	stateMachineForTest.clock = clock.NewFakeClock(now)
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.GetCurrentAndOldDeploymentsForAppFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error) {
+		fd := testFlinkDeployment(application)
+		return &fd, []common.FlinkDeployment{{Hash: "old-hash"}}, nil
+	}
+	checks := 0
+	mockFlinkController.GetOrphanedClustersFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, createdBefore time.Time) ([]string, error) {
+		assert.Equal(t, stateMachineForTest.clock.Now().Add(-defaultOrphanedClusterGracePeriod), createdBefore)
+		checks++
+		// old clusters are also orphaned, but have already been deleted
+		return []string{"failed-hash", "old-hash", "partial-hash"}, nil
+	}
+
+	var deleted []string
+	mockFlinkController.DeleteClusterFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) error {
+		deleted = append(deleted, hash)
+		if hash == "failed-hash" {
+			return errors.New("delete failed")
+		}
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &v1alpha1.FlinkApplication{
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase: v1alpha1.FlinkApplicationDeployFailed,
+		},
+	})
+	// failing to delete an orphaned cluster does not fail the reconciliation
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"old-hash", "failed-hash", "partial-hash"}, deleted)
+	assert.Equal(t, 3, len(mockFlinkController.Events))
+	assert.Equal(t, "Deleting old cluster with hash old-hash", mockFlinkController.Events[0].Message)
+	assert.Equal(t, "Deleting orphaned cluster with hash failed-hash", mockFlinkController.Events[1].Message)
+	assert.Equal(t, "Deleting orphaned cluster with hash partial-hash", mockFlinkController.Events[2].Message)
+	assert.Equal(t, 1, checks)
+
+	// the application is not checked again until the grace period has passed
+	app := v1alpha1.FlinkApplication{
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase: v1alpha1.FlinkApplicationRunning,
+		},
+	}
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, checks)
+
+	stateMachineForTest.clock.(*clock.FakeClock).Step(defaultOrphanedClusterGracePeriod)
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, checks)
+}
+
 func TestRunningToClusterStarting(t *testing.T) {
 	updateInvoked := false
 	stateMachineForTest := getTestStateMachine()
@@ -486,87 +759,261 @@ func TestRunningToClusterStarting(t *testing.T) {
 	assert.Nil(t, err)
 }
 
-func TestRollingBack(t *testing.T) {
-	jobID := "j1"
-
-	app := v1alpha1.FlinkApplication{
+// Returns a running application whose parallelism has been changed from 4 to 6
+func getRescaleTestApp(flinkVersion string, availableSlots int32) *v1alpha1.FlinkApplication {
+	app := &v1alpha1.FlinkApplication{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-app",
 			Namespace: "flink",
 		},
 		Spec: v1alpha1.FlinkApplicationSpec{
-			JarName:     "job.jar",
-			Parallelism: 5,
-			EntryClass:  "com.my.Class",
-			ProgramArgs: "--test",
+			JarName:      "job.jar",
+			Parallelism:  4,
+			FlinkVersion: flinkVersion,
 		},
 		Status: v1alpha1.FlinkApplicationStatus{
-			Phase:      v1alpha1.FlinkApplicationRollingBackJob,
-			DeployHash: "old-hash",
+			Phase: v1alpha1.FlinkApplicationRunning,
+			ClusterStatus: v1alpha1.FlinkClusterStatus{
+				AvailableTaskSlots: availableSlots,
+			},
 			JobStatus: v1alpha1.FlinkJobStatus{
-				JarName:     "old-job.jar",
-				Parallelism: 10,
-				EntryClass:  "com.my.OldClass",
-				ProgramArgs: "--no-test",
+				JobID:       "j1",
+				State:       v1alpha1.Running,
+				JarName:     "job.jar",
+				Parallelism: 4,
 			},
 		},
 	}
-	appHash := flink.HashForApplication(&app)
+	app.Status.DeployHash = flink.HashForApplication(app)
+	app.Spec.Parallelism = 6
+	return app
+}
+
+func TestRunningToRescaling(t *testing.T) {
+	app := getRescaleTestApp("1.8", 2)
 
 	stateMachineForTest := getTestStateMachine()
 	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
-	mockFlinkController.IsServiceReadyFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
-		assert.Equal(t, "old-hash", hash)
-		return true, nil
+	mockFlinkController.GetCurrentAndOldDeploymentsForAppFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error) {
+		assert.False(t, true)
+		return nil, nil, nil
 	}
 
-	startCalled := false
-	mockFlinkController.StartFlinkJobFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string,
-		jarName string, parallelism int32, entryClass string, programArgs string) (string, error) {
-
-		startCalled = true
-		assert.Equal(t, "old-hash", hash)
-		assert.Equal(t, app.Status.JobStatus.JarName, jarName)
-		assert.Equal(t, app.Status.JobStatus.Parallelism, parallelism)
-		assert.Equal(t, app.Status.JobStatus.EntryClass, entryClass)
-		assert.Equal(t, app.Status.JobStatus.ProgramArgs, programArgs)
-		return jobID, nil
+	updateInvoked := false
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, v1alpha1.FlinkApplicationRescaling, application.Status.Phase)
+		updateInvoked = true
+		return nil
 	}
 
-	getCount := 0
-	mockFlinkController.GetJobsForApplicationFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) ([]client.FlinkJob, error) {
-		assert.Equal(t, "old-hash", hash)
-		var res []client.FlinkJob
-		if getCount == 1 {
-			res = []client.FlinkJob{
-				{
-					JobID:  jobID,
-					Status: client.Running,
-				}}
+	err := stateMachineForTest.Handle(context.Background(), app)
+	assert.Nil(t, err)
+	assert.True(t, updateInvoked)
+}
+
+func TestRunningCannotRescale(t *testing.T) {
+	for _, app := range []*v1alpha1.FlinkApplication{
+		// not enough free slots
+		getRescaleTestApp("1.8", 1),
+		// rescaling is not supported from 1.9
+		getRescaleTestApp("1.9", 2),
+	} {
+		stateMachineForTest := getTestStateMachine()
+		mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+		mockFlinkController.GetCurrentAndOldDeploymentsForAppFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (*common.FlinkDeployment, []common.FlinkDeployment, error) {
+			return nil, nil, nil
 		}
-		getCount++
-		return res, nil
-	}
 
-	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+		mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+		mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+			application := object.(*v1alpha1.FlinkApplication)
+			assert.Equal(t, v1alpha1.FlinkApplicationUpdating, application.Status.Phase)
+			return nil
+		}
 
-	getServiceCount := 0
-	mockK8Cluster.GetServiceFunc = func(ctx context.Context, namespace string, name string) (*v1.Service, error) {
-		assert.Equal(t, "flink", namespace)
-		assert.Equal(t, "test-app", name)
+		err := stateMachineForTest.Handle(context.Background(), app)
+		assert.Nil(t, err)
+		assert.Equal(t, v1alpha1.FlinkApplicationUpdating, app.Status.Phase)
+	}
+}
 
-		hash := appHash
-		if getServiceCount > 0 {
-			hash = "old-hash"
+func TestHandleRescaling(t *testing.T) {
+	app := getRescaleTestApp("1.8", 2)
+	app.Status.Phase = v1alpha1.FlinkApplicationRescaling
+	deployHash := app.Status.DeployHash
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.RescaleJobFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, parallelism int32) (string, error) {
+		assert.Equal(t, deployHash, hash)
+		assert.Equal(t, int32(6), parallelism)
+		return "trigger", nil
+	}
+	statusCalls := 0
+	mockFlinkController.GetRescaleStatusFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+		assert.Equal(t, deployHash, hash)
+		assert.Equal(t, "trigger", triggerID)
+		statusCalls++
+		status := client.SavePointInProgress
+		if statusCalls > 1 {
+			status = client.SavePointCompleted
 		}
+		return &client.SavepointResponse{
+			SavepointStatus: client.SavepointStatusResponse{
+				Status: status,
+			},
+		}, nil
+	}
 
-		getServiceCount++
+	updateCount := 0
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		updateCount++
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), app)
+	assert.Nil(t, err)
+	assert.Equal(t, "trigger", app.Status.RescaleTriggerID)
+	assert.Equal(t, 1, updateCount)
+
+	// still in progress
+	err = stateMachineForTest.Handle(context.Background(), app)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, updateCount)
+
+	err = stateMachineForTest.Handle(context.Background(), app)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, updateCount)
+	assert.Equal(t, v1alpha1.FlinkApplicationRunning, app.Status.Phase)
+	assert.Empty(t, app.Status.RescaleTriggerID)
+	assert.Equal(t, int32(6), app.Status.JobStatus.Parallelism)
+	assert.Equal(t, deployHash, app.Status.DeployHash)
+	assert.Equal(t, flink.HashForApplication(app), app.Status.RescaledHash)
+
+	// scaling back to the deployed parallelism is also done in place
+	app.Spec.Parallelism = 4
+	assert.True(t, stateMachineForTest.canRescale(app))
+}
+
+func TestHandleRescalingFailed(t *testing.T) {
+	app := getRescaleTestApp("1.8", 2)
+	app.Status.Phase = v1alpha1.FlinkApplicationRescaling
+	app.Status.RescaleTriggerID = "trigger"
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.GetRescaleStatusFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+		return &client.SavepointResponse{
+			SavepointStatus: client.SavepointStatusResponse{
+				Status: client.SavePointCompleted,
+			},
+			Operation: client.SavepointOperationResponse{
+				FailureCause: client.FailureCause{
+					Class: "org.apache.flink.runtime.rest.handler.RestHandlerException",
+				},
+			},
+		}, nil
+	}
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		return nil
+	}
+
+	// we fall back to savepointing and resubmitting the job
+	err := stateMachineForTest.Handle(context.Background(), app)
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha1.FlinkApplicationUpdating, app.Status.Phase)
+	assert.Empty(t, app.Status.RescaleTriggerID)
+	assert.Equal(t, int32(4), app.Status.JobStatus.Parallelism)
+	assert.Empty(t, app.Status.RescaledHash)
+}
+
+func TestRollingBack(t *testing.T) {
+	jobID := "j1"
+
+	app := v1alpha1.FlinkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app",
+			Namespace: "flink",
+		},
+		Spec: v1alpha1.FlinkApplicationSpec{
+			JarName:     "job.jar",
+			Parallelism: 5,
+			EntryClass:  "com.my.Class",
+			ProgramArgs: "--test",
+		},
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:      v1alpha1.FlinkApplicationRollingBackJob,
+			DeployHash: "old-hash",
+			JobStatus: v1alpha1.FlinkJobStatus{
+				JarName:     "old-job.jar",
+				Parallelism: 10,
+				EntryClass:  "com.my.OldClass",
+				ProgramArgs: "--no-test",
+			},
+		},
+	}
+	appHash := flink.HashForApplication(&app)
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.IsServiceReadyFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
+		assert.Equal(t, "old-hash", hash)
+		return true, nil
+	}
+
+	startCalled := false
+	mockFlinkController.StartFlinkJobFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string,
+		jarName string, parallelism int32, entryClass string, programArgs string) (string, error) {
+
+		startCalled = true
+		assert.Equal(t, "old-hash", hash)
+		assert.Equal(t, app.Status.JobStatus.JarName, jarName)
+		assert.Equal(t, app.Status.JobStatus.Parallelism, parallelism)
+		assert.Equal(t, app.Status.JobStatus.EntryClass, entryClass)
+		assert.Equal(t, app.Status.JobStatus.ProgramArgs, programArgs)
+		return jobID, nil
+	}
+
+	getCount := 0
+	mockFlinkController.GetJobsForApplicationFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) ([]client.FlinkJob, error) {
+		assert.Equal(t, "old-hash", hash)
+		var res []client.FlinkJob
+		if getCount == 1 {
+			res = []client.FlinkJob{
+				{
+					JobID:  jobID,
+					Status: client.Running,
+				}}
+		}
+		getCount++
+		return res, nil
+	}
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+
+	getServiceCount := 0
+	mockK8Cluster.GetServiceFunc = func(ctx context.Context, namespace string, name string) (*v1.Service, error) {
+		assert.Equal(t, "flink", namespace)
+		assert.Equal(t, "test-app", name)
+
+		hash := appHash
+		if getServiceCount > 0 {
+			hash = "old-hash"
+		}
+
+		getServiceCount++
 		return &v1.Service{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "test-app",
 				Namespace: "flink",
 			},
 			Spec: v1.ServiceSpec{
+				Ports: flink.FetchJobManagerServiceCreateObj(&app, hash).Spec.Ports,
 				Selector: map[string]string{
 					"flink-app-hash": hash,
 				},
@@ -680,7 +1127,7 @@ func TestDeleteWithSavepoint(t *testing.T) {
 		} else if updateCount == 2 {
 			assert.Equal(t, savepointPath, application.Spec.SavepointInfo.SavepointLocation)
 		} else if updateCount == 3 {
-			assert.Equal(t, 0, len(app.Finalizers))
+			assert.Equal(t, 0, len(application.Finalizers))
 		}
 
 		updateCount++
@@ -723,6 +1170,57 @@ func TestDeleteWithSavepoint(t *testing.T) {
 
 }
 
+func TestDeleteWithUnsupportedStopMode(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+
+	app := v1alpha1.FlinkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Finalizers:        []string{jobFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: v1alpha1.FlinkApplicationSpec{
+			FlinkVersion: "1.9",
+			StopMode:     v1alpha1.StopModeDrain,
+		},
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:              v1alpha1.FlinkApplicationDeleting,
+			DeployHash:         "old-hash",
+			DeployFlinkVersion: "1.8",
+			JobStatus: v1alpha1.FlinkJobStatus{
+				JobID: "j1",
+			},
+		},
+	}
+
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.GetJobsForApplicationFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) ([]client.FlinkJob, error) {
+		return []client.FlinkJob{
+			{
+				JobID:  "j1",
+				Status: client.Running,
+			},
+		}, nil
+	}
+	mockFlinkController.CancelWithSavepointFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (string, error) {
+		assert.Fail(t, "the job must not be stopped with an unsupported stop mode")
+		return "", nil
+	}
+
+	updateInvoked := false
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, "ErrorCode: [BadJobSpecificationError] Reason: [stop mode Drain requires flink 1.9 or later, but the cluster runs 1.8]", application.Status.Reason)
+		assert.Equal(t, []string{jobFinalizer}, application.Finalizers)
+		updateInvoked = true
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.NoError(t, err)
+	assert.True(t, updateInvoked)
+}
+
 func TestDeleteWithForceCancel(t *testing.T) {
 	stateMachineForTest := getTestStateMachine()
 
@@ -768,7 +1266,7 @@ func TestDeleteWithForceCancel(t *testing.T) {
 		assert.Equal(t, v1alpha1.FlinkApplicationDeleting, application.Status.Phase)
 
 		if updateCount == 1 {
-			assert.Equal(t, 0, len(app.Finalizers))
+			assert.Equal(t, 0, len(application.Finalizers))
 		}
 
 		updateCount++
@@ -836,7 +1334,7 @@ func TestDeleteModeNone(t *testing.T) {
 		assert.Equal(t, v1alpha1.FlinkApplicationDeleting, application.Status.Phase)
 
 		if updateCount == 1 {
-			assert.Equal(t, 0, len(app.Finalizers))
+			assert.Equal(t, 0, len(application.Finalizers))
 		}
 
 		updateCount++
@@ -848,3 +1346,400 @@ func TestDeleteModeNone(t *testing.T) {
 	assert.Equal(t, 2, updateCount)
 	assert.False(t, cancelled)
 }
+
+func TestClusterStartingUnrecoverablePodFailure(t *testing.T) {
+	updateInvoked := false
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.IsClusterReadyFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+		return false, nil
+	}
+	mockFlinkController.GetPodFailuresFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) ([]common.PodFailure, error) {
+		return []common.PodFailure{
+			{
+				PodName:       "jm-pod",
+				Reason:        "ImagePullBackOff",
+				Message:       "container jobmanager: Back-off pulling image",
+				Unrecoverable: true,
+			},
+		}, nil
+	}
+
+	app := v1alpha1.FlinkApplication{
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:      v1alpha1.FlinkApplicationClusterStarting,
+			DeployHash: "old-hash",
+		},
+	}
+	hash := flink.HashForApplication(&app)
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, v1alpha1.FlinkApplicationDeployFailed, application.Status.Phase)
+		assert.Equal(t, hash, application.Status.FailedDeployHash)
+		assert.Equal(t, "jm-pod: ImagePullBackOff: container jobmanager: Back-off pulling image", application.Status.Reason)
+		updateInvoked = true
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, updateInvoked)
+	assert.Equal(t, "Pod jm-pod failed to start: ImagePullBackOff: container jobmanager: Back-off pulling image",
+		mockFlinkController.Events[0].Message)
+}
+
+func TestClusterStartingRecoverablePodFailure(t *testing.T) {
+	updateCount := 0
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.IsClusterReadyFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, error) {
+		return false, nil
+	}
+	mockFlinkController.GetPodFailuresFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) ([]common.PodFailure, error) {
+		return []common.PodFailure{
+			{
+				PodName: "tm-pod",
+				Reason:  "Unschedulable",
+				Message: "0/3 nodes are available: 3 Insufficient cpu.",
+			},
+		}, nil
+	}
+
+	app := v1alpha1.FlinkApplication{
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:      v1alpha1.FlinkApplicationClusterStarting,
+			DeployHash: "old-hash",
+		},
+	}
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, v1alpha1.FlinkApplicationClusterStarting, application.Status.Phase)
+		assert.Equal(t, "tm-pod: Unschedulable: 0/3 nodes are available: 3 Insufficient cpu.", application.Status.Reason)
+		updateCount++
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+
+	// the same failure should not be reported again
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, updateCount)
+	assert.Equal(t, 1, len(mockFlinkController.Events))
+}
+
+// Backs the mock cluster with an in-memory store of the objects it is asked to create, in which deployments become
+// available as soon as they are created
+func newInMemoryK8Cluster() *k8mock.K8Cluster {
+	objects := map[string]runtime.Object{}
+	key := func(object runtime.Object) string {
+		accessor, _ := meta.Accessor(object)
+		return fmt.Sprintf("%T/%s/%s", object, accessor.GetNamespace(), accessor.GetName())
+	}
+
+	return &k8mock.K8Cluster{
+		CreateK8ObjectFunc: func(ctx context.Context, object runtime.Object) error {
+			if _, ok := object.(*v1.Event); ok {
+				return nil
+			}
+			if _, ok := objects[key(object)]; ok {
+				return k8sErrors.NewAlreadyExists(schema.GroupResource{}, key(object))
+			}
+			if deployment, ok := object.(*appsv1.Deployment); ok {
+				deployment.Status.AvailableReplicas = *deployment.Spec.Replicas
+			}
+			objects[key(object)] = object.DeepCopyObject()
+			return nil
+		},
+		UpdateK8ObjectFunc: func(ctx context.Context, object runtime.Object) error {
+			if _, ok := object.(*v1.Service); ok {
+				objects[key(object)] = object.DeepCopyObject()
+			}
+			return nil
+		},
+		DeleteK8ObjectFunc: func(ctx context.Context, object runtime.Object) error {
+			if _, ok := objects[key(object)]; !ok {
+				return k8sErrors.NewNotFound(schema.GroupResource{}, key(object))
+			}
+			delete(objects, key(object))
+			return nil
+		},
+		GetServiceFunc: func(ctx context.Context, namespace string, name string) (*v1.Service, error) {
+			service, ok := objects[key(&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}})]
+			if !ok {
+				return nil, nil
+			}
+			return service.DeepCopyObject().(*v1.Service), nil
+		},
+		GetDeploymentsWithLabelFunc: func(ctx context.Context, namespace string, labelMap map[string]string) (*appsv1.DeploymentList, error) {
+			list := &appsv1.DeploymentList{}
+			for _, object := range objects {
+				deployment, ok := object.(*appsv1.Deployment)
+				if ok && deployment.Namespace == namespace && labels.SelectorFromSet(labelMap).Matches(labels.Set(deployment.Labels)) {
+					list.Items = append(list.Items, *deployment.DeepCopy())
+				}
+			}
+			return list, nil
+		},
+	}
+}
+
+// Runs the state machine against the fake JobManager through an initial deploy and an update that stops the job
+// with a savepoint and restores the new job from it
+func TestStateMachineWithFakeJobManager(t *testing.T) {
+	jm := fake.NewJobManager(fake.Config{
+		SlotsPerTaskManager: 8,
+	})
+	server := httptest.NewServer(jm)
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	serverPort, _ := strconv.Atoi(serverURL.Port())
+	err := controller_config.ConfigSection.SetConfig(&controller_config.Config{
+		UseProxy:  true,
+		ProxyPort: config.Port{Port: serverPort},
+	})
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, controller_config.ConfigSection.SetConfig(&controller_config.Config{}))
+	}()
+
+	labeled.SetMetricKeys(common.GetValidLabelNames()...)
+	k8Cluster := newInMemoryK8Cluster()
+	stateMachineForTest := NewFlinkStateMachine(k8Cluster, controller_config.RuntimeConfig{
+		MetricsScope: mockScope.NewTestScope(),
+	})
+
+	app := v1alpha1.FlinkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app",
+			Namespace: "flink",
+		},
+		Spec: v1alpha1.FlinkApplicationSpec{
+			Image:        "flink-job:1",
+			JarName:      "job.jar",
+			Parallelism:  2,
+			FlinkVersion: "1.9",
+		},
+	}
+	handleUntil := func(phase v1alpha1.FlinkApplicationPhase) {
+		for i := 0; i < 10 && app.Status.Phase != phase; i++ {
+			assert.Nil(t, stateMachineForTest.Handle(context.Background(), &app))
+		}
+		assert.Equal(t, phase, app.Status.Phase)
+	}
+
+	// the initial deploy submits the job without a savepoint
+	handleUntil(v1alpha1.FlinkApplicationRunning)
+	firstHash := app.Status.DeployHash
+	firstJobID := app.Status.JobStatus.JobID
+	assert.Equal(t, flink.HashForApplication(&app), firstHash)
+	request, ok := jm.SubmitRequest(firstJobID)
+	assert.True(t, ok)
+	assert.Equal(t, "", request.SavepointPath)
+	assert.Equal(t, int32(2), request.Parallelism)
+
+	assert.Nil(t, stateMachineForTest.Handle(context.Background(), &app))
+	assert.Equal(t, v1alpha1.JobState(client.Running), app.Status.JobStatus.State)
+
+	// the update drains the job into a savepoint in the old cluster and restores the new job in the new cluster
+	app.Spec.Image = "flink-job:2"
+	app.Spec.StopMode = v1alpha1.StopModeDrain
+	handleUntil(v1alpha1.FlinkApplicationUpdating)
+	handleUntil(v1alpha1.FlinkApplicationRunning)
+	assert.NotEqual(t, firstHash, app.Status.DeployHash)
+	assert.NotEqual(t, firstJobID, app.Status.JobStatus.JobID)
+	assert.True(t, jm.IsDrained(firstJobID))
+
+	request, ok = jm.SubmitRequest(app.Status.JobStatus.JobID)
+	assert.True(t, ok)
+	assert.NotEmpty(t, request.SavepointPath)
+	assert.Contains(t, request.SavepointPath, fake.DefaultSavepointDirectory)
+
+	// the old cluster is deleted once the new one is running
+	assert.Nil(t, stateMachineForTest.Handle(context.Background(), &app))
+	deployments, _ := k8Cluster.GetDeploymentsWithLabel(context.Background(), app.Namespace, map[string]string{
+		flink.FlinkAppHash: firstHash,
+	})
+	assert.Empty(t, deployments.Items)
+	service, _ := k8Cluster.GetService(context.Background(), app.Namespace, app.Name)
+	assert.Equal(t, app.Status.DeployHash, service.Spec.Selector[flink.FlinkAppHash])
+}
+
+func TestHandleReportsNonRetryableErrors(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	createErr := flinkErrors.Errorf(flinkErrors.BadJobSpecificationError, "invalid number of task slots")
+	mockFlinkController.CreateClusterFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+		return createErr
+	}
+
+	app := v1alpha1.FlinkApplication{
+		Spec: v1alpha1.FlinkApplicationSpec{
+			FlinkVersion: "1.8",
+		},
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase: v1alpha1.FlinkApplicationNew,
+		},
+	}
+
+	updateCount := 0
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, v1alpha1.FlinkApplicationNew, application.Status.Phase)
+		assert.Equal(t, "ErrorCode: [BadJobSpecificationError] Reason: [invalid number of task slots]", application.Status.Reason)
+		updateCount++
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.Equal(t, createErr, err)
+	assert.Equal(t, 1, updateCount)
+	assert.Equal(t, 1, len(mockFlinkController.Events))
+	assert.Equal(t, "User error: ErrorCode: [BadJobSpecificationError] Reason: [invalid number of task slots]", mockFlinkController.Events[0].Message)
+
+	// the same error should not be reported again
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.Equal(t, createErr, err)
+	assert.Equal(t, 1, updateCount)
+	assert.Equal(t, 1, len(mockFlinkController.Events))
+
+	// and retryable errors are not reported at all
+	mockFlinkController.CreateClusterFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication) error {
+		return flinkErrors.WrapErrorf(flinkErrors.JobManagerUnavailableError, errors.New("timeout"), "request failed")
+	}
+	err = stateMachineForTest.Handle(context.Background(), &app)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, updateCount)
+	assert.Equal(t, 1, len(mockFlinkController.Events))
+}
+
+func TestSubmittingJobUserErrorRollsBack(t *testing.T) {
+	app := v1alpha1.FlinkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-app",
+			Namespace:  "flink",
+			Finalizers: []string{jobFinalizer},
+		},
+		Spec: v1alpha1.FlinkApplicationSpec{
+			JarName:     "job.jar",
+			Parallelism: 5,
+		},
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:      v1alpha1.FlinkApplicationSubmittingJob,
+			DeployHash: "old-hash",
+		},
+	}
+	appHash := flink.HashForApplication(&app)
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.IsServiceReadyFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) (bool, error) {
+		return true, nil
+	}
+	mockFlinkController.GetJobsForApplicationFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string) ([]client.FlinkJob, error) {
+		return nil, nil
+	}
+	mockFlinkController.StartFlinkJobFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string,
+		jarName string, parallelism int32, entryClass string, programArgs string) (string, error) {
+		return "", flinkErrors.Errorf(flinkErrors.JobSubmissionError, "Job submission failed with status 400")
+	}
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.GetServiceFunc = func(ctx context.Context, namespace string, name string) (*v1.Service, error) {
+		return &v1.Service{
+			Spec: v1.ServiceSpec{
+				Ports: flink.FetchJobManagerServiceCreateObj(&app, appHash).Spec.Ports,
+				Selector: map[string]string{
+					flink.FlinkAppHash: appHash,
+				},
+			},
+		}, nil
+	}
+	updateInvoked := false
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		application := object.(*v1alpha1.FlinkApplication)
+		assert.Equal(t, v1alpha1.FlinkApplicationRollingBackJob, application.Status.Phase)
+		assert.Equal(t, "ErrorCode: [JobSubmissionError] Reason: [Job submission failed with status 400]", application.Status.Reason)
+		updateInvoked = true
+		return nil
+	}
+
+	err := stateMachineForTest.Handle(context.Background(), &app)
+	assert.Nil(t, err)
+	assert.True(t, updateInvoked)
+}
+
+func TestUpdateApplicationPatchesChanges(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+	app := v1alpha1.FlinkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-app",
+			Namespace:  "flink",
+			Finalizers: []string{"other"},
+		},
+		Spec: v1alpha1.FlinkApplicationSpec{JarName: "job.jar"},
+	}
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	// another writer adds a finalizer in the meantime
+	mockK8Cluster.GetK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		object.(*v1alpha1.FlinkApplication).Finalizers = []string{"other", "added"}
+		return nil
+	}
+	var updated *v1alpha1.FlinkApplication
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		updated = object.(*v1alpha1.FlinkApplication)
+		return nil
+	}
+	var patches []*v1alpha1.FlinkApplication
+	mockK8Cluster.PatchK8ObjectFunc = func(ctx context.Context, original runtime.Object, modified runtime.Object) error {
+		patches = append(patches, original.(*v1alpha1.FlinkApplication).DeepCopy())
+		return nil
+	}
+
+	ctx := withApplicationSnapshot(context.Background(), &app)
+	app.Status.Phase = v1alpha1.FlinkApplicationClusterStarting
+	assert.Nil(t, stateMachineForTest.updateApplication(ctx, &app))
+
+	// finalizers are written to the latest version of the application
+	assert.Nil(t, stateMachineForTest.addFinalizerIfMissing(ctx, &app, jobFinalizer))
+	assert.Equal(t, []string{"other", "added", jobFinalizer}, updated.Finalizers)
+	assert.Equal(t, []string{"other", "added", jobFinalizer}, app.Finalizers)
+
+	app.Status.Phase = v1alpha1.FlinkApplicationSubmittingJob
+	assert.Nil(t, stateMachineForTest.updateApplication(ctx, &app))
+
+	// each patch is computed against what was last written
+	assert.Equal(t, 2, len(patches))
+	assert.Equal(t, v1alpha1.FlinkApplicationNew, patches[0].Status.Phase)
+	assert.Equal(t, v1alpha1.FlinkApplicationClusterStarting, patches[1].Status.Phase)
+	assert.Equal(t, app.Finalizers, patches[1].Finalizers)
+}
+
+func TestUpdateFinalizersFailure(t *testing.T) {
+	stateMachineForTest := getTestStateMachine()
+	app := v1alpha1.FlinkApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-app",
+			Namespace:  "flink",
+			Finalizers: []string{jobFinalizer},
+		},
+	}
+
+	mockK8Cluster := stateMachineForTest.k8Cluster.(*k8mock.K8Cluster)
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		return errors.New("update failed")
+	}
+
+	// the in-memory application is left as it is when the finalizers could not be written
+	assert.NotNil(t, stateMachineForTest.clearFinalizers(context.Background(), &app))
+	assert.Equal(t, []string{jobFinalizer}, app.Finalizers)
+}
@@ -0,0 +1,146 @@
+package flinkapplication
+
+import (
+	"context"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Decides which namespaces applications are reconciled in. Namespaces are either listed in the config, or selected by
+// their labels, in which case they are looked up in the cache so that label changes take effect without restarting
+// the operator. The objects of the watched namespaces are cached per namespace, so that the operator does not cache
+// the objects of the whole cluster.
+type namespaceFilter struct {
+	namespaces map[string]bool
+	selector   labels.Selector
+	cache      namespaceCache
+}
+
+// The caching of the watched namespaces, implemented by k8.NamespacedCache
+type namespaceCache interface {
+	client.Reader
+	AddNamespace(namespace string) error
+	RemoveNamespace(namespace string)
+}
+
+// Returns nil if applications are reconciled in all namespaces the operator watches. A single limitNamespace does not
+// need a filter, as the caches are limited to it already (see Config.GetCacheNamespace).
+func newNamespaceFilter(cfg *config.Config, cache namespaceCache) (*namespaceFilter, error) {
+	if len(cfg.LimitNamespaces) == 0 && cfg.NamespaceSelector == "" {
+		return nil, nil
+	}
+
+	namespaces := map[string]bool{}
+	if cfg.LimitNamespace != "" {
+		namespaces[cfg.LimitNamespace] = true
+	}
+	for _, namespace := range cfg.LimitNamespaces {
+		namespaces[namespace] = true
+	}
+
+	var selector labels.Selector
+	if cfg.NamespaceSelector != "" {
+		var err error
+		selector, err = labels.Parse(cfg.NamespaceSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid namespace selector %s", cfg.NamespaceSelector)
+		}
+	}
+
+	for namespace := range namespaces {
+		if err := cache.AddNamespace(namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	return &namespaceFilter{
+		namespaces: namespaces,
+		selector:   selector,
+		cache:      cache,
+	}, nil
+}
+
+func (f *namespaceFilter) isWatched(ctx context.Context, namespace string) (bool, error) {
+	if f == nil || f.namespaces[namespace] {
+		return true, nil
+	}
+	if f.selector == nil {
+		return false, nil
+	}
+
+	ns := &coreV1.Namespace{}
+	if err := f.cache.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if k8.IsK8sObjectDoesNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return f.selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// Starts caching a namespace once it matches the selector, which picks up the applications in it, and releases it
+// once it no longer does
+func (f *namespaceFilter) getNamespaceEventHandler() toolscache.ResourceEventHandler {
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: f.onNamespaceChange,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			f.onNamespaceChange(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if ns, ok := obj.(*coreV1.Namespace); ok {
+				f.release(context.Background(), ns.Name)
+			}
+		},
+	}
+}
+
+func (f *namespaceFilter) onNamespaceChange(obj interface{}) {
+	ns, ok := obj.(*coreV1.Namespace)
+	if !ok || f.namespaces[ns.Name] {
+		return
+	}
+
+	ctx := context.Background()
+	if f.selector.Matches(labels.Set(ns.Labels)) {
+		if err := f.cache.AddNamespace(ns.Name); err != nil {
+			logger.Warnf(ctx, "Failed to watch namespace %s: %v", ns.Name, err)
+		}
+		return
+	}
+	f.release(ctx, ns.Name)
+}
+
+// Stops caching a namespace that is no longer watched. A namespace is kept as long as there are applications in it,
+// so that their deletion, which is blocked on the finalizer of the operator, is still processed; it is released once
+// the last of them is gone.
+func (f *namespaceFilter) release(ctx context.Context, namespace string) {
+	if f == nil {
+		return
+	}
+	if watched, err := f.isWatched(ctx, namespace); err != nil || watched {
+		return
+	}
+
+	applications := &v1alpha1.FlinkApplicationList{}
+	err := f.cache.List(ctx, &client.ListOptions{Namespace: namespace}, applications)
+	if err != nil && !k8.IsK8sObjectDoesNotExist(err) {
+		logger.Warnf(ctx, "Failed to list applications in namespace %s: %v", namespace, err)
+		return
+	}
+	if len(applications.Items) > 0 {
+		return
+	}
+	f.cache.RemoveNamespace(namespace)
+}
@@ -0,0 +1,139 @@
+package flinkapplication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Serves namespaces and applications from memory, and records the namespaces that are cached
+type testReader struct {
+	namespaces   map[string]map[string]string
+	applications []v1alpha1.FlinkApplication
+	cached       map[string]bool
+}
+
+func (r *testReader) AddNamespace(namespace string) error {
+	if r.cached == nil {
+		r.cached = map[string]bool{}
+	}
+	r.cached[namespace] = true
+	return nil
+}
+
+func (r *testReader) RemoveNamespace(namespace string) {
+	delete(r.cached, namespace)
+}
+
+func (r *testReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	nsLabels, ok := r.namespaces[key.Name]
+	if !ok {
+		return k8sErrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, key.Name)
+	}
+	obj.(*coreV1.Namespace).Labels = nsLabels
+	return nil
+}
+
+func (r *testReader) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	applications := list.(*v1alpha1.FlinkApplicationList)
+	for _, app := range r.applications {
+		if app.Namespace == opts.Namespace {
+			applications.Items = append(applications.Items, app)
+		}
+	}
+	return nil
+}
+
+func TestNamespaceFilter(t *testing.T) {
+	ctx := context.Background()
+	reader := &testReader{namespaces: map[string]map[string]string{
+		"team-a": {"flink-enabled": "true"},
+		"team-b": {"flink-enabled": "false"},
+	}}
+
+	// a single namespace is handled by the caches
+	filter, err := newNamespaceFilter(&config.Config{LimitNamespace: "flink"}, reader)
+	assert.Nil(t, err)
+	assert.Nil(t, filter)
+	watched, err := filter.isWatched(ctx, "team-b")
+	assert.Nil(t, err)
+	assert.True(t, watched)
+
+	filter, err = newNamespaceFilter(&config.Config{
+		LimitNamespace:    "flink",
+		LimitNamespaces:   []string{"team-c"},
+		NamespaceSelector: "flink-enabled=true",
+	}, reader)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"flink": true, "team-c": true}, reader.cached)
+	for namespace, expected := range map[string]bool{
+		"flink":   true,
+		"team-a":  true,
+		"team-b":  false,
+		"team-c":  true,
+		"missing": false,
+	} {
+		watched, err := filter.isWatched(ctx, namespace)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, watched, namespace)
+	}
+
+	// label changes are picked up
+	reader.namespaces["team-b"]["flink-enabled"] = "true"
+	watched, err = filter.isWatched(ctx, "team-b")
+	assert.Nil(t, err)
+	assert.True(t, watched)
+
+	_, err = newNamespaceFilter(&config.Config{NamespaceSelector: "flink-enabled in (true"}, reader)
+	assert.NotNil(t, err)
+}
+
+func TestNamespaceEventHandler(t *testing.T) {
+	reader := &testReader{
+		namespaces: map[string]map[string]string{
+			"team-a": {"flink-enabled": "true"},
+			"team-b": {"flink-enabled": "true"},
+		},
+		applications: []v1alpha1.FlinkApplication{
+			{ObjectMeta: metaV1.ObjectMeta{Namespace: "team-a", Name: "app-1"}},
+		},
+	}
+	filter, err := newNamespaceFilter(&config.Config{
+		LimitNamespaces:   []string{"team-c"},
+		NamespaceSelector: "flink-enabled=true",
+	}, reader)
+	assert.Nil(t, err)
+	handler := filter.getNamespaceEventHandler()
+
+	// namespaces are cached once they match the selector
+	teamA := &coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "team-a", Labels: reader.namespaces["team-a"]}}
+	teamB := &coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "team-b", Labels: reader.namespaces["team-b"]}}
+	handler.OnAdd(teamA)
+	handler.OnAdd(teamB)
+	handler.OnAdd(&coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "team-d"}})
+	assert.Equal(t, map[string]bool{"team-a": true, "team-b": true, "team-c": true}, reader.cached)
+
+	// namespaces that no longer match are kept as long as they contain applications
+	reader.namespaces["team-a"] = map[string]string{}
+	reader.namespaces["team-b"] = map[string]string{}
+	handler.OnUpdate(teamA, &coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "team-a"}})
+	handler.OnUpdate(teamB, &coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "team-b"}})
+	assert.Equal(t, map[string]bool{"team-a": true, "team-c": true}, reader.cached)
+
+	reader.applications = nil
+	filter.release(context.Background(), "team-a")
+	assert.Equal(t, map[string]bool{"team-c": true}, reader.cached)
+
+	// namespaces listed in the config are never released
+	handler.OnDelete(&coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "team-c"}})
+	assert.Equal(t, map[string]bool{"team-c": true}, reader.cached)
+}
@@ -0,0 +1,170 @@
+package flinkapplication
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	"github.com/lyft/flytestdlib/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Number of disposed (or failed to dispose) savepoints that are kept in the status for reference
+const maxDisposedSavepointRecords = 5
+
+// Records a savepoint taken during an update, so that it can later be disposed according to the retention policy.
+// Savepoints are only tracked for applications that have a retention policy.
+func recordSavepoint(app *v1alpha1.FlinkApplication, location string) {
+	if app.Spec.SavepointRetention == nil {
+		return
+	}
+	for _, sp := range app.Status.Savepoints {
+		if sp.Location == location {
+			return
+		}
+	}
+	app.Status.Savepoints = append(app.Status.Savepoints, v1alpha1.SavepointRecord{
+		Location:  location,
+		CreatedAt: metav1.Now(),
+	})
+}
+
+// Enforces the application's savepoint retention policy while it is running: marks the savepoint the job was restored
+// from, polls disposals in progress and triggers the disposal of the next expired savepoint. At most one disposal is
+// in progress at a time. Returns true if the status was changed.
+func (s *FlinkStateMachine) enforceSavepointRetention(ctx context.Context, app *v1alpha1.FlinkApplication) bool {
+	if len(app.Status.Savepoints) == 0 {
+		return false
+	}
+
+	changed := false
+	now := metav1.Now()
+	jobRunning := app.Status.JobStatus.State == v1alpha1.Running
+
+	disposing := false
+	for i := range app.Status.Savepoints {
+		sp := &app.Status.Savepoints[i]
+
+		// the job can only be running after it has successfully restored from its savepoint
+		if sp.RestoredAt == nil && jobRunning && sp.Location == app.Spec.SavepointInfo.SavepointLocation {
+			sp.RestoredAt = &now
+			changed = true
+		}
+
+		if sp.DisposalState == v1alpha1.SavepointDisposing {
+			if s.updateDisposalStatus(ctx, app, sp) {
+				changed = true
+			}
+			disposing = disposing || sp.DisposalState == v1alpha1.SavepointDisposing
+		}
+	}
+
+	if !disposing && app.Spec.SavepointRetention != nil {
+		if sp := getExpiredSavepoint(app, now); sp != nil {
+			triggerID, err := s.flinkController.DisposeSavepoint(ctx, app, app.Status.DeployHash, sp.Location)
+			if err != nil {
+				logger.Warnf(ctx, "Failed to trigger disposal of savepoint %s: %v", sp.Location, err)
+			} else {
+				s.flinkController.LogEvent(ctx, app, "", corev1.EventTypeNormal,
+					fmt.Sprintf("Disposing savepoint %s", sp.Location))
+				sp.DisposalState = v1alpha1.SavepointDisposing
+				sp.DisposalTriggerID = triggerID
+				changed = true
+			}
+		}
+	}
+
+	if pruneDisposedSavepoints(app) {
+		changed = true
+	}
+	return changed
+}
+
+func (s *FlinkStateMachine) updateDisposalStatus(ctx context.Context, app *v1alpha1.FlinkApplication,
+	sp *v1alpha1.SavepointRecord) bool {
+	status, err := s.flinkController.GetSavepointDisposalStatus(ctx, app, app.Status.DeployHash, sp.DisposalTriggerID)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get status of savepoint disposal %s: %v", sp.DisposalTriggerID, err)
+		return false
+	}
+	if status == nil || status.SavepointStatus.Status != client.SavePointCompleted {
+		return false
+	}
+
+	now := metav1.Now()
+	sp.DisposedAt = &now
+	sp.DisposalTriggerID = ""
+	if status.Operation.FailureCause != (client.FailureCause{}) {
+		// failed disposals are not retried, as they usually mean the savepoint no longer exists
+		sp.DisposalState = v1alpha1.SavepointDisposalFailed
+		sp.DisposalError = status.Operation.FailureCause.Class
+		s.flinkController.LogEvent(ctx, app, "", corev1.EventTypeWarning,
+			fmt.Sprintf("Failed to dispose savepoint %s: %v", sp.Location, status.Operation.FailureCause))
+	} else {
+		sp.DisposalState = v1alpha1.SavepointDisposed
+		s.flinkController.LogEvent(ctx, app, "", corev1.EventTypeNormal,
+			fmt.Sprintf("Disposed savepoint %s", sp.Location))
+	}
+	return true
+}
+
+// Returns the oldest retained savepoint that should be disposed under the retention policy, if any
+func getExpiredSavepoint(app *v1alpha1.FlinkApplication, now metav1.Time) *v1alpha1.SavepointRecord {
+	policy := app.Spec.SavepointRetention
+	jobRunning := app.Status.JobStatus.State == v1alpha1.Running
+
+	retained := 0
+	for _, sp := range app.Status.Savepoints {
+		if sp.DisposalState == v1alpha1.SavepointRetained {
+			retained++
+		}
+	}
+
+	for i := range app.Status.Savepoints {
+		sp := &app.Status.Savepoints[i]
+		if sp.DisposalState != v1alpha1.SavepointRetained {
+			continue
+		}
+
+		// savepoints are ordered oldest first, so the first retained savepoints are the ones beyond the newest N
+		if policy.KeepLast != nil && retained > int(*policy.KeepLast) &&
+			sp.Location != app.Spec.SavepointInfo.SavepointLocation {
+			return sp
+		}
+		retained--
+
+		if policy.DeleteAfterRestore != nil && jobRunning && sp.RestoredAt != nil &&
+			now.Sub(sp.RestoredAt.Time) >= policy.DeleteAfterRestore.Duration {
+			return sp
+		}
+	}
+	return nil
+}
+
+// Drops the oldest records of disposed savepoints beyond maxDisposedSavepointRecords
+func pruneDisposedSavepoints(app *v1alpha1.FlinkApplication) bool {
+	finished := 0
+	for _, sp := range app.Status.Savepoints {
+		if sp.DisposalState == v1alpha1.SavepointDisposed || sp.DisposalState == v1alpha1.SavepointDisposalFailed {
+			finished++
+		}
+	}
+	if finished <= maxDisposedSavepointRecords {
+		return false
+	}
+
+	toRemove := finished - maxDisposedSavepointRecords
+	savepoints := make([]v1alpha1.SavepointRecord, 0, len(app.Status.Savepoints)-toRemove)
+	for _, sp := range app.Status.Savepoints {
+		if toRemove > 0 && (sp.DisposalState == v1alpha1.SavepointDisposed ||
+			sp.DisposalState == v1alpha1.SavepointDisposalFailed) {
+			toRemove--
+			continue
+		}
+		savepoints = append(savepoints, sp)
+	}
+	app.Status.Savepoints = savepoints
+	return true
+}
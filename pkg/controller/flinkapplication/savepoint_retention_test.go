@@ -0,0 +1,193 @@
+package flinkapplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/client"
+	"github.com/lyft/flinkk8soperator/pkg/controller/flink/mock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getRetentionTestApp(policy v1alpha1.SavepointRetentionPolicy, locations ...string) *v1alpha1.FlinkApplication {
+	app := &v1alpha1.FlinkApplication{
+		Spec: v1alpha1.FlinkApplicationSpec{
+			SavepointRetention: &policy,
+		},
+		Status: v1alpha1.FlinkApplicationStatus{
+			Phase:      v1alpha1.FlinkApplicationRunning,
+			DeployHash: "hash",
+			JobStatus: v1alpha1.FlinkJobStatus{
+				State: v1alpha1.Running,
+			},
+		},
+	}
+	for _, location := range locations {
+		recordSavepoint(app, location)
+	}
+	if len(locations) > 0 {
+		app.Spec.SavepointInfo.SavepointLocation = locations[len(locations)-1]
+	}
+	return app
+}
+
+func completedDisposal(cause client.FailureCause) *client.SavepointResponse {
+	return &client.SavepointResponse{
+		SavepointStatus: client.SavepointStatusResponse{
+			Status: client.SavePointCompleted,
+		},
+		Operation: client.SavepointOperationResponse{
+			FailureCause: cause,
+		},
+	}
+}
+
+func TestRecordSavepoint(t *testing.T) {
+	app := &v1alpha1.FlinkApplication{}
+	recordSavepoint(app, "s3://savepoints/sp-1")
+	assert.Empty(t, app.Status.Savepoints)
+
+	keepLast := int32(1)
+	app.Spec.SavepointRetention = &v1alpha1.SavepointRetentionPolicy{KeepLast: &keepLast}
+	recordSavepoint(app, "s3://savepoints/sp-1")
+	recordSavepoint(app, "s3://savepoints/sp-1")
+	assert.Equal(t, 1, len(app.Status.Savepoints))
+	assert.Equal(t, "s3://savepoints/sp-1", app.Status.Savepoints[0].Location)
+	assert.Equal(t, v1alpha1.SavepointRetained, app.Status.Savepoints[0].DisposalState)
+}
+
+func TestSavepointRetentionKeepLast(t *testing.T) {
+	keepLast := int32(1)
+	app := getRetentionTestApp(v1alpha1.SavepointRetentionPolicy{KeepLast: &keepLast},
+		"s3://savepoints/sp-1", "s3://savepoints/sp-2", "s3://savepoints/sp-3")
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	var disposed []string
+	mockFlinkController.DisposeSavepointFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, savepointPath string) (string, error) {
+		assert.Equal(t, "hash", hash)
+		disposed = append(disposed, savepointPath)
+		return fmt.Sprintf("trigger-%d", len(disposed)), nil
+	}
+	mockFlinkController.GetSavepointDisposalStatusFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+		assert.Equal(t, "trigger-1", triggerID)
+		return completedDisposal(client.FailureCause{}), nil
+	}
+
+	// the restored savepoint is marked and the oldest savepoint is disposed
+	assert.True(t, stateMachineForTest.enforceSavepointRetention(context.Background(), app))
+	assert.Equal(t, []string{"s3://savepoints/sp-1"}, disposed)
+	assert.Equal(t, v1alpha1.SavepointDisposing, app.Status.Savepoints[0].DisposalState)
+	assert.Equal(t, "trigger-1", app.Status.Savepoints[0].DisposalTriggerID)
+	assert.NotNil(t, app.Status.Savepoints[2].RestoredAt)
+
+	// only one disposal is in progress at a time
+	assert.True(t, stateMachineForTest.enforceSavepointRetention(context.Background(), app))
+	assert.Equal(t, v1alpha1.SavepointDisposed, app.Status.Savepoints[0].DisposalState)
+	assert.NotNil(t, app.Status.Savepoints[0].DisposedAt)
+	assert.Empty(t, app.Status.Savepoints[0].DisposalTriggerID)
+	assert.Equal(t, []string{"s3://savepoints/sp-1", "s3://savepoints/sp-2"}, disposed)
+	assert.Equal(t, v1alpha1.SavepointDisposing, app.Status.Savepoints[1].DisposalState)
+	assert.Equal(t, v1alpha1.SavepointRetained, app.Status.Savepoints[2].DisposalState)
+}
+
+func TestSavepointRetentionKeepsCurrentSavepoint(t *testing.T) {
+	keepLast := int32(0)
+	app := getRetentionTestApp(v1alpha1.SavepointRetentionPolicy{KeepLast: &keepLast}, "s3://savepoints/sp-1")
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	mockFlinkController.DisposeSavepointFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, savepointPath string) (string, error) {
+		assert.False(t, true)
+		return "", nil
+	}
+
+	// the savepoint the spec refers to may still be needed to restore the job
+	stateMachineForTest.enforceSavepointRetention(context.Background(), app)
+	assert.Equal(t, v1alpha1.SavepointRetained, app.Status.Savepoints[0].DisposalState)
+}
+
+func TestSavepointRetentionDeleteAfterRestore(t *testing.T) {
+	app := getRetentionTestApp(v1alpha1.SavepointRetentionPolicy{
+		DeleteAfterRestore: &metav1.Duration{Duration: time.Hour},
+	}, "s3://savepoints/sp-1")
+	app.Status.JobStatus.State = v1alpha1.Failing
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	disposeInvoked := false
+	mockFlinkController.DisposeSavepointFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, savepointPath string) (string, error) {
+		assert.Equal(t, "s3://savepoints/sp-1", savepointPath)
+		disposeInvoked = true
+		return "trigger", nil
+	}
+
+	// savepoints are not considered restored until the job is running
+	assert.False(t, stateMachineForTest.enforceSavepointRetention(context.Background(), app))
+	assert.Nil(t, app.Status.Savepoints[0].RestoredAt)
+
+	app.Status.JobStatus.State = v1alpha1.Running
+	assert.True(t, stateMachineForTest.enforceSavepointRetention(context.Background(), app))
+	assert.NotNil(t, app.Status.Savepoints[0].RestoredAt)
+	assert.False(t, disposeInvoked)
+
+	restoredAt := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	app.Status.Savepoints[0].RestoredAt = &restoredAt
+	assert.True(t, stateMachineForTest.enforceSavepointRetention(context.Background(), app))
+	assert.True(t, disposeInvoked)
+	assert.Equal(t, v1alpha1.SavepointDisposing, app.Status.Savepoints[0].DisposalState)
+}
+
+func TestSavepointDisposalFailed(t *testing.T) {
+	keepLast := int32(1)
+	app := getRetentionTestApp(v1alpha1.SavepointRetentionPolicy{KeepLast: &keepLast},
+		"s3://savepoints/sp-1", "s3://savepoints/sp-2")
+	app.Status.Savepoints[0].DisposalState = v1alpha1.SavepointDisposing
+	app.Status.Savepoints[0].DisposalTriggerID = "trigger"
+
+	stateMachineForTest := getTestStateMachine()
+	mockFlinkController := stateMachineForTest.flinkController.(*mock.FlinkController)
+	statusCalls := 0
+	mockFlinkController.GetSavepointDisposalStatusFunc = func(ctx context.Context, application *v1alpha1.FlinkApplication, hash string, triggerID string) (*client.SavepointResponse, error) {
+		statusCalls++
+		if statusCalls == 1 {
+			return nil, errors.New("jobmanager unavailable")
+		}
+		return completedDisposal(client.FailureCause{
+			Class: "java.io.FileNotFoundException",
+		}), nil
+	}
+
+	// errors fetching the status are retried on the next reconciliation
+	stateMachineForTest.enforceSavepointRetention(context.Background(), app)
+	assert.Equal(t, v1alpha1.SavepointDisposing, app.Status.Savepoints[0].DisposalState)
+
+	assert.True(t, stateMachineForTest.enforceSavepointRetention(context.Background(), app))
+	assert.Equal(t, v1alpha1.SavepointDisposalFailed, app.Status.Savepoints[0].DisposalState)
+	assert.Equal(t, "java.io.FileNotFoundException", app.Status.Savepoints[0].DisposalError)
+	assert.Equal(t, 2, statusCalls)
+}
+
+func TestPruneDisposedSavepoints(t *testing.T) {
+	app := &v1alpha1.FlinkApplication{}
+	for i := 0; i < maxDisposedSavepointRecords+2; i++ {
+		app.Status.Savepoints = append(app.Status.Savepoints, v1alpha1.SavepointRecord{
+			Location:      fmt.Sprintf("s3://savepoints/sp-%d", i),
+			DisposalState: v1alpha1.SavepointDisposed,
+		})
+	}
+	app.Status.Savepoints = append(app.Status.Savepoints, v1alpha1.SavepointRecord{
+		Location: "s3://savepoints/current",
+	})
+
+	assert.True(t, pruneDisposedSavepoints(app))
+	assert.Equal(t, maxDisposedSavepointRecords+1, len(app.Status.Savepoints))
+	assert.Equal(t, "s3://savepoints/sp-2", app.Status.Savepoints[0].Location)
+	assert.Equal(t, "s3://savepoints/current", app.Status.Savepoints[maxDisposedSavepointRecords].Location)
+	assert.False(t, pruneDisposedSavepoints(app))
+}
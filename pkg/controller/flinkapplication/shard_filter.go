@@ -0,0 +1,210 @@
+package flinkapplication
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	"github.com/lyft/flinkk8soperator/pkg/controller/common"
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
+	"github.com/lyft/flinkk8soperator/pkg/controller/sharding"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Decides which applications this operator instance reconciles when applications are sharded. An instance claims the
+// applications of its shard by recording its shard in an annotation. Applications claimed by another shard, which
+// happens when the number of shards or the shard label of an application changes, are only taken over once the leader
+// election lock of that shard is no longer held, so that two instances never reconcile an application at the same time.
+type shardFilter struct {
+	shard     *sharding.Shard
+	k8Cluster k8.ClusterInterface
+	config    *config.Config
+	clock     clock.Clock
+	metrics   *shardMetrics
+
+	mu    sync.Mutex
+	owned map[types.NamespacedName]bool
+}
+
+type shardMetrics struct {
+	claimed        labeled.Counter
+	released       labeled.Counter
+	handoffWaiting labeled.Counter
+	index          prometheus.Gauge
+	count          prometheus.Gauge
+	applications   prometheus.Gauge
+}
+
+func newShardMetrics(scope promutils.Scope) *shardMetrics {
+	shardScope := scope.NewSubScope("shard")
+	return &shardMetrics{
+		claimed:        labeled.NewCounter("claimed", "Application claimed by this shard", shardScope),
+		released:       labeled.NewCounter("released", "Application moved to another shard released by this shard", shardScope),
+		handoffWaiting: labeled.NewCounter("handoff_waiting", "Application of this shard still claimed by another shard", shardScope),
+		index:          shardScope.MustNewGauge("index", "Index of the shard of this operator instance"),
+		count:          shardScope.MustNewGauge("count", "Number of shards"),
+		applications:   shardScope.MustNewGauge("applications", "Number of applications reconciled by this shard"),
+	}
+}
+
+// Returns nil if sharding is disabled. Sharding requires leader election, as an instance only takes over the
+// applications of another shard once the lock of that shard has expired.
+func newShardFilter(cfg *config.Config, k8Cluster k8.ClusterInterface, scope promutils.Scope) (*shardFilter, error) {
+	shard, err := sharding.NewShard(cfg.Sharding)
+	if err != nil || shard == nil {
+		return nil, err
+	}
+	if !cfg.LeaderElection.Enabled {
+		return nil, errors.New("Invalid config: sharding requires leaderElection.enabled")
+	}
+
+	metrics := newShardMetrics(scope)
+	metrics.index.Set(float64(shard.Index))
+	metrics.count.Set(float64(shard.Count))
+	return &shardFilter{
+		shard:     shard,
+		k8Cluster: k8Cluster,
+		config:    cfg,
+		clock:     clock.RealClock{},
+		metrics:   metrics,
+		owned:     map[types.NamespacedName]bool{},
+	}, nil
+}
+
+func (f *shardFilter) setOwned(key types.NamespacedName, owned bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if owned {
+		f.owned[key] = true
+	} else {
+		delete(f.owned, key)
+	}
+	f.metrics.applications.Set(float64(len(f.owned)))
+}
+
+// Forgets an application that no longer exists
+func (f *shardFilter) remove(key types.NamespacedName) {
+	if f != nil {
+		f.setOwned(key, false)
+	}
+}
+
+// Returns whether the leader election lock of the shard is held
+func (f *shardFilter) isShardActive(ctx context.Context, shard *sharding.Shard) (bool, error) {
+	namespace, err := f.config.GetLeaderElectionNamespace()
+	if err != nil {
+		return false, err
+	}
+
+	lock := &coreV1.ConfigMap{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       k8.ConfigMap,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Namespace: namespace,
+			Name:      shard.GetLockName(f.config.LeaderElection.LockName),
+		},
+	}
+	if err := f.k8Cluster.GetK8Object(ctx, lock); err != nil {
+		if k8.IsK8sObjectDoesNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return sharding.IsLockHeld(lock, f.clock.Now()), nil
+}
+
+// Writes the claim of the application as an update, so that it fails if another instance claimed it concurrently
+func (f *shardFilter) setClaim(ctx context.Context, application *v1alpha1.FlinkApplication, claim string) error {
+	claimed := application.DeepCopy()
+	if claim == "" {
+		delete(claimed.Annotations, sharding.ShardAnnotation)
+	} else {
+		claimed.Annotations = common.CopyMap(claimed.Annotations, map[string]string{sharding.ShardAnnotation: claim})
+	}
+	if err := f.k8Cluster.UpdateK8Object(ctx, claimed); err != nil {
+		return err
+	}
+	application.Annotations = claimed.Annotations
+	application.ResourceVersion = claimed.ResourceVersion
+	return nil
+}
+
+// Returns whether this instance reconciles the application, claiming it if needed. If the application belongs to this
+// shard but is still claimed by another one, the returned duration is the time after which to check again.
+func (f *shardFilter) claim(ctx context.Context, application *v1alpha1.FlinkApplication) (bool, time.Duration, error) {
+	if f == nil {
+		return true, 0, nil
+	}
+	key := types.NamespacedName{Namespace: application.Namespace, Name: application.Name}
+	claimant := application.Annotations[sharding.ShardAnnotation]
+
+	if !f.shard.Owns(application) {
+		f.setOwned(key, false)
+		// the application was moved to another shard, which waits for it to be released
+		if claimant == f.shard.String() {
+			logger.Infof(ctx, "Releasing application moved to another shard")
+			if err := f.setClaim(ctx, application, ""); err != nil {
+				return false, 0, err
+			}
+			f.metrics.released.Inc(ctx)
+		}
+		return false, 0, nil
+	}
+
+	if claimant != "" && claimant != f.shard.String() {
+		if previous, err := sharding.ParseShard(claimant); err == nil {
+			active, err := f.isShardActive(ctx, previous)
+			if err != nil {
+				return false, 0, err
+			}
+			if active {
+				logger.Infof(ctx, "Waiting for shard %s to release the application", claimant)
+				f.metrics.handoffWaiting.Inc(ctx)
+				return false, f.config.LeaderElection.LeaseDuration.Duration, nil
+			}
+		}
+	}
+
+	if claimant != f.shard.String() {
+		logger.Infof(ctx, "Claiming application for shard %s", f.shard)
+		if err := f.setClaim(ctx, application, f.shard.String()); err != nil {
+			return false, 0, err
+		}
+		f.metrics.claimed.Inc(ctx)
+	}
+	f.setOwned(key, true)
+	return true, 0, nil
+}
+
+// Only events for applications of this shard are enqueued. Updates are enqueued for the previous shard as well, so
+// that it releases applications that are moved to another shard.
+func (f *shardFilter) getPredicateFuncs() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return f.shard.Owns(e.Meta)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return f.shard.Owns(e.MetaNew) || f.shard.Owns(e.MetaOld)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return f.shard.Owns(e.Meta)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return f.shard.Owns(e.Meta)
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package flinkapplication
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/apis/app/v1alpha1"
+	controller_config "github.com/lyft/flinkk8soperator/pkg/controller/config"
+	k8mock "github.com/lyft/flinkk8soperator/pkg/controller/k8/mock"
+	"github.com/lyft/flinkk8soperator/pkg/controller/sharding"
+	"github.com/lyft/flytestdlib/config"
+	mockScope "github.com/lyft/flytestdlib/promutils"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func getTestShardFilter(now time.Time) *shardFilter {
+	return &shardFilter{
+		shard:     &sharding.Shard{Index: 0, Count: 2},
+		k8Cluster: &k8mock.K8Cluster{},
+		config: &controller_config.Config{LeaderElection: controller_config.LeaderElectionConfig{
+			Enabled:       true,
+			LockNamespace: "flink",
+			LockName:      "lock",
+			LeaseDuration: config.Duration{Duration: 15 * time.Second},
+		}},
+		clock:   clock.NewFakeClock(now),
+		metrics: newShardMetrics(mockScope.NewTestScope()),
+		owned:   map[types.NamespacedName]bool{},
+	}
+}
+
+// Returns an application that belongs to the shard if owned is set, and to another shard otherwise
+func getShardTestApp(shard *sharding.Shard, owned bool, claimant string) *v1alpha1.FlinkApplication {
+	for i := 0; ; i++ {
+		app := &v1alpha1.FlinkApplication{ObjectMeta: metaV1.ObjectMeta{
+			Namespace: "flink",
+			Name:      fmt.Sprintf("app-%d", i),
+		}}
+		if shard.Owns(app) == owned {
+			if claimant != "" {
+				app.Annotations = map[string]string{sharding.ShardAnnotation: claimant}
+			}
+			return app
+		}
+	}
+}
+
+func TestNewShardFilter(t *testing.T) {
+	filter, err := newShardFilter(&controller_config.Config{}, &k8mock.K8Cluster{}, mockScope.NewTestScope())
+	assert.Nil(t, err)
+	assert.Nil(t, filter)
+
+	cfg := &controller_config.Config{Sharding: controller_config.ShardingConfig{ShardCount: 2}}
+	_, err = newShardFilter(cfg, &k8mock.K8Cluster{}, mockScope.NewTestScope())
+	assert.EqualError(t, err, "Invalid config: sharding requires leaderElection.enabled")
+
+	cfg.LeaderElection.Enabled = true
+	filter, err = newShardFilter(cfg, &k8mock.K8Cluster{}, mockScope.NewTestScope())
+	assert.Nil(t, err)
+	assert.Equal(t, "0/2", filter.shard.String())
+}
+
+func TestShardFilterClaimsApplications(t *testing.T) {
+	filter := getTestShardFilter(time.Now())
+	mockK8Cluster := filter.k8Cluster.(*k8mock.K8Cluster)
+	var claims []string
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		claims = append(claims, object.(*v1alpha1.FlinkApplication).Annotations[sharding.ShardAnnotation])
+		return nil
+	}
+
+	app := getShardTestApp(filter.shard, true, "")
+	owned, _, err := filter.claim(context.Background(), app)
+	assert.Nil(t, err)
+	assert.True(t, owned)
+	assert.Equal(t, "0/2", app.Annotations[sharding.ShardAnnotation])
+
+	// applications that are already claimed are not written again
+	owned, _, err = filter.claim(context.Background(), app)
+	assert.Nil(t, err)
+	assert.True(t, owned)
+	assert.Equal(t, []string{"0/2"}, claims)
+	assert.Equal(t, 1, len(filter.owned))
+
+	// applications of other shards are ignored
+	owned, _, err = filter.claim(context.Background(), getShardTestApp(filter.shard, false, ""))
+	assert.Nil(t, err)
+	assert.False(t, owned)
+	assert.Equal(t, 1, len(claims))
+}
+
+func TestShardFilterReleasesMovedApplications(t *testing.T) {
+	filter := getTestShardFilter(time.Now())
+	mockK8Cluster := filter.k8Cluster.(*k8mock.K8Cluster)
+	updated := false
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		_, claimed := object.(*v1alpha1.FlinkApplication).Annotations[sharding.ShardAnnotation]
+		assert.False(t, claimed)
+		updated = true
+		return nil
+	}
+
+	app := getShardTestApp(filter.shard, false, "0/2")
+	owned, _, err := filter.claim(context.Background(), app)
+	assert.Nil(t, err)
+	assert.False(t, owned)
+	assert.True(t, updated)
+}
+
+func TestShardFilterWaitsForHandoff(t *testing.T) {
+	now := time.Now()
+	filter := getTestShardFilter(now)
+	mockK8Cluster := filter.k8Cluster.(*k8mock.K8Cluster)
+	renewTime := now.Add(-5 * time.Second)
+	mockK8Cluster.GetK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		lock := object.(*coreV1.ConfigMap)
+		assert.Equal(t, "flink", lock.Namespace)
+		if lock.Name != "lock-shard-1-of-3" {
+			return k8sErrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, lock.Name)
+		}
+		lock.Annotations = map[string]string{
+			resourcelock.LeaderElectionRecordAnnotationKey: fmt.Sprintf(
+				`{"holderIdentity":"operator-1","leaseDurationSeconds":15,"renewTime":"%s"}`, renewTime.Format(time.RFC3339)),
+		}
+		return nil
+	}
+	updated := false
+	mockK8Cluster.UpdateK8ObjectFunc = func(ctx context.Context, object runtime.Object) error {
+		updated = true
+		return nil
+	}
+
+	// the shard that claimed the application under the previous shard count is still running
+	app := getShardTestApp(filter.shard, true, "1/3")
+	owned, retryAfter, err := filter.claim(context.Background(), app)
+	assert.Nil(t, err)
+	assert.False(t, owned)
+	assert.Equal(t, 15*time.Second, retryAfter)
+	assert.False(t, updated)
+
+	// once its lease expires, the application is taken over
+	renewTime = now.Add(-time.Minute)
+	owned, _, err = filter.claim(context.Background(), app)
+	assert.Nil(t, err)
+	assert.True(t, owned)
+	assert.Equal(t, "0/2", app.Annotations[sharding.ShardAnnotation])
+
+	// shards without a lock are not running
+	app = getShardTestApp(filter.shard, true, "0/3")
+	owned, _, err = filter.claim(context.Background(), app)
+	assert.Nil(t, err)
+	assert.True(t, owned)
+}
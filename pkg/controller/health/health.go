@@ -0,0 +1,277 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const (
+	HealthzPath   = "/healthz"
+	ReadyzPath    = "/readyz"
+	DebugAppsPath = "/debug/apps"
+)
+
+// The result of a single check of the health of the operator
+type CheckResult struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+type checkResponse struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+// The state of an application as of its last reconciliation
+type AppStatus struct {
+	Namespace          string     `json:"namespace"`
+	Name               string     `json:"name"`
+	Phase              string     `json:"phase"`
+	LastReconciled     time.Time  `json:"lastReconciled"`
+	LastSuccessfulSync *time.Time `json:"lastSuccessfulSync,omitempty"`
+	LastError          string     `json:"lastError,omitempty"`
+}
+
+// Tracks the state of the operator that its health depends on: whether the caches have synced, whether this replica
+// is the leader, the depth of the work queues, and the outcome of the last reconciliation of every application. The
+// methods that record state can be called on a nil Monitor, which tracks nothing.
+type Monitor struct {
+	mu    sync.RWMutex
+	clock clock.Clock
+
+	maxQueueDepth   int
+	maxReconcileAge time.Duration
+
+	caches            []Cache
+	cacheSynced       bool
+	leader            bool
+	lastSuccessfulRun time.Time
+	queueDepths       map[string]int
+	apps              map[types.NamespacedName]*AppStatus
+}
+
+// Creates a monitor that reports the operator as not ready while the work queues hold more than maxQueueDepth items
+// (0 to disable), and as unhealthy while there is work queued but no reconciliation has succeeded for maxReconcileAge
+// (0 to disable)
+func NewMonitor(maxQueueDepth int, maxReconcileAge time.Duration) *Monitor {
+	return newMonitor(clock.RealClock{}, maxQueueDepth, maxReconcileAge)
+}
+
+func newMonitor(clock clock.Clock, maxQueueDepth int, maxReconcileAge time.Duration) *Monitor {
+	return &Monitor{
+		clock:             clock,
+		maxQueueDepth:     maxQueueDepth,
+		maxReconcileAge:   maxReconcileAge,
+		lastSuccessfulRun: clock.Now(),
+		queueDepths:       map[string]int{},
+		apps:              map[types.NamespacedName]*AppStatus{},
+	}
+}
+
+// A cache that has to sync before the operator is ready
+type Cache interface {
+	WaitForCacheSync(stop <-chan struct{}) bool
+}
+
+// Adds a cache that WaitForCacheSync waits for, in addition to the caches added before
+func (m *Monitor) AddCache(c Cache) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.caches = append(m.caches, c)
+}
+
+// Waits for all caches added to the monitor to sync, and records whether they did. It should be called once all
+// caches have been added.
+func (m *Monitor) WaitForCacheSync(stop <-chan struct{}) {
+	if m == nil {
+		return
+	}
+	m.mu.RLock()
+	caches := append([]Cache(nil), m.caches...)
+	m.mu.RUnlock()
+
+	for _, c := range caches {
+		if !c.WaitForCacheSync(stop) {
+			m.SetCacheSynced(false)
+			return
+		}
+	}
+	m.SetCacheSynced(true)
+}
+
+func (m *Monitor) SetCacheSynced(synced bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cacheSynced = synced
+}
+
+func (m *Monitor) SetLeader(leader bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if leader && !m.leader {
+		// work only starts being processed once elected
+		m.lastSuccessfulRun = m.clock.Now()
+	}
+	m.leader = leader
+}
+
+// Records the outcome of a reconciliation of the application
+func (m *Monitor) RecordReconcile(key types.NamespacedName, phase string, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	status, ok := m.apps[key]
+	if !ok {
+		status = &AppStatus{Namespace: key.Namespace, Name: key.Name}
+		m.apps[key] = status
+	}
+	status.Phase = phase
+	status.LastReconciled = now
+	if err != nil {
+		status.LastError = err.Error()
+		return
+	}
+	status.LastError = ""
+	status.LastSuccessfulSync = &now
+	m.lastSuccessfulRun = now
+}
+
+// Forgets an application that no longer exists
+func (m *Monitor) RemoveApp(key types.NamespacedName) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.apps, key)
+	// finding that an application is gone is progress as well
+	m.lastSuccessfulRun = m.clock.Now()
+}
+
+func (m *Monitor) addQueueDepth(queue string, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queueDepths[queue] += delta
+}
+
+func (m *Monitor) getQueueDepth() int {
+	depth := 0
+	for _, d := range m.queueDepths {
+		depth += d
+	}
+	return depth
+}
+
+// Liveness checks: the reconcile workers make progress while there is work queued
+func (m *Monitor) Healthz() []CheckResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stuck := CheckResult{Name: "reconcile", Healthy: true}
+	age := m.clock.Since(m.lastSuccessfulRun)
+	if m.leader && m.maxReconcileAge > 0 && m.getQueueDepth() > 0 && age > m.maxReconcileAge {
+		stuck.Healthy = false
+		stuck.Message = fmt.Sprintf("no successful reconciliation for %v with %d items queued", age.Round(time.Second), m.getQueueDepth())
+	}
+
+	return []CheckResult{stuck, m.leaderCheck()}
+}
+
+// Readiness checks: the caches have synced and the work queues are not backed up
+func (m *Monitor) Readyz() []CheckResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cache := CheckResult{Name: "cache", Healthy: m.cacheSynced}
+	if !m.cacheSynced {
+		cache.Message = "caches have not synced"
+	}
+
+	depth := m.getQueueDepth()
+	queue := CheckResult{Name: "queue", Healthy: true, Message: fmt.Sprintf("%d items queued", depth)}
+	if m.maxQueueDepth > 0 && depth > m.maxQueueDepth {
+		queue.Healthy = false
+		queue.Message = fmt.Sprintf("%d items queued, more than the maximum of %d", depth, m.maxQueueDepth)
+	}
+
+	return []CheckResult{cache, queue, m.leaderCheck()}
+}
+
+// Replicas that are not the leader are healthy, but the check shows which replica is doing the work
+func (m *Monitor) leaderCheck() CheckResult {
+	message := "not the leader"
+	if m.leader {
+		message = "leader"
+	}
+	return CheckResult{Name: "leader", Healthy: true, Message: message}
+}
+
+// Returns the status of every known application, ordered by namespace and name
+func (m *Monitor) Apps() []AppStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	apps := make([]AppStatus, 0, len(m.apps))
+	for _, status := range m.apps {
+		apps = append(apps, *status)
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].Namespace != apps[j].Namespace {
+			return apps[i].Namespace < apps[j].Namespace
+		}
+		return apps[i].Name < apps[j].Name
+	})
+	return apps
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func checksHandler(checks func() []CheckResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := checkResponse{Healthy: true, Checks: checks()}
+		for _, check := range response.Checks {
+			response.Healthy = response.Healthy && check.Healthy
+		}
+		status := http.StatusOK
+		if !response.Healthy {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, response)
+	})
+}
+
+// Returns the handlers to serve, by path
+func (m *Monitor) GetHandlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		HealthzPath: checksHandler(m.Healthz),
+		ReadyzPath:  checksHandler(m.Readyz),
+		DebugAppsPath: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, m.Apps())
+		}),
+	}
+}
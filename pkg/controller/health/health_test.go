@@ -0,0 +1,122 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func get(t *testing.T, m *Monitor, path string, result interface{}) int {
+	recorder := httptest.NewRecorder()
+	m.GetHandlers()[path].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), result))
+	return recorder.Code
+}
+
+func TestReadyz(t *testing.T) {
+	m := newMonitor(clock.NewFakeClock(time.Now()), 2, time.Minute)
+	response := checkResponse{}
+
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, m, ReadyzPath, &response))
+	assert.False(t, response.Healthy)
+	assert.Equal(t, CheckResult{Name: "cache", Healthy: false, Message: "caches have not synced"}, response.Checks[0])
+
+	m.SetCacheSynced(true)
+	assert.Equal(t, http.StatusOK, get(t, m, ReadyzPath, &response))
+	assert.True(t, response.Healthy)
+
+	provider := &queueMetricsProvider{monitor: m}
+	depth := provider.NewDepthMetric("flinkAppController")
+	depth.Inc()
+	depth.Inc()
+	depth.Inc()
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, m, ReadyzPath, &response))
+	assert.Equal(t, CheckResult{Name: "queue", Healthy: false, Message: "3 items queued, more than the maximum of 2"},
+		response.Checks[1])
+
+	depth.Dec()
+	assert.Equal(t, http.StatusOK, get(t, m, ReadyzPath, &response))
+	assert.Equal(t, CheckResult{Name: "leader", Healthy: true, Message: "not the leader"}, response.Checks[2])
+}
+
+type fakeCache struct {
+	synced bool
+}
+
+func (c *fakeCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	return c.synced
+}
+
+func TestWaitForCacheSync(t *testing.T) {
+	m := newMonitor(clock.NewFakeClock(time.Now()), 0, time.Minute)
+	response := checkResponse{}
+
+	// the operator is only ready once every cache has synced
+	m.AddCache(&fakeCache{synced: true})
+	m.AddCache(&fakeCache{synced: false})
+	m.WaitForCacheSync(make(chan struct{}))
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, m, ReadyzPath, &response))
+
+	m = newMonitor(clock.NewFakeClock(time.Now()), 0, time.Minute)
+	m.AddCache(&fakeCache{synced: true})
+	m.AddCache(&fakeCache{synced: true})
+	m.WaitForCacheSync(make(chan struct{}))
+	assert.Equal(t, http.StatusOK, get(t, m, ReadyzPath, &response))
+}
+
+func TestHealthz(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	m := newMonitor(fakeClock, 0, time.Minute)
+	m.SetLeader(true)
+	depth := (&queueMetricsProvider{monitor: m}).NewDepthMetric("flinkAppController")
+	response := checkResponse{}
+
+	// an idle operator is healthy however long ago it last reconciled
+	fakeClock.Step(time.Hour)
+	assert.Equal(t, http.StatusOK, get(t, m, HealthzPath, &response))
+
+	// queued work is expected to be processed
+	depth.Inc()
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, m, HealthzPath, &response))
+	assert.Equal(t, "no successful reconciliation for 1h0m0s with 1 items queued", response.Checks[0].Message)
+
+	m.RecordReconcile(types.NamespacedName{Namespace: "ns", Name: "app"}, "Running", nil)
+	assert.Equal(t, http.StatusOK, get(t, m, HealthzPath, &response))
+
+	// failed reconciliations are not progress
+	fakeClock.Step(2 * time.Minute)
+	m.RecordReconcile(types.NamespacedName{Namespace: "ns", Name: "app"}, "Running", errors.New("failed"))
+	assert.Equal(t, http.StatusServiceUnavailable, get(t, m, HealthzPath, &response))
+
+	// replicas that are not the leader do not process work
+	m.SetLeader(false)
+	assert.Equal(t, http.StatusOK, get(t, m, HealthzPath, &response))
+}
+
+func TestDebugApps(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := newMonitor(fakeClock, 0, 0)
+	m.RecordReconcile(types.NamespacedName{Namespace: "ns", Name: "b"}, "Running", nil)
+	fakeClock.Step(time.Minute)
+	m.RecordReconcile(types.NamespacedName{Namespace: "ns", Name: "b"}, "Running", errors.New("failed"))
+	m.RecordReconcile(types.NamespacedName{Namespace: "ns", Name: "a"}, "ClusterStarting", nil)
+	m.RecordReconcile(types.NamespacedName{Namespace: "ns", Name: "c"}, "Running", nil)
+	m.RemoveApp(types.NamespacedName{Namespace: "ns", Name: "c"})
+
+	var apps []AppStatus
+	assert.Equal(t, http.StatusOK, get(t, m, DebugAppsPath, &apps))
+	assert.Equal(t, 2, len(apps))
+	assert.Equal(t, "a", apps[0].Name)
+	assert.Equal(t, "ClusterStarting", apps[0].Phase)
+	assert.Equal(t, "b", apps[1].Name)
+	assert.Equal(t, "failed", apps[1].LastError)
+	assert.True(t, fakeClock.Now().Equal(apps[1].LastReconciled))
+	assert.True(t, fakeClock.Now().Add(-time.Minute).Equal(*apps[1].LastSuccessfulSync))
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Tracks the depth of the work queues of the controllers. Only the depth is of interest, the other metrics are
+// discarded.
+type queueMetricsProvider struct {
+	monitor *Monitor
+}
+
+type queueDepth struct {
+	monitor *Monitor
+	queue   string
+}
+
+func (d *queueDepth) Inc() {
+	d.monitor.addQueueDepth(d.queue, 1)
+}
+
+func (d *queueDepth) Dec() {
+	d.monitor.addQueueDepth(d.queue, -1)
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Observe(float64) {}
+
+func (p *queueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return &queueDepth{monitor: p.monitor, queue: name}
+}
+
+func (p *queueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return noopMetric{}
+}
+
+func (p *queueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return noopMetric{}
+}
+
+func (p *queueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return noopMetric{}
+}
+
+func (p *queueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return noopMetric{}
+}
+
+// Tracks the depth of all work queues created from now on. This has to be called before the controllers are created,
+// and only once, as client-go only accepts the first provider.
+func (m *Monitor) WatchQueues() {
+	workqueue.SetProvider(&queueMetricsProvider{monitor: m})
+}
@@ -2,52 +2,162 @@ package k8
 
 import (
 	"context"
+	"reflect"
 
+	"github.com/lyft/flinkk8soperator/pkg/controller/tracing"
 	"github.com/lyft/flytestdlib/logger"
 	v1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
+	policyV1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	unstructuredV1 "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 const (
-	Deployment = "Deployment"
-	Pod        = "Pod"
-	Service    = "Service"
-	Endpoints  = "Endpoints"
-	Ingress    = "Ingress"
+	Deployment            = "Deployment"
+	Pod                   = "Pod"
+	Service               = "Service"
+	Endpoints             = "Endpoints"
+	Ingress               = "Ingress"
+	Secret                = "Secret"
+	PodDisruptionBudget   = "PodDisruptionBudget"
+	NetworkPolicy         = "NetworkPolicy"
+	ConfigMap             = "ConfigMap"
+	ServiceAccount        = "ServiceAccount"
+	Role                  = "Role"
+	RoleBinding           = "RoleBinding"
+	StatefulSet           = "StatefulSet"
+	PersistentVolumeClaim = "PersistentVolumeClaim"
+)
+
+const (
+	kindKey = tracing.Key("k8s.kind")
+	nameKey = tracing.Key("k8s.name")
 )
 
 type ClusterInterface interface {
 	// Tries to fetch the value from the controller runtime manager cache, if it does not exist, call API server
 	GetDeploymentsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error)
 
+	// Tries to fetch the value from the controller runtime manager cache, if it does not exist, call API server
+	GetStatefulSetsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*v1.StatefulSetList, error)
+
+	// Lists the persistent volume claims matching the labels directly from the API server, as they are not watched
+	// by the operator either
+	GetPersistentVolumeClaimsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.PersistentVolumeClaimList, error)
+
+	// Tries to fetch the value from the controller runtime manager cache, if it does not exist, call API server
+	GetServicesWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.ServiceList, error)
+
+	// Tries to fetch the value from the controller runtime manager cache, if it does not exist, call API server
+	GetConfigMapsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.ConfigMapList, error)
+
+	// Tries to fetch the value from the controller runtime manager cache, if it does not exist, call API server
+	GetPodDisruptionBudgetsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*policyV1beta1.PodDisruptionBudgetList, error)
+
+	// Lists the pods matching the labels directly from the API server. Pods are not watched by the operator, so
+	// they are not available in the cache.
+	GetPodsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*coreV1.PodList, error)
+
 	// Tries to fetch the value from the controller runtime manager cache, if it does not exist, call API server
 	GetService(ctx context.Context, namespace string, name string) (*coreV1.Service, error)
 
+	// Fetches the secret directly from the API server, so that the operator does not need to watch secrets
+	GetSecret(ctx context.Context, namespace string, name string) (*coreV1.Secret, error)
+
+	// Fetches the config map directly from the API server, so that the operator does not need to watch config maps
+	GetConfigMap(ctx context.Context, namespace string, name string) (*coreV1.ConfigMap, error)
+
+	// Replaces the object with its latest version from the API server, bypassing the cache, so that it can be
+	// modified and updated within RetryOnConflict
+	GetK8Object(ctx context.Context, object runtime.Object) error
+
 	CreateK8Object(ctx context.Context, object runtime.Object) error
 	UpdateK8Object(ctx context.Context, object runtime.Object) error
+
+	// Writes the changes from original to modified as a merge patch, which unlike an update does not conflict with
+	// concurrent changes to other fields. Nothing is sent if there are no changes. On success, modified is replaced
+	// with the object returned by the API server.
+	PatchK8Object(ctx context.Context, original runtime.Object, modified runtime.Object) error
+
 	DeleteK8Object(ctx context.Context, object runtime.Object) error
+
+	// Returns whether the API server serves the kind in the given group version, according to the API discovery
+	// performed when the operator started
+	IsKindServed(gvk schema.GroupVersionKind) (bool, error)
 }
 
-func NewK8Cluster(mgr manager.Manager) ClusterInterface {
-	return &Cluster{
-		cache:  mgr.GetCache(),
-		client: mgr.GetClient(),
+func NewK8Cluster(mgr manager.Manager) (ClusterInterface, error) {
+	return newK8Cluster(mgr, mgr.GetCache(), true)
+}
+
+// Creates a cluster that reads the watched objects from the given cache rather than the cache of the manager. Objects
+// missing from the cache are read from the API server.
+func NewK8ClusterWithCache(mgr manager.Manager, objectCache cache.Cache) (ClusterInterface, error) {
+	return newK8Cluster(mgr, objectCache, false)
+}
+
+func newK8Cluster(mgr manager.Manager, objectCache cache.Cache, useManagerClient bool) (ClusterInterface, error) {
+	// reads through the client of the manager are served from the cache, which starts an informer for every kind
+	// that is read. Objects that are not watched are read through a client that always calls the API server.
+	reader, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+
+	// the client of the controller runtime cannot patch, so patches are sent through the dynamic client
+	dynamicClient, err := dynamic.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	objectClient := reader
+	if useManagerClient {
+		objectClient = mgr.GetClient()
 	}
+
+	return &Cluster{
+		cache:         objectCache,
+		client:        objectClient,
+		reader:        reader,
+		dynamicClient: dynamicClient,
+		mapper:        mgr.GetRESTMapper(),
+		scheme:        mgr.GetScheme(),
+	}, nil
 }
 
 type Cluster struct {
-	cache  cache.Cache
-	client client.Client
+	cache         cache.Cache
+	client        client.Client
+	reader        client.Reader
+	dynamicClient dynamic.Interface
+	mapper        meta.RESTMapper
+	scheme        *runtime.Scheme
+}
+
+// Starts a span for an operation on a single Kubernetes object
+func startObjectSpan(ctx context.Context, name string, object runtime.Object) (context.Context, *tracing.Span) {
+	attrs := []tracing.KeyValue{kindKey.String(object.GetObjectKind().GroupVersionKind().Kind)}
+	if objectMeta, err := meta.Accessor(object); err == nil {
+		attrs = append(attrs, nameKey.String(objectMeta.GetName()))
+	}
+	return tracing.StartSpan(ctx, name, attrs...)
 }
 
-func (k *Cluster) GetService(ctx context.Context, namespace string, name string) (*coreV1.Service, error) {
+func (k *Cluster) GetService(ctx context.Context, namespace string, name string) (_ *coreV1.Service, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetService", kindKey.String(Service), nameKey.String(name))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	service := &coreV1.Service{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: coreV1.SchemeGroupVersion.String(),
@@ -58,7 +168,7 @@ func (k *Cluster) GetService(ctx context.Context, namespace string, name string)
 		Name:      name,
 		Namespace: namespace,
 	}
-	err := k.cache.Get(ctx, key, service)
+	err = k.cache.Get(ctx, key, service)
 	if err != nil {
 		if IsK8sObjectDoesNotExist(err) {
 			err := k.client.Get(ctx, key, service)
@@ -73,7 +183,54 @@ func (k *Cluster) GetService(ctx context.Context, namespace string, name string)
 	return service, nil
 }
 
-func (k *Cluster) GetDeploymentsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error) {
+func (k *Cluster) GetSecret(ctx context.Context, namespace string, name string) (_ *coreV1.Secret, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetSecret", kindKey.String(Secret), nameKey.String(name))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	secret := &coreV1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       Secret,
+		},
+	}
+	key := types.NamespacedName{
+		Name:      name,
+		Namespace: namespace,
+	}
+	err = k.reader.Get(ctx, key, secret)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get secret %v", err)
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (k *Cluster) GetConfigMap(ctx context.Context, namespace string, name string) (_ *coreV1.ConfigMap, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetConfigMap", kindKey.String(ConfigMap), nameKey.String(name))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	configMap := &coreV1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       ConfigMap,
+		},
+	}
+	key := types.NamespacedName{
+		Name:      name,
+		Namespace: namespace,
+	}
+	err = k.reader.Get(ctx, key, configMap)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get config map %v", err)
+		return nil, err
+	}
+	return configMap, nil
+}
+
+func (k *Cluster) GetDeploymentsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (_ *v1.DeploymentList, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetDeploymentsWithLabel", kindKey.String(Deployment))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	deploymentList := &v1.DeploymentList{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: v1.SchemeGroupVersion.String(),
@@ -82,9 +239,10 @@ func (k *Cluster) GetDeploymentsWithLabel(ctx context.Context, namespace string,
 	}
 	labelSelector := labels.SelectorFromSet(labelMap)
 	options := &client.ListOptions{
+		Namespace:     namespace,
 		LabelSelector: labelSelector,
 	}
-	err := k.cache.List(ctx, options, deploymentList)
+	err = k.cache.List(ctx, options, deploymentList)
 	if err != nil {
 		if IsK8sObjectDoesNotExist(err) {
 			err := k.client.List(ctx, options, deploymentList)
@@ -92,6 +250,7 @@ func (k *Cluster) GetDeploymentsWithLabel(ctx context.Context, namespace string,
 				logger.Warnf(ctx, "Failed to list deployments %v", err)
 				return nil, err
 			}
+			return deploymentList, nil
 		}
 		logger.Warnf(ctx, "Failed to list deployments from cache %v", err)
 		return nil, err
@@ -99,17 +258,265 @@ func (k *Cluster) GetDeploymentsWithLabel(ctx context.Context, namespace string,
 	return deploymentList, nil
 }
 
-func (k *Cluster) CreateK8Object(ctx context.Context, object runtime.Object) error {
+func (k *Cluster) GetPodsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (_ *coreV1.PodList, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetPodsWithLabel", kindKey.String(Pod))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	podList := &coreV1.PodList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       Pod,
+		},
+	}
+	options := &client.ListOptions{
+		Namespace:     namespace,
+		LabelSelector: labels.SelectorFromSet(labelMap),
+	}
+	err = k.reader.List(ctx, options, podList)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to list pods %v", err)
+		return nil, err
+	}
+	return podList, nil
+}
+
+func (k *Cluster) GetStatefulSetsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (_ *v1.StatefulSetList, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetStatefulSetsWithLabel", kindKey.String(StatefulSet))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	statefulSetList := &v1.StatefulSetList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       StatefulSet,
+		},
+	}
+	options := &client.ListOptions{
+		Namespace:     namespace,
+		LabelSelector: labels.SelectorFromSet(labelMap),
+	}
+	err = k.cache.List(ctx, options, statefulSetList)
+	if err != nil {
+		if IsK8sObjectDoesNotExist(err) {
+			err := k.client.List(ctx, options, statefulSetList)
+			if err != nil {
+				logger.Warnf(ctx, "Failed to list stateful sets %v", err)
+				return nil, err
+			}
+			return statefulSetList, nil
+		}
+		logger.Warnf(ctx, "Failed to list stateful sets from cache %v", err)
+		return nil, err
+	}
+	return statefulSetList, nil
+}
+
+func (k *Cluster) GetPersistentVolumeClaimsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (_ *coreV1.PersistentVolumeClaimList, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetPersistentVolumeClaimsWithLabel", kindKey.String(PersistentVolumeClaim))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	claimList := &coreV1.PersistentVolumeClaimList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       PersistentVolumeClaim,
+		},
+	}
+	options := &client.ListOptions{
+		Namespace:     namespace,
+		LabelSelector: labels.SelectorFromSet(labelMap),
+	}
+	err = k.reader.List(ctx, options, claimList)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to list persistent volume claims %v", err)
+		return nil, err
+	}
+	return claimList, nil
+}
+
+func (k *Cluster) GetServicesWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (_ *coreV1.ServiceList, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetServicesWithLabel", kindKey.String(Service))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	serviceList := &coreV1.ServiceList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       Service,
+		},
+	}
+	options := &client.ListOptions{
+		Namespace:     namespace,
+		LabelSelector: labels.SelectorFromSet(labelMap),
+	}
+	err = k.cache.List(ctx, options, serviceList)
+	if err != nil {
+		if IsK8sObjectDoesNotExist(err) {
+			err := k.client.List(ctx, options, serviceList)
+			if err != nil {
+				logger.Warnf(ctx, "Failed to list services %v", err)
+				return nil, err
+			}
+			return serviceList, nil
+		}
+		logger.Warnf(ctx, "Failed to list services from cache %v", err)
+		return nil, err
+	}
+	return serviceList, nil
+}
+
+func (k *Cluster) GetConfigMapsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (_ *coreV1.ConfigMapList, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetConfigMapsWithLabel", kindKey.String(ConfigMap))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	configMapList := &coreV1.ConfigMapList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: coreV1.SchemeGroupVersion.String(),
+			Kind:       ConfigMap,
+		},
+	}
+	options := &client.ListOptions{
+		Namespace:     namespace,
+		LabelSelector: labels.SelectorFromSet(labelMap),
+	}
+	err = k.cache.List(ctx, options, configMapList)
+	if err != nil {
+		if IsK8sObjectDoesNotExist(err) {
+			err := k.client.List(ctx, options, configMapList)
+			if err != nil {
+				logger.Warnf(ctx, "Failed to list config maps %v", err)
+				return nil, err
+			}
+			return configMapList, nil
+		}
+		logger.Warnf(ctx, "Failed to list config maps from cache %v", err)
+		return nil, err
+	}
+	return configMapList, nil
+}
+
+func (k *Cluster) GetPodDisruptionBudgetsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (_ *policyV1beta1.PodDisruptionBudgetList, err error) {
+	ctx, span := tracing.StartSpan(ctx, "k8.GetPodDisruptionBudgetsWithLabel", kindKey.String(PodDisruptionBudget))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	pdbList := &policyV1beta1.PodDisruptionBudgetList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: policyV1beta1.SchemeGroupVersion.String(),
+			Kind:       PodDisruptionBudget,
+		},
+	}
+	options := &client.ListOptions{
+		Namespace:     namespace,
+		LabelSelector: labels.SelectorFromSet(labelMap),
+	}
+	err = k.cache.List(ctx, options, pdbList)
+	if err != nil {
+		if IsK8sObjectDoesNotExist(err) {
+			err := k.client.List(ctx, options, pdbList)
+			if err != nil {
+				logger.Warnf(ctx, "Failed to list pod disruption budgets %v", err)
+				return nil, err
+			}
+			return pdbList, nil
+		}
+		logger.Warnf(ctx, "Failed to list pod disruption budgets from cache %v", err)
+		return nil, err
+	}
+	return pdbList, nil
+}
+
+func (k *Cluster) CreateK8Object(ctx context.Context, object runtime.Object) (err error) {
+	ctx, span := startObjectSpan(ctx, "k8.CreateK8Object", object)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	objCreate := object.DeepCopyObject()
 	return k.client.Create(ctx, objCreate)
 }
 
-func (k *Cluster) UpdateK8Object(ctx context.Context, object runtime.Object) error {
+func (k *Cluster) UpdateK8Object(ctx context.Context, object runtime.Object) (err error) {
+	ctx, span := startObjectSpan(ctx, "k8.UpdateK8Object", object)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	objUpdate := object.DeepCopyObject()
 	return k.client.Update(ctx, objUpdate)
 }
 
-func (k *Cluster) DeleteK8Object(ctx context.Context, object runtime.Object) error {
+// Returns the dynamic client for the resource of the object, and the name of the object
+func (k *Cluster) getResourceClient(object runtime.Object) (dynamic.ResourceInterface, string, error) {
+	gvk, err := apiutil.GVKForObject(object, k.scheme)
+	if err != nil {
+		return nil, "", err
+	}
+	mapping, err := k.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, "", err
+	}
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		return nil, "", err
+	}
+	return k.dynamicClient.Resource(mapping.Resource).Namespace(objectMeta.GetNamespace()), objectMeta.GetName(), nil
+}
+
+// Replaces the contents of the object with the unstructured object returned by the API server
+func setFromUnstructured(object runtime.Object, unstructured map[string]interface{}) error {
+	if u, ok := object.(*unstructuredV1.Unstructured); ok {
+		u.SetUnstructuredContent(unstructured)
+		return nil
+	}
+	// converting into the object would keep the fields that are no longer set, so it is converted into a new one
+	latest := reflect.New(reflect.TypeOf(object).Elem()).Interface()
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructured, latest); err != nil {
+		return err
+	}
+	reflect.ValueOf(object).Elem().Set(reflect.ValueOf(latest).Elem())
+	return nil
+}
+
+func (k *Cluster) GetK8Object(ctx context.Context, object runtime.Object) (err error) {
+	ctx, span := startObjectSpan(ctx, "k8.GetK8Object", object)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	resourceClient, name, err := k.getResourceClient(object)
+	if err != nil {
+		return err
+	}
+	latest, err := resourceClient.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return setFromUnstructured(object, latest.UnstructuredContent())
+}
+
+func (k *Cluster) PatchK8Object(ctx context.Context, original runtime.Object, modified runtime.Object) (err error) {
+	ctx, span := startObjectSpan(ctx, "k8.PatchK8Object", modified)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	patch, err := CreateMergePatch(original, modified)
+	if err != nil || patch == nil {
+		return err
+	}
+
+	resourceClient, name, err := k.getResourceClient(modified)
+	if err != nil {
+		return err
+	}
+	patched, err := resourceClient.Patch(name, types.MergePatchType, patch)
+	if err != nil {
+		return err
+	}
+	return setFromUnstructured(modified, patched.UnstructuredContent())
+}
+
+func (k *Cluster) DeleteK8Object(ctx context.Context, object runtime.Object) (err error) {
+	ctx, span := startObjectSpan(ctx, "k8.DeleteK8Object", object)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	objDelete := object.DeepCopyObject()
 	return k.client.Delete(ctx, objDelete)
 }
+
+func (k *Cluster) IsKindServed(gvk schema.GroupVersionKind) (bool, error) {
+	_, err := k.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
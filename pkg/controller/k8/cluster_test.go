@@ -0,0 +1,52 @@
+package k8
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A cache that has not synced the objects that are listed
+type notFoundCache struct {
+	cache.Cache
+}
+
+func (c *notFoundCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return k8sErrors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (c *notFoundCache) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	return k8sErrors.NewNotFound(schema.GroupResource{}, "")
+}
+
+type listClient struct {
+	client.Client
+	list func(list runtime.Object) error
+}
+
+func (c *listClient) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	return c.list(list)
+}
+
+func TestGetStatefulSetsWithLabelFallsBackToClient(t *testing.T) {
+	statefulSet := v1.StatefulSet{}
+	statefulSet.Name = "app-name-hash-tm"
+	cluster := Cluster{
+		cache: &notFoundCache{},
+		client: &listClient{list: func(list runtime.Object) error {
+			list.(*v1.StatefulSetList).Items = []v1.StatefulSet{statefulSet}
+			return nil
+		}},
+	}
+
+	statefulSets, err := cluster.GetStatefulSetsWithLabel(context.Background(), "flink", map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, []v1.StatefulSet{statefulSet}, statefulSets.Items)
+}
@@ -3,23 +3,48 @@ package mock
 import (
 	"context"
 
+	"github.com/lyft/flinkk8soperator/pkg/controller/k8"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type GetDeploymentsWithLabelFunc func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error)
+type GetPodsWithLabelFunc func(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.PodList, error)
+type GetConfigMapsWithLabelFunc func(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.ConfigMapList, error)
+type GetStatefulSetsWithLabelFunc func(ctx context.Context, namespace string, labelMap map[string]string) (*v1.StatefulSetList, error)
+type GetPersistentVolumeClaimsWithLabelFunc func(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.PersistentVolumeClaimList, error)
+type GetServicesWithLabelFunc func(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.ServiceList, error)
+type GetPodDisruptionBudgetsWithLabelFunc func(ctx context.Context, namespace string, labelMap map[string]string) (*policyv1beta1.PodDisruptionBudgetList, error)
 type CreateK8ObjectFunc func(ctx context.Context, object runtime.Object) error
 type GetServiceFunc func(ctx context.Context, namespace string, name string) (*corev1.Service, error)
+type GetSecretFunc func(ctx context.Context, namespace string, name string) (*corev1.Secret, error)
+type GetConfigMapFunc func(ctx context.Context, namespace string, name string) (*corev1.ConfigMap, error)
+type GetK8ObjectFunc func(ctx context.Context, object runtime.Object) error
 type UpdateK8ObjectFunc func(ctx context.Context, object runtime.Object) error
+type PatchK8ObjectFunc func(ctx context.Context, original runtime.Object, modified runtime.Object) error
 type DeleteK8ObjectFunc func(ctx context.Context, object runtime.Object) error
+type IsKindServedFunc func(gvk schema.GroupVersionKind) (bool, error)
 
 type K8Cluster struct {
-	GetDeploymentsWithLabelFunc GetDeploymentsWithLabelFunc
-	GetServiceFunc              GetServiceFunc
-	CreateK8ObjectFunc          CreateK8ObjectFunc
-	UpdateK8ObjectFunc          UpdateK8ObjectFunc
-	DeleteK8ObjectFunc          DeleteK8ObjectFunc
+	GetDeploymentsWithLabelFunc            GetDeploymentsWithLabelFunc
+	GetPodsWithLabelFunc                   GetPodsWithLabelFunc
+	GetStatefulSetsWithLabelFunc           GetStatefulSetsWithLabelFunc
+	GetPersistentVolumeClaimsWithLabelFunc GetPersistentVolumeClaimsWithLabelFunc
+	GetServicesWithLabelFunc               GetServicesWithLabelFunc
+	GetConfigMapsWithLabelFunc             GetConfigMapsWithLabelFunc
+	GetPodDisruptionBudgetsWithLabelFunc   GetPodDisruptionBudgetsWithLabelFunc
+	GetServiceFunc                         GetServiceFunc
+	GetSecretFunc                          GetSecretFunc
+	GetConfigMapFunc                       GetConfigMapFunc
+	CreateK8ObjectFunc                     CreateK8ObjectFunc
+	GetK8ObjectFunc                        GetK8ObjectFunc
+	UpdateK8ObjectFunc                     UpdateK8ObjectFunc
+	PatchK8ObjectFunc                      PatchK8ObjectFunc
+	DeleteK8ObjectFunc                     DeleteK8ObjectFunc
+	IsKindServedFunc                       IsKindServedFunc
 }
 
 func (m *K8Cluster) GetDeploymentsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*v1.DeploymentList, error) {
@@ -29,6 +54,48 @@ func (m *K8Cluster) GetDeploymentsWithLabel(ctx context.Context, namespace strin
 	return nil, nil
 }
 
+func (m *K8Cluster) GetPodsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.PodList, error) {
+	if m.GetPodsWithLabelFunc != nil {
+		return m.GetPodsWithLabelFunc(ctx, namespace, labelMap)
+	}
+	return nil, nil
+}
+
+func (m *K8Cluster) GetStatefulSetsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*v1.StatefulSetList, error) {
+	if m.GetStatefulSetsWithLabelFunc != nil {
+		return m.GetStatefulSetsWithLabelFunc(ctx, namespace, labelMap)
+	}
+	return nil, nil
+}
+
+func (m *K8Cluster) GetPersistentVolumeClaimsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.PersistentVolumeClaimList, error) {
+	if m.GetPersistentVolumeClaimsWithLabelFunc != nil {
+		return m.GetPersistentVolumeClaimsWithLabelFunc(ctx, namespace, labelMap)
+	}
+	return nil, nil
+}
+
+func (m *K8Cluster) GetServicesWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.ServiceList, error) {
+	if m.GetServicesWithLabelFunc != nil {
+		return m.GetServicesWithLabelFunc(ctx, namespace, labelMap)
+	}
+	return nil, nil
+}
+
+func (m *K8Cluster) GetConfigMapsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*corev1.ConfigMapList, error) {
+	if m.GetConfigMapsWithLabelFunc != nil {
+		return m.GetConfigMapsWithLabelFunc(ctx, namespace, labelMap)
+	}
+	return nil, nil
+}
+
+func (m *K8Cluster) GetPodDisruptionBudgetsWithLabel(ctx context.Context, namespace string, labelMap map[string]string) (*policyv1beta1.PodDisruptionBudgetList, error) {
+	if m.GetPodDisruptionBudgetsWithLabelFunc != nil {
+		return m.GetPodDisruptionBudgetsWithLabelFunc(ctx, namespace, labelMap)
+	}
+	return nil, nil
+}
+
 func (m *K8Cluster) GetService(ctx context.Context, namespace string, name string) (*corev1.Service, error) {
 	if m.GetServiceFunc != nil {
 		return m.GetServiceFunc(ctx, namespace, name)
@@ -36,6 +103,20 @@ func (m *K8Cluster) GetService(ctx context.Context, namespace string, name strin
 	return nil, nil
 }
 
+func (m *K8Cluster) GetSecret(ctx context.Context, namespace string, name string) (*corev1.Secret, error) {
+	if m.GetSecretFunc != nil {
+		return m.GetSecretFunc(ctx, namespace, name)
+	}
+	return nil, nil
+}
+
+func (m *K8Cluster) GetConfigMap(ctx context.Context, namespace string, name string) (*corev1.ConfigMap, error) {
+	if m.GetConfigMapFunc != nil {
+		return m.GetConfigMapFunc(ctx, namespace, name)
+	}
+	return nil, nil
+}
+
 func (m *K8Cluster) CreateK8Object(ctx context.Context, object runtime.Object) error {
 	if m.CreateK8ObjectFunc != nil {
 		return m.CreateK8ObjectFunc(ctx, object)
@@ -50,9 +131,37 @@ func (m *K8Cluster) UpdateK8Object(ctx context.Context, object runtime.Object) e
 	return nil
 }
 
+func (m *K8Cluster) GetK8Object(ctx context.Context, object runtime.Object) error {
+	if m.GetK8ObjectFunc != nil {
+		return m.GetK8ObjectFunc(ctx, object)
+	}
+	return nil
+}
+
+// Without a PatchK8ObjectFunc, patches with changes are passed to the UpdateK8ObjectFunc as updates of the modified
+// object, so that tests can check the result of writes independently of how they are made
+func (m *K8Cluster) PatchK8Object(ctx context.Context, original runtime.Object, modified runtime.Object) error {
+	if m.PatchK8ObjectFunc != nil {
+		return m.PatchK8ObjectFunc(ctx, original, modified)
+	}
+	patch, err := k8.CreateMergePatch(original, modified)
+	if err != nil || patch == nil {
+		return err
+	}
+	return m.UpdateK8Object(ctx, modified)
+}
+
 func (m *K8Cluster) DeleteK8Object(ctx context.Context, object runtime.Object) error {
 	if m.DeleteK8ObjectFunc != nil {
 		return m.DeleteK8ObjectFunc(ctx, object)
 	}
 	return nil
 }
+
+// Unless overridden, every kind is served by the mock cluster
+func (m *K8Cluster) IsKindServed(gvk schema.GroupVersionKind) (bool, error) {
+	if m.IsKindServedFunc != nil {
+		return m.IsKindServedFunc(gvk)
+	}
+	return true, nil
+}
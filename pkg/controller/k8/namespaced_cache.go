@@ -0,0 +1,312 @@
+package k8
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// A cache that watches namespaced objects in a set of namespaces only, with a separate cache per namespace, rather
+// than in every namespace of the cluster. Cluster scoped objects, such as namespaces, are cached cluster-wide.
+// Namespaces can be added and removed while the cache is running.
+//
+// The informers of namespaced objects differ per namespace, so they are not available through GetInformer; events
+// are received by registering a handler with AddEventHandler (see NamespacedKind).
+type NamespacedCache struct {
+	newCache     func(namespace string) (cache.Cache, error)
+	clusterCache cache.Cache
+	scheme       *runtime.Scheme
+	mapper       meta.RESTMapper
+
+	lock       sync.RWMutex
+	namespaces map[string]*namespaceCache
+	handlers   []namespacedEventHandler
+	indexes    []namespacedIndex
+	stop       <-chan struct{}
+}
+
+type namespaceCache struct {
+	cache.Cache
+	stop chan struct{}
+}
+
+type namespacedEventHandler struct {
+	object  runtime.Object
+	handler toolscache.ResourceEventHandler
+}
+
+type namespacedIndex struct {
+	object       runtime.Object
+	field        string
+	extractValue client.IndexerFunc
+}
+
+// Creates a cache without any namespaces. The namespace of opts is ignored.
+func NewNamespacedCache(config *rest.Config, opts cache.Options) (*NamespacedCache, error) {
+	if opts.Mapper == nil {
+		mapper, err := apiutil.NewDiscoveryRESTMapper(config)
+		if err != nil {
+			return nil, err
+		}
+		opts.Mapper = mapper
+	}
+
+	newCache := func(namespace string) (cache.Cache, error) {
+		namespaceOpts := opts
+		namespaceOpts.Namespace = namespace
+		return cache.New(config, namespaceOpts)
+	}
+	clusterCache, err := newCache("")
+	if err != nil {
+		return nil, err
+	}
+
+	return &NamespacedCache{
+		newCache:     newCache,
+		clusterCache: clusterCache,
+		scheme:       opts.Scheme,
+		mapper:       opts.Mapper,
+		namespaces:   map[string]*namespaceCache{},
+	}, nil
+}
+
+// Starts caching the objects in the namespace. The event handlers and indexes registered so far are added to the
+// informers of the namespace.
+func (c *NamespacedCache) AddNamespace(namespace string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.namespaces[namespace]; ok {
+		return nil
+	}
+
+	objectCache, err := c.newCache(namespace)
+	if err != nil {
+		return err
+	}
+	for _, index := range c.indexes {
+		if err := objectCache.IndexField(index.object, index.field, index.extractValue); err != nil {
+			return err
+		}
+	}
+	for _, handler := range c.handlers {
+		informer, err := objectCache.GetInformer(handler.object)
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(handler.handler)
+	}
+
+	nsCache := &namespaceCache{Cache: objectCache, stop: make(chan struct{})}
+	c.namespaces[namespace] = nsCache
+	if c.stop != nil {
+		c.startNamespace(nsCache, c.stop)
+	}
+	return nil
+}
+
+// Stops caching the objects in the namespace
+func (c *NamespacedCache) RemoveNamespace(namespace string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if nsCache, ok := c.namespaces[namespace]; ok {
+		close(nsCache.stop)
+		delete(c.namespaces, namespace)
+	}
+}
+
+// Registers a handler for the events of a namespaced kind, in the namespaces cached now and those added later
+func (c *NamespacedCache) AddEventHandler(object runtime.Object, handler toolscache.ResourceEventHandler) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, nsCache := range c.namespaces {
+		informer, err := nsCache.GetInformer(object)
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(handler)
+	}
+	c.handlers = append(c.handlers, namespacedEventHandler{object: object, handler: handler})
+	return nil
+}
+
+func (c *NamespacedCache) startNamespace(nsCache *namespaceCache, stop <-chan struct{}) {
+	namespaceStop := make(chan struct{})
+	go func() {
+		defer close(namespaceStop)
+		select {
+		case <-stop:
+		case <-nsCache.stop:
+		}
+	}()
+	go func() {
+		_ = nsCache.Start(namespaceStop)
+	}()
+}
+
+func (c *NamespacedCache) getNamespaceCaches() []*namespaceCache {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	caches := make([]*namespaceCache, 0, len(c.namespaces))
+	for _, nsCache := range c.namespaces {
+		caches = append(caches, nsCache)
+	}
+	return caches
+}
+
+func (c *NamespacedCache) getNamespaceCache(namespace string) (*namespaceCache, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	nsCache, ok := c.namespaces[namespace]
+	return nsCache, ok
+}
+
+func (c *NamespacedCache) getMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	return c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+func (c *NamespacedCache) isNamespaced(gvk schema.GroupVersionKind) (bool, error) {
+	mapping, err := c.getMapping(gvk)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// Objects in namespaces that are not cached are reported as not found, so that callers fall back to the API server
+func (c *NamespacedCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if key.Namespace == "" {
+		return c.clusterCache.Get(ctx, key, obj)
+	}
+	if nsCache, ok := c.getNamespaceCache(key.Namespace); ok {
+		return nsCache.Get(ctx, key, obj)
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	mapping, err := c.getMapping(gvk)
+	if err != nil {
+		return err
+	}
+	return k8sErrors.NewNotFound(mapping.Resource.GroupResource(), key.Name)
+}
+
+// Lists without a namespace are aggregated over all cached namespaces
+func (c *NamespacedCache) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(list, c.scheme)
+	if err != nil {
+		return err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	if opts != nil && opts.Namespace != "" {
+		if nsCache, ok := c.getNamespaceCache(opts.Namespace); ok {
+			return nsCache.List(ctx, opts, list)
+		}
+		mapping, err := c.getMapping(gvk)
+		if err != nil {
+			return err
+		}
+		return k8sErrors.NewNotFound(mapping.Resource.GroupResource(), "")
+	}
+
+	namespaced, err := c.isNamespaced(gvk)
+	if err != nil {
+		return err
+	}
+	if !namespaced {
+		return c.clusterCache.List(ctx, opts, list)
+	}
+
+	var items []runtime.Object
+	for _, nsCache := range c.getNamespaceCaches() {
+		nsList := list.DeepCopyObject()
+		if err := nsCache.List(ctx, opts, nsList); err != nil {
+			return err
+		}
+		nsItems, err := meta.ExtractList(nsList)
+		if err != nil {
+			return err
+		}
+		items = append(items, nsItems...)
+	}
+	return meta.SetList(list, items)
+}
+
+// Only the informers of cluster scoped objects are available, see AddEventHandler for namespaced objects
+func (c *NamespacedCache) GetInformer(obj runtime.Object) (toolscache.SharedIndexInformer, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetInformerForKind(gvk)
+}
+
+func (c *NamespacedCache) GetInformerForKind(gvk schema.GroupVersionKind) (toolscache.SharedIndexInformer, error) {
+	namespaced, err := c.isNamespaced(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if namespaced {
+		return nil, errors.Errorf("%v is cached per namespace and has no cluster-wide informer", gvk)
+	}
+	return c.clusterCache.GetInformerForKind(gvk)
+}
+
+func (c *NamespacedCache) Start(stop <-chan struct{}) error {
+	c.lock.Lock()
+	c.stop = stop
+	for _, nsCache := range c.namespaces {
+		c.startNamespace(nsCache, stop)
+	}
+	c.lock.Unlock()
+	return c.clusterCache.Start(stop)
+}
+
+func (c *NamespacedCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	if !c.clusterCache.WaitForCacheSync(stop) {
+		return false
+	}
+	for _, nsCache := range c.getNamespaceCaches() {
+		if !nsCache.WaitForCacheSync(stop) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *NamespacedCache) IndexField(obj runtime.Object, field string, extractValue client.IndexerFunc) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	namespaced, err := c.isNamespaced(gvk)
+	if err != nil {
+		return err
+	}
+	if !namespaced {
+		return c.clusterCache.IndexField(obj, field, extractValue)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, nsCache := range c.namespaces {
+		if err := nsCache.IndexField(obj, field, extractValue); err != nil {
+			return err
+		}
+	}
+	c.indexes = append(c.indexes, namespacedIndex{object: obj, field: field, extractValue: extractValue})
+	return nil
+}
@@ -0,0 +1,101 @@
+package k8
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A cache holding the deployments of a single namespace
+type deploymentCache struct {
+	cache.Cache
+	deployments []v1.Deployment
+}
+
+func (c *deploymentCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	for _, deployment := range c.deployments {
+		if deployment.Namespace == key.Namespace && deployment.Name == key.Name {
+			deployment.DeepCopyInto(obj.(*v1.Deployment))
+			return nil
+		}
+	}
+	return k8sErrors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (c *deploymentCache) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	list.(*v1.DeploymentList).Items = append([]v1.Deployment(nil), c.deployments...)
+	return nil
+}
+
+func newTestNamespacedCache() *NamespacedCache {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{v1.SchemeGroupVersion, coreV1.SchemeGroupVersion})
+	mapper.Add(v1.SchemeGroupVersion.WithKind(Deployment), meta.RESTScopeNamespace)
+	mapper.Add(coreV1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+
+	return &NamespacedCache{
+		newCache: func(namespace string) (cache.Cache, error) {
+			deployment := v1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "app-name-hash"}}
+			return &deploymentCache{deployments: []v1.Deployment{deployment}}, nil
+		},
+		clusterCache: &notFoundCache{},
+		scheme:       scheme.Scheme,
+		mapper:       mapper,
+		namespaces:   map[string]*namespaceCache{},
+	}
+}
+
+func TestNamespacedCache(t *testing.T) {
+	ctx := context.Background()
+	namespacedCache := newTestNamespacedCache()
+	assert.Nil(t, namespacedCache.AddNamespace("team-a"))
+	assert.Nil(t, namespacedCache.AddNamespace("team-b"))
+
+	deployment := &v1.Deployment{}
+	err := namespacedCache.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "app-name-hash"}, deployment)
+	assert.Nil(t, err)
+	assert.Equal(t, "team-a", deployment.Namespace)
+
+	// objects in namespaces that are not cached are not found
+	err = namespacedCache.Get(ctx, types.NamespacedName{Namespace: "team-c", Name: "app-name-hash"}, deployment)
+	assert.True(t, IsK8sObjectDoesNotExist(err))
+	err = namespacedCache.List(ctx, &client.ListOptions{Namespace: "team-c"}, &v1.DeploymentList{})
+	assert.True(t, IsK8sObjectDoesNotExist(err))
+
+	// cluster scoped objects are read from the cluster cache
+	err = namespacedCache.Get(ctx, types.NamespacedName{Name: "team-a"}, &coreV1.Namespace{})
+	assert.True(t, IsK8sObjectDoesNotExist(err))
+	err = namespacedCache.List(ctx, &client.ListOptions{}, &coreV1.NamespaceList{})
+	assert.True(t, IsK8sObjectDoesNotExist(err))
+
+	deployments := &v1.DeploymentList{}
+	assert.Nil(t, namespacedCache.List(ctx, &client.ListOptions{Namespace: "team-b"}, deployments))
+	assert.Equal(t, 1, len(deployments.Items))
+	assert.Equal(t, "team-b", deployments.Items[0].Namespace)
+
+	// lists without a namespace span all cached namespaces
+	deployments = &v1.DeploymentList{}
+	assert.Nil(t, namespacedCache.List(ctx, &client.ListOptions{}, deployments))
+	assert.Equal(t, 2, len(deployments.Items))
+
+	namespacedCache.RemoveNamespace("team-b")
+	deployments = &v1.DeploymentList{}
+	assert.Nil(t, namespacedCache.List(ctx, nil, deployments))
+	assert.Equal(t, 1, len(deployments.Items))
+	assert.Equal(t, "team-a", deployments.Items[0].Namespace)
+
+	// the informers of namespaced objects differ per namespace
+	_, err = namespacedCache.GetInformer(&v1.Deployment{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,100 @@
+package k8
+
+import (
+	"context"
+
+	"github.com/lyft/flytestdlib/logger"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// A source of the events of a kind in every namespace of a NamespacedCache, including the namespaces added after the
+// controller started. It is the counterpart of source.Kind, which can only watch the informers of a single cache.
+type NamespacedKind struct {
+	Cache *NamespacedCache
+	Type  runtime.Object
+}
+
+func (s *NamespacedKind) Start(eventHandler handler.EventHandler, queue workqueue.RateLimitingInterface,
+	predicates ...predicate.Predicate) error {
+	return s.Cache.AddEventHandler(s.Type, &namespacedSourceHandler{
+		eventHandler: eventHandler,
+		queue:        queue,
+		predicates:   predicates,
+	})
+}
+
+// Converts the notifications of an informer into the events of the controller runtime, filtered by the predicates
+type namespacedSourceHandler struct {
+	eventHandler handler.EventHandler
+	queue        workqueue.RateLimitingInterface
+	predicates   []predicate.Predicate
+}
+
+func toObject(obj interface{}) (metaObject metav1.Object, object runtime.Object, ok bool) {
+	if tombstone, isTombstone := obj.(toolscache.DeletedFinalStateUnknown); isTombstone {
+		obj = tombstone.Obj
+	}
+	metaObject, err := meta.Accessor(obj)
+	if err != nil {
+		logger.Warnf(context.Background(), "Ignoring event for object without metadata %T: %v", obj, err)
+		return nil, nil, false
+	}
+	object, ok = obj.(runtime.Object)
+	if !ok {
+		logger.Warnf(context.Background(), "Ignoring event for object %T that is not a runtime.Object", obj)
+	}
+	return metaObject, object, ok
+}
+
+func (h *namespacedSourceHandler) OnAdd(obj interface{}) {
+	metaObject, object, ok := toObject(obj)
+	if !ok {
+		return
+	}
+	e := event.CreateEvent{Meta: metaObject, Object: object}
+	for _, p := range h.predicates {
+		if !p.Create(e) {
+			return
+		}
+	}
+	h.eventHandler.Create(e, h.queue)
+}
+
+func (h *namespacedSourceHandler) OnUpdate(oldObj, newObj interface{}) {
+	oldMeta, oldObject, ok := toObject(oldObj)
+	if !ok {
+		return
+	}
+	newMeta, newObject, ok := toObject(newObj)
+	if !ok {
+		return
+	}
+	e := event.UpdateEvent{MetaOld: oldMeta, ObjectOld: oldObject, MetaNew: newMeta, ObjectNew: newObject}
+	for _, p := range h.predicates {
+		if !p.Update(e) {
+			return
+		}
+	}
+	h.eventHandler.Update(e, h.queue)
+}
+
+func (h *namespacedSourceHandler) OnDelete(obj interface{}) {
+	metaObject, object, ok := toObject(obj)
+	if !ok {
+		return
+	}
+	e := event.DeleteEvent{Meta: metaObject, Object: object}
+	for _, p := range h.predicates {
+		if !p.Delete(e) {
+			return
+		}
+	}
+	h.eventHandler.Delete(e, h.queue)
+}
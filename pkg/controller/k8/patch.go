@@ -0,0 +1,82 @@
+package k8
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/lyft/flytestdlib/logger"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+)
+
+// Returns a JSON merge patch (RFC 7386) with the changes from original to modified, or nil if there are none. Lists
+// are replaced as a whole, as merge patches do not support merging them.
+func CreateMergePatch(original runtime.Object, modified runtime.Object) ([]byte, error) {
+	originalMap, err := toJSONMap(original)
+	if err != nil {
+		return nil, err
+	}
+	modifiedMap, err := toJSONMap(modified)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := diffJSONMaps(originalMap, modifiedMap)
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(patch)
+}
+
+func toJSONMap(object runtime.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+func diffJSONMaps(original map[string]interface{}, modified map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for key, originalValue := range original {
+		modifiedValue, ok := modified[key]
+		if !ok {
+			// removed fields are set to null
+			patch[key] = nil
+			continue
+		}
+
+		originalChild, originalIsMap := originalValue.(map[string]interface{})
+		modifiedChild, modifiedIsMap := modifiedValue.(map[string]interface{})
+		if originalIsMap && modifiedIsMap {
+			if childPatch := diffJSONMaps(originalChild, modifiedChild); len(childPatch) > 0 {
+				patch[key] = childPatch
+			}
+		} else if !reflect.DeepEqual(originalValue, modifiedValue) {
+			patch[key] = modifiedValue
+		}
+	}
+
+	for key, modifiedValue := range modified {
+		if _, ok := original[key]; !ok {
+			patch[key] = modifiedValue
+		}
+	}
+	return patch
+}
+
+// Runs the read-modify-write in update until it does not fail with a conflict, backing off between attempts. The
+// update is expected to read the latest version of the object it writes on every attempt.
+func RetryOnConflict(ctx context.Context, update func() error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := update()
+		if k8sErrors.IsConflict(err) {
+			logger.Infof(ctx, "Retrying write after conflict: %v", err)
+		}
+		return err
+	})
+}
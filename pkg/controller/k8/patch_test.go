@@ -0,0 +1,67 @@
+package k8
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCreateMergePatch(t *testing.T) {
+	original := &coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:            "app",
+			ResourceVersion: "10",
+			Labels:          map[string]string{"flink-app": "app", "team": "a"},
+		},
+		Spec: coreV1.ServiceSpec{
+			Selector: map[string]string{"flink-app": "app", "flink-app-hash": "old"},
+			Ports:    []coreV1.ServicePort{{Name: "ui", Port: 8081}},
+		},
+	}
+
+	patch, err := CreateMergePatch(original, original.DeepCopy())
+	assert.Nil(t, err)
+	assert.Nil(t, patch)
+
+	modified := original.DeepCopy()
+	modified.Spec.Selector["flink-app-hash"] = "new"
+	delete(modified.Labels, "team")
+	patch, err = CreateMergePatch(original, modified)
+	assert.Nil(t, err)
+	// the resource version is not included, so the patch does not conflict with other writes
+	assert.JSONEq(t, `{"metadata":{"labels":{"team":null}},"spec":{"selector":{"flink-app-hash":"new"}}}`, string(patch))
+
+	modified = original.DeepCopy()
+	modified.Spec.Ports = append(modified.Spec.Ports, coreV1.ServicePort{Name: "rpc", Port: 6123})
+	patch, err = CreateMergePatch(original, modified)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"spec":{"ports":[{"name":"ui","port":8081,"targetPort":0},`+
+		`{"name":"rpc","port":6123,"targetPort":0}]}}`, string(patch))
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	attempts := 0
+	err := RetryOnConflict(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return k8sErrors.NewConflict(schema.GroupResource{Resource: "services"}, "app", errors.New("modified"))
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = RetryOnConflict(context.Background(), func() error {
+		attempts++
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,122 @@
+package sharding
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Records the shard of the operator instance that reconciles an application, so that an instance taking over the
+// application (e.g. after the number of shards changed) waits for the previous one to stop
+const ShardAnnotation = "flink.k8s.io/shard"
+
+// The share of the applications that an operator instance reconciles
+type Shard struct {
+	Index int
+	Count int
+	label string
+}
+
+// Returns the shard of this operator instance, or nil if sharding is disabled
+func NewShard(cfg config.ShardingConfig) (*Shard, error) {
+	if cfg.ShardCount <= 1 {
+		return nil, nil
+	}
+
+	index := cfg.ShardIndex
+	if index == -1 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		if index, err = getOrdinal(hostname); err != nil {
+			return nil, err
+		}
+	}
+	if index < 0 || index >= cfg.ShardCount {
+		return nil, errors.Errorf("Invalid config: shard index %d is not between 0 and %d", index, cfg.ShardCount-1)
+	}
+
+	return &Shard{Index: index, Count: cfg.ShardCount, label: cfg.ShardLabel}, nil
+}
+
+// Returns the ordinal at the end of the name of a pod in a StatefulSet (e.g. 2 for flinkk8soperator-2)
+func getOrdinal(hostname string) (int, error) {
+	ordinal, err := strconv.Atoi(hostname[strings.LastIndex(hostname, "-")+1:])
+	if err != nil || ordinal < 0 {
+		return 0, errors.Errorf("Invalid config: cannot take the shard index from hostname %s", hostname)
+	}
+	return ordinal, nil
+}
+
+// Parses the value of the ShardAnnotation
+func ParseShard(value string) (*Shard, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) == 2 {
+		index, indexErr := strconv.Atoi(parts[0])
+		count, countErr := strconv.Atoi(parts[1])
+		if indexErr == nil && countErr == nil && index >= 0 && index < count {
+			return &Shard{Index: index, Count: count}, nil
+		}
+	}
+	return nil, errors.Errorf("Invalid shard %s", value)
+}
+
+func (s *Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+func (s *Shard) hash(value string) int {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(value))
+	return int(hasher.Sum32() % uint32(s.Count))
+}
+
+// Returns the index of the shard that the object is assigned to
+func (s *Shard) getShardIndex(object metaV1.Object) int {
+	if value, ok := object.GetLabels()[s.label]; ok && s.label != "" {
+		if index, err := strconv.Atoi(value); err == nil && index >= 0 {
+			return index % s.Count
+		}
+		return s.hash(value)
+	}
+	return s.hash(object.GetNamespace() + "/" + object.GetName())
+}
+
+// Returns whether the object is assigned to this shard. Without sharding, all objects are.
+func (s *Shard) Owns(object metaV1.Object) bool {
+	return s == nil || s.getShardIndex(object) == s.Index
+}
+
+// Returns the name of the leader election lock for the replicas of this shard
+func (s *Shard) GetLockName(lockName string) string {
+	if s == nil {
+		return lockName
+	}
+	return fmt.Sprintf("%s-shard-%d-of-%d", lockName, s.Index, s.Count)
+}
+
+// Returns whether the leader election lock stored in the ConfigMap is held, i.e. whether its holder renewed it within
+// the lease duration
+func IsLockHeld(lock *coreV1.ConfigMap, now time.Time) bool {
+	value, ok := lock.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]
+	if !ok {
+		return false
+	}
+	record := resourcelock.LeaderElectionRecord{}
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return false
+	}
+	expiry := record.RenewTime.Add(time.Duration(record.LeaseDurationSeconds) * time.Second)
+	return record.HolderIdentity != "" && expiry.After(now)
+}
@@ -0,0 +1,91 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func TestNewShard(t *testing.T) {
+	shard, err := NewShard(config.ShardingConfig{ShardCount: 1})
+	assert.Nil(t, err)
+	assert.Nil(t, shard)
+	assert.True(t, shard.Owns(&metaV1.ObjectMeta{Namespace: "ns", Name: "app"}))
+	assert.Equal(t, "lock", shard.GetLockName("lock"))
+
+	shard, err = NewShard(config.ShardingConfig{ShardCount: 4, ShardIndex: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, "2/4", shard.String())
+	assert.Equal(t, "lock-shard-2-of-4", shard.GetLockName("lock"))
+
+	_, err = NewShard(config.ShardingConfig{ShardCount: 4, ShardIndex: 4})
+	assert.EqualError(t, err, "Invalid config: shard index 4 is not between 0 and 3")
+
+	ordinal, err := getOrdinal("flinkk8soperator-12")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, ordinal)
+	_, err = getOrdinal("flinkk8soperator-5d8f7b")
+	assert.NotNil(t, err)
+}
+
+func TestParseShard(t *testing.T) {
+	shard, err := ParseShard("1/3")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, shard.Index)
+	assert.Equal(t, 3, shard.Count)
+
+	for _, value := range []string{"", "1", "3/3", "a/3", "1/3/5"} {
+		_, err := ParseShard(value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestOwns(t *testing.T) {
+	shards := []*Shard{{Index: 0, Count: 3, label: "team"}, {Index: 1, Count: 3, label: "team"}, {Index: 2, Count: 3, label: "team"}}
+	owners := func(meta *metaV1.ObjectMeta) []int {
+		var owners []int
+		for _, shard := range shards {
+			if shard.Owns(meta) {
+				owners = append(owners, shard.Index)
+			}
+		}
+		return owners
+	}
+
+	// every application is owned by exactly one shard
+	counts := make([]int, 3)
+	for i := 0; i < 300; i++ {
+		owner := owners(&metaV1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("app-%d", i)})
+		assert.Equal(t, 1, len(owner))
+		counts[owner[0]]++
+	}
+	for _, count := range counts {
+		assert.True(t, count > 50, "applications are not spread across shards: %v", counts)
+	}
+
+	// numeric labels select the shard, other values are hashed
+	assert.Equal(t, []int{2}, owners(&metaV1.ObjectMeta{Name: "a", Labels: map[string]string{"team": "5"}}))
+	expected := owners(&metaV1.ObjectMeta{Name: "a", Labels: map[string]string{"team": "data"}})
+	assert.Equal(t, expected, owners(&metaV1.ObjectMeta{Name: "b", Labels: map[string]string{"team": "data"}}))
+}
+
+func TestIsLockHeld(t *testing.T) {
+	now := time.Now()
+	lock := func(holder string, renewed time.Time) *coreV1.ConfigMap {
+		return &coreV1.ConfigMap{ObjectMeta: metaV1.ObjectMeta{Annotations: map[string]string{
+			resourcelock.LeaderElectionRecordAnnotationKey: fmt.Sprintf(
+				`{"holderIdentity":"%s","leaseDurationSeconds":15,"renewTime":"%s"}`, holder, renewed.Format(time.RFC3339)),
+		}}}
+	}
+
+	assert.True(t, IsLockHeld(lock("operator-0", now.Add(-10*time.Second)), now))
+	assert.False(t, IsLockHeld(lock("operator-0", now.Add(-20*time.Second)), now))
+	assert.False(t, IsLockHeld(lock("", now), now))
+	assert.False(t, IsLockHeld(&coreV1.ConfigMap{}, now))
+}
@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/pkg/errors"
+)
+
+// Spans follow the OpenTelemetry span model, so that an OpenTelemetry exporter can be plugged in behind the Exporter
+// interface once the SDK is vendored. Until then spans are dropped, unless an exporter is installed by tests.
+const ExporterNoop = "noop"
+
+const (
+	AppKey       = Key("flink.app")
+	NamespaceKey = Key("flink.namespace")
+	PhaseKey     = Key("flink.phase")
+	HashKey      = Key("flink.hash")
+)
+
+// The application attributes that are propagated through the context by the reconciler
+var contextAttributeKeys = []struct {
+	contextKey   contextutils.Key
+	attributeKey Key
+}{
+	{contextutils.AppNameKey, AppKey},
+	{contextutils.NamespaceKey, NamespaceKey},
+	{contextutils.PhaseKey, PhaseKey},
+}
+
+// The name of a span attribute, following the OpenTelemetry attribute naming conventions
+type Key string
+
+// A span attribute. Value is either a string or an int64.
+type KeyValue struct {
+	Key   Key
+	Value interface{}
+}
+
+func (k Key) String(value string) KeyValue {
+	return KeyValue{Key: k, Value: value}
+}
+
+func (k Key) Int(value int) KeyValue {
+	return KeyValue{Key: k, Value: int64(value)}
+}
+
+// Status codes of a span, matching the OpenTelemetry status codes
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOk    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+type Status struct {
+	Code        StatusCode
+	Description string
+}
+
+// An ended span as it is handed to the exporter
+type SpanData struct {
+	Name       string
+	StartTime  time.Time
+	EndTime    time.Time
+	Attributes []KeyValue
+	Status     Status
+}
+
+// Receives spans as they end. Implementations must be safe for concurrent use.
+type Exporter interface {
+	ExportSpan(span SpanData)
+}
+
+var (
+	exporterLock   sync.RWMutex
+	globalExporter Exporter
+)
+
+func getExporter() Exporter {
+	exporterLock.RLock()
+	defer exporterLock.RUnlock()
+	return globalExporter
+}
+
+func setExporter(exporter Exporter) Exporter {
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+	previous := globalExporter
+	globalExporter = exporter
+	return previous
+}
+
+// A span that is recorded if an exporter is installed. All methods are safe to call on a span that is not recorded.
+type Span struct {
+	lock     sync.Mutex
+	data     SpanData
+	exporter Exporter
+	ended    bool
+}
+
+func (s *Span) SetAttributes(attrs ...KeyValue) {
+	if s == nil || s.exporter == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+func (s *Span) SetStatus(code StatusCode, description string) {
+	if s == nil || s.exporter == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data.Status = Status{Code: code, Description: description}
+}
+
+// Ends the span and hands it to the exporter. Calls after the first are ignored.
+func (s *Span) End() {
+	if s == nil || s.exporter == nil {
+		return
+	}
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	data.Attributes = append([]KeyValue(nil), s.data.Attributes...)
+	s.lock.Unlock()
+
+	s.exporter.ExportSpan(data)
+}
+
+// Configures tracing according to the operator config. The returned function stops tracing, and should be called
+// before the operator exits.
+func Initialize(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	switch cfg.Exporter {
+	case "", ExporterNoop:
+		setExporter(nil)
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, errors.Errorf("unsupported tracing exporter %s", cfg.Exporter)
+	}
+}
+
+// Records every span in memory. Intended for tests.
+type InMemoryExporter struct {
+	lock  sync.Mutex
+	spans []SpanData
+}
+
+func (e *InMemoryExporter) ExportSpan(span SpanData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Returns the spans that ended so far, in the order they ended
+func (e *InMemoryExporter) GetSpans() []SpanData {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Records all spans in memory. Intended for tests; the returned function restores the previously installed exporter
+// and should be deferred by the caller.
+func InstallInMemoryExporter() (*InMemoryExporter, func()) {
+	exporter := &InMemoryExporter{}
+	previous := setExporter(exporter)
+	return exporter, func() { setExporter(previous) }
+}
+
+// Starts a span. The span is annotated with the application, namespace and phase carried by the context, along with
+// any additional attributes.
+func StartSpan(ctx context.Context, name string, attrs ...KeyValue) (context.Context, *Span) {
+	span := &Span{data: SpanData{Name: name, StartTime: time.Now()}, exporter: getExporter()}
+	for _, key := range contextAttributeKeys {
+		if value, ok := ctx.Value(key.contextKey).(string); ok && value != "" {
+			span.SetAttributes(key.attributeKey.String(value))
+		}
+	}
+	span.SetAttributes(attrs...)
+	return ctx, span
+}
+
+// Marks the span as failed if err is non-nil
+func RecordError(span *Span, err error) {
+	if err != nil {
+		span.SetStatus(StatusError, err.Error())
+	}
+}
+
+// Records err, if any, and ends the span
+func EndSpan(span *Span, err error) {
+	RecordError(span, err)
+	span.End()
+}
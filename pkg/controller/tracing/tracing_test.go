@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lyft/flinkk8soperator/pkg/controller/config"
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartSpanContextAttributes(t *testing.T) {
+	exporter, restore := InstallInMemoryExporter()
+	defer restore()
+
+	ctx := contextutils.WithNamespace(context.Background(), "flink")
+	ctx = contextutils.WithAppName(ctx, "test-app")
+	ctx = contextutils.WithPhase(ctx, "Running")
+
+	_, span := StartSpan(ctx, "test-span", HashKey.String("abcd1234"))
+	EndSpan(span, nil)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, "test-span", spans[0].Name)
+	assert.ElementsMatch(t, []KeyValue{
+		NamespaceKey.String("flink"),
+		AppKey.String("test-app"),
+		PhaseKey.String("Running"),
+		HashKey.String("abcd1234"),
+	}, spans[0].Attributes)
+	assert.Equal(t, StatusUnset, spans[0].Status.Code)
+}
+
+func TestEndSpanWithError(t *testing.T) {
+	exporter, restore := InstallInMemoryExporter()
+	defer restore()
+
+	_, span := StartSpan(context.Background(), "test-span")
+	EndSpan(span, errors.New("failed"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.Empty(t, spans[0].Attributes)
+	assert.Equal(t, StatusError, spans[0].Status.Code)
+	assert.Equal(t, "failed", spans[0].Status.Description)
+}
+
+func TestInstallInMemoryExporterRestore(t *testing.T) {
+	outer, restoreOuter := InstallInMemoryExporter()
+	defer restoreOuter()
+
+	_, restoreInner := InstallInMemoryExporter()
+	restoreInner()
+
+	_, span := StartSpan(context.Background(), "test-span")
+	span.End()
+	assert.Equal(t, 1, len(outer.GetSpans()))
+}
+
+func TestInitializeExporters(t *testing.T) {
+	previous := setExporter(nil)
+	defer setExporter(previous)
+
+	shutdown, err := Initialize(context.Background(), config.TracingConfig{})
+	assert.Nil(t, err)
+	assert.Nil(t, shutdown(context.Background()))
+
+	_, span := StartSpan(context.Background(), "test-span", HashKey.String("abcd1234"))
+	EndSpan(span, errors.New("failed"))
+	assert.Nil(t, span.exporter)
+
+	_, err = Initialize(context.Background(), config.TracingConfig{
+		Exporter: "otlp",
+	})
+	assert.EqualError(t, err, "unsupported tracing exporter otlp")
+}